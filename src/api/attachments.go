@@ -0,0 +1,165 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/storage"
+	"encoding/base64"
+	"fmt"
+)
+
+// attachmentInput is the part of an attachment checked at ingest
+type attachmentInput struct {
+	Filename   string
+	Content    string
+	URL        string
+	StorageKey string
+}
+
+// decodeAttachments decodes base64 attachment content and checks it against the channel size limits.
+// Attachments given by URL are only checked against the host allowlist here, they are fetched at send time.
+// The returned slice holds nil for attachments that have nothing to decode.
+func decodeAttachments(channel models.Channel, attachments []attachmentInput) ([][]byte, error) {
+	fetcher := storage.NewFetcherFromEnv()
+
+	data := make([][]byte, len(attachments))
+	filenames := make([]string, 0, len(attachments))
+	sizes := make([]int64, 0, len(attachments))
+	for i, attachment := range attachments {
+		switch {
+		case attachment.StorageKey != "":
+			// Already offloaded by an earlier call
+			continue
+		case attachment.URL != "" && attachment.Content != "":
+			return nil, fmt.Errorf("attachment %q must have either content or url, not both", attachment.Filename)
+		case attachment.URL != "":
+			if err := fetcher.CheckURL(attachment.URL); err != nil {
+				return nil, err
+			}
+			continue
+		case attachment.Content == "":
+			return nil, fmt.Errorf("attachment %q has no content or url", attachment.Filename)
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(attachment.Content)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %q is not valid base64", attachment.Filename)
+		}
+		data[i] = decoded
+		filenames = append(filenames, attachment.Filename)
+		sizes = append(sizes, int64(len(decoded)))
+	}
+
+	if err := storage.LimitsForChannel(channel).Check(channel, filenames, sizes); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateEmailAttachments checks that the attachments of an email request can be accepted
+func validateEmailAttachments(attachments []AttachmentMetadata) error {
+	inputs := make([]attachmentInput, len(attachments))
+	for i, attachment := range attachments {
+		inputs[i] = attachmentInput{Filename: attachment.Filename, Content: attachment.Content, URL: attachment.URL}
+	}
+	_, err := decodeAttachments(models.ChannelEmail, inputs)
+	return err
+}
+
+// validateWhatsAppAttachments checks that the attachments of a WhatsApp request can be accepted
+func validateWhatsAppAttachments(attachments *WhatsAppAttachments) error {
+	if attachments == nil {
+		return nil
+	}
+	inputs := make([]attachmentInput, len(attachments.Inline))
+	for i, attachment := range attachments.Inline {
+		inputs[i] = attachmentInput{Filename: attachment.Filename, Content: attachment.Content, URL: attachment.URL}
+	}
+	_, err := decodeAttachments(models.ChannelWhatsApp, inputs)
+	return err
+}
+
+// offloadEmailAttachments moves the attachment bytes of an email to the blob store so only
+// references are queued
+func offloadEmailAttachments(store storage.Store, messageUUID string, attachments []models.AttachmentMetadata) error {
+	inputs := make([]attachmentInput, len(attachments))
+	for i, attachment := range attachments {
+		inputs[i] = attachmentInput{
+			Filename:   attachment.Filename,
+			Content:    attachment.Content,
+			URL:        attachment.URL,
+			StorageKey: attachment.StorageKey,
+		}
+	}
+
+	data, err := decodeAttachments(models.ChannelEmail, inputs)
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	for i, content := range data {
+		if content == nil {
+			continue
+		}
+		key := storage.AttachmentKey(messageUUID, i, attachments[i].Filename)
+		if err := store.Put(key, &storage.Object{Data: content, ContentType: attachments[i].ContentType}); err != nil {
+			return fmt.Errorf("failed to store attachment %q: %w", attachments[i].Filename, err)
+		}
+		attachments[i].Content = ""
+		attachments[i].StorageKey = key
+		attachments[i].Size = int64(len(content))
+		stored++
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"message_uuid": messageUUID,
+		"attachments":  stored,
+	}).Debug("Offloaded email attachments")
+	return nil
+}
+
+// offloadWhatsAppAttachments moves the inline attachment bytes of a WhatsApp message to the
+// blob store so only references are queued
+func offloadWhatsAppAttachments(store storage.Store, messageUUID string, attachments *models.WhatsAppAttachments) error {
+	if attachments == nil {
+		return nil
+	}
+
+	inputs := make([]attachmentInput, len(attachments.Inline))
+	for i, attachment := range attachments.Inline {
+		inputs[i] = attachmentInput{
+			Filename:   attachment.Filename,
+			Content:    attachment.Content,
+			URL:        attachment.URL,
+			StorageKey: attachment.StorageKey,
+		}
+	}
+
+	data, err := decodeAttachments(models.ChannelWhatsApp, inputs)
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	for i, content := range data {
+		if content == nil {
+			continue
+		}
+		attachment := &attachments.Inline[i]
+		key := storage.AttachmentKey(messageUUID, i, attachment.Filename)
+		if err := store.Put(key, &storage.Object{Data: content, ContentType: attachment.Type}); err != nil {
+			return fmt.Errorf("failed to store attachment %q: %w", attachment.Filename, err)
+		}
+		attachment.Content = ""
+		attachment.StorageKey = key
+		attachment.Size = int64(len(content))
+		stored++
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"message_uuid": messageUUID,
+		"attachments":  stored,
+	}).Debug("Offloaded WhatsApp attachments")
+	return nil
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"delivery/api/types"
 	"delivery/helper"
 	"delivery/models"
 	"delivery/services/queue"
+	"delivery/services/storage"
 	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -24,6 +29,12 @@ type EmailMessage struct {
 	Identifiers map[string]interface{} `json:"identifiers" validate:"required"`
 	Params      map[string]string      `json:"params"`
 	Subject     string                 `json:"subject,omitempty"`
+	Cc          []EmailRecipient       `json:"cc,omitempty"`
+	Bcc         []EmailRecipient       `json:"bcc,omitempty"`
+	ReplyTo     *EmailRecipient        `json:"replyTo,omitempty"`  // Where replies go instead of the provider's from address
+	FromName    string                 `json:"fromName,omitempty"` // Display name shown with the provider's from address
+	Headers     map[string]string      `json:"headers,omitempty"`
+	CustomArgs  map[string]string      `json:"customArgs,omitempty"` // Echoed back by the provider in event webhooks
 	Attachments []AttachmentMetadata   `json:"attachments,omitempty"`
 	TenantID    string                 `json:"tenantId" validate:"required"`
 }
@@ -38,6 +49,18 @@ func (e *EmailMessage) ToModelEmailMessage() *models.EmailMessage {
 		Identifiers: e.Identifiers,
 		Params:      e.Params,
 		Subject:     e.Subject,
+		Cc:          toModelEmailRecipients(e.Cc),
+		Bcc:         toModelEmailRecipients(e.Bcc),
+		FromName:    e.FromName,
+		Headers:     e.Headers,
+		CustomArgs:  e.CustomArgs,
+	}
+
+	if e.ReplyTo != nil {
+		modelMessage.ReplyTo = &models.EmailRecipient{
+			Name:  e.ReplyTo.Name,
+			Email: e.ReplyTo.Email,
+		}
 	}
 
 	// Convert recipients
@@ -57,6 +80,7 @@ func (e *EmailMessage) ToModelEmailMessage() *models.EmailMessage {
 				Filename:    attachment.Filename,
 				ContentType: attachment.ContentType,
 				Content:     attachment.Content,
+				URL:         attachment.URL,
 			}
 		}
 	}
@@ -64,6 +88,59 @@ func (e *EmailMessage) ToModelEmailMessage() *models.EmailMessage {
 	return modelMessage
 }
 
+// toModelEmailRecipients converts API recipients to model recipients
+func toModelEmailRecipients(recipients []EmailRecipient) []models.EmailRecipient {
+	if len(recipients) == 0 {
+		return nil
+	}
+	result := make([]models.EmailRecipient, len(recipients))
+	for i, recipient := range recipients {
+		result[i] = models.EmailRecipient{
+			Name:  recipient.Name,
+			Email: recipient.Email,
+		}
+	}
+	return result
+}
+
+// validateEnvelope checks the recipients, reply-to address and headers of an email
+func validateEnvelope(message EmailMessage) error {
+	seen := map[string]string{}
+	groups := []struct {
+		name       string
+		recipients []EmailRecipient
+	}{
+		{"to", message.To},
+		{"cc", message.Cc},
+		{"bcc", message.Bcc},
+	}
+	for _, group := range groups {
+		for _, recipient := range group.recipients {
+			if _, err := mail.ParseAddress(recipient.Email); err != nil {
+				return fmt.Errorf("%s address %q is not a valid email address", group.name, recipient.Email)
+			}
+			address := strings.ToLower(recipient.Email)
+			if previous, ok := seen[address]; ok {
+				return fmt.Errorf("%s address %q is already a %s recipient", group.name, recipient.Email, previous)
+			}
+			seen[address] = group.name
+		}
+	}
+
+	if message.ReplyTo != nil {
+		if _, err := mail.ParseAddress(message.ReplyTo.Email); err != nil {
+			return fmt.Errorf("replyTo address %q is not a valid email address", message.ReplyTo.Email)
+		}
+	}
+
+	for name := range message.Headers {
+		if err := types.ValidateHeaderName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // EmailRecipient represents an email recipient with name and email
 type EmailRecipient struct {
 	Name  string `json:"name,omitempty"`
@@ -74,7 +151,8 @@ type EmailRecipient struct {
 type AttachmentMetadata struct {
 	Filename    string `json:"filename" validate:"required"`
 	ContentType string `json:"contentType" validate:"required"`
-	Content     string `json:"content" validate:"required"` // base64 encoded
+	Content     string `json:"content"`       // base64 encoded, required unless url is given
+	URL         string `json:"url,omitempty"` // Fetched from an allowlisted host at send time
 }
 
 // EmailResponse represents the response body for sending Email messages
@@ -101,6 +179,7 @@ type EmailAPI struct {
 	DB              *gorm.DB
 	ReaderDB        *gorm.DB
 	MessageProducer *queue.EmailProducer
+	Store           storage.Store
 }
 
 // NewEmailAPI creates a new Email API
@@ -120,6 +199,12 @@ func NewEmailAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClien
 		return nil, errors.New("pulsar client is nil")
 	}
 
+	store, err := storage.NewStoreFromEnv()
+	if err != nil {
+		helper.Log.WithError(err).Error("Failed to initialize Email API: could not create attachment store")
+		return nil, err
+	}
+
 	producer := queue.NewEmailProducer(pulsarClient, db)
 	helper.Log.Info("Email API initialized successfully")
 
@@ -127,6 +212,7 @@ func NewEmailAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClien
 		DB:              db,
 		ReaderDB:        readerDB,
 		MessageProducer: producer,
+		Store:           store,
 	}, nil
 }
 
@@ -139,6 +225,18 @@ func (a *EmailAPI) ProcessMessageBatch(request EmailRequest) ([]EmailMessageResp
 	batchLogger.Info("Starting to process Email message batch")
 	responses := []EmailMessageResponse{}
 
+	// Validate the whole batch before anything is stored or queued
+	for idx, message := range request.Messages {
+		if err := validateEnvelope(message); err != nil {
+			batchLogger.WithError(err).WithField("messageIndex", idx).Warn("Rejected Email message")
+			return nil, fmt.Errorf("message %d: %v", idx, err)
+		}
+		if err := validateEmailAttachments(message.Attachments); err != nil {
+			batchLogger.WithError(err).WithField("messageIndex", idx).Warn("Rejected Email message")
+			return nil, fmt.Errorf("message %d: %v", idx, err)
+		}
+	}
+
 	for idx, message := range request.Messages {
 		messageLogger := batchLogger.WithFields(map[string]interface{}{
 			"messageIndex": idx,
@@ -173,6 +271,12 @@ func (a *EmailAPI) ProcessMessageBatch(request EmailRequest) ([]EmailMessageResp
 		// Convert to model message
 		modelMessage := message.ToModelEmailMessage()
 
+		// Attachment bytes go to the blob store, the queued message only references them
+		if err := offloadEmailAttachments(a.Store, messageUUID, modelMessage.Attachments); err != nil {
+			messageLogger.WithError(err).Error("Failed to store Email attachments")
+			return nil, errors.New("failed to process message: " + err.Error())
+		}
+
 		// Send to queue
 		messageLogger.Debug("Sending Email message to queue")
 		if err := a.MessageProducer.ProduceEmailMessage(modelMessage, messageUUID); err != nil {
@@ -212,6 +316,11 @@ func (a *EmailAPI) DirectPushEmailMessage(modelMessage *models.EmailMessage) (st
 	// Log with UUID
 	logger = logger.WithField("uuid", directPushMessage.UUID)
 
+	if err := offloadEmailAttachments(a.Store, directPushMessage.UUID, directPushMessage.Message.Attachments); err != nil {
+		logger.WithError(err).Error("Failed to store email attachments")
+		return "", err
+	}
+
 	// Push the message directly to Pulsar
 	logger.Debug("Pushing email message directly to queue")
 	if err := directPushMessage.Push(); err != nil {
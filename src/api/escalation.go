@@ -0,0 +1,646 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EscalationPolicyRequest represents the request body for escalation policy APIs
+type EscalationPolicyRequest struct {
+	Policies []EscalationPolicyItem `json:"policies" binding:"required,min=1"`
+}
+
+// EscalationPolicyItem represents a single escalation policy in the request
+type EscalationPolicyItem struct {
+	Code     string               `json:"code" binding:"required"` // Cannot be edited after creation
+	Name     string               `json:"name" binding:"required"`
+	Status   int                  `json:"status"` // 0=inactive, 1=active
+	Steps    []EscalationStepItem `json:"steps" binding:"required,min=1"`
+	TenantID string               `json:"tenantId" binding:"required"`
+}
+
+// EscalationStepItem represents a single step of an escalation policy
+type EscalationStepItem struct {
+	Channel     string                  `json:"channel"`
+	Template    string                  `json:"template"`           // Template code, resolved for the step channel
+	Provider    string                  `json:"provider,omitempty"` // Optional provider UUID
+	To          []NotificationRecipient `json:"to"`
+	WaitSeconds int                     `json:"waitSeconds"` // Delay after the previous step
+}
+
+// EscalationPolicyResponse represents the response body for escalation policy APIs
+type EscalationPolicyResponse struct {
+	Policies []EscalationPolicyResponseItem `json:"policies"`
+}
+
+// EscalationPolicyResponseItem represents a single escalation policy in the response
+type EscalationPolicyResponseItem struct {
+	UUID      string               `json:"uuid"`
+	Code      string               `json:"code"`
+	Name      string               `json:"name"`
+	Status    int                  `json:"status"`
+	Steps     []EscalationStepItem `json:"steps"`
+	TenantID  string               `json:"tenantId"`
+	CreatedAt string               `json:"createdAt"`
+	UpdatedAt string               `json:"updatedAt"`
+}
+
+// EscalationPolicyListParams represents parameters for listing escalation policies
+type EscalationPolicyListParams struct {
+	Limit    int    `json:"limit" form:"limit"`
+	Offset   int    `json:"offset" form:"offset"`
+	TenantID string `json:"tenantId" form:"tenantId"`
+}
+
+// EscalationRequest represents the request body for starting escalations
+type EscalationRequest struct {
+	Escalations []EscalationItem `json:"escalations" binding:"required,min=1"`
+}
+
+// EscalationItem represents a single escalation to start
+type EscalationItem struct {
+	Policy      string                 `json:"policy" binding:"required"` // Policy code
+	RefNo       string                 `json:"refno" binding:"required"`
+	Categories  []string               `json:"categories"`
+	Identifiers map[string]interface{} `json:"identifiers"`
+	Params      map[string]string      `json:"params"`
+	Subject     string                 `json:"subject"` // Optional email subject override
+	TenantID    string                 `json:"tenantId" binding:"required"`
+}
+
+// EscalationResponse represents the response body for escalation APIs
+type EscalationResponse struct {
+	Escalations []EscalationResponseItem `json:"escalations"`
+}
+
+// EscalationResponseItem represents a running escalation in the response
+type EscalationResponseItem struct {
+	UUID      string                       `json:"uuid"`
+	Policy    string                       `json:"policy"`
+	RefNo     string                       `json:"refno"`
+	TenantID  string                       `json:"tenantId"`
+	Status    string                       `json:"status"`
+	Reason    string                       `json:"reason,omitempty"`
+	Steps     []EscalationStepResponseItem `json:"steps"`
+	CreatedAt string                       `json:"createdAt"`
+	UpdatedAt string                       `json:"updatedAt"`
+}
+
+// EscalationStepResponseItem represents the state of a single escalation step
+type EscalationStepResponseItem struct {
+	Position     int                     `json:"position"`
+	Channel      string                  `json:"channel"`
+	Template     string                  `json:"template"`
+	Provider     string                  `json:"provider,omitempty"`
+	To           []NotificationRecipient `json:"to"`
+	WaitSeconds  int                     `json:"waitSeconds"`
+	Status       string                  `json:"status"`
+	Reason       string                  `json:"reason,omitempty"`
+	Notification string                  `json:"notification,omitempty"`
+	DueAt        string                  `json:"dueAt,omitempty"`
+	SentAt       string                  `json:"sentAt,omitempty"`
+}
+
+// EscalationListParams represents parameters for listing escalations
+type EscalationListParams struct {
+	Limit    int    `json:"limit" form:"limit"`
+	Offset   int    `json:"offset" form:"offset"`
+	TenantID string `json:"tenantId" form:"tenantId"`
+	RefNo    string `json:"refno" form:"refno"`
+	Status   string `json:"status" form:"status"`
+}
+
+// EscalationAPI handles escalation policy and escalation business logic
+type EscalationAPI struct {
+	DB                *gorm.DB
+	ReaderDB          *gorm.DB
+	EscalationService *services.EscalationService
+}
+
+// NewEscalationAPI creates a new escalation API
+func NewEscalationAPI(db *gorm.DB, readerDB *gorm.DB) (*EscalationAPI, error) {
+	logger := helper.Log.WithField("component", "EscalationAPI")
+
+	if db == nil {
+		logger.Error("Writer database connection is nil")
+		return nil, fmt.Errorf("writer database connection is nil")
+	}
+	if readerDB == nil {
+		logger.Error("Reader database connection is nil")
+		return nil, fmt.Errorf("reader database connection is nil")
+	}
+
+	escalationService, err := services.NewEscalationService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create escalation service")
+		return nil, err
+	}
+
+	logger.Info("Escalation API initialized successfully")
+	return &EscalationAPI{
+		DB:                db,
+		ReaderDB:          readerDB,
+		EscalationService: escalationService,
+	}, nil
+}
+
+// toPolicySteps validates the requested steps and converts them to policy step models
+func toPolicySteps(items []EscalationStepItem) ([]models.EscalationPolicyStep, error) {
+	if len(items) == 0 {
+		return nil, errors.New("at least one step is required")
+	}
+
+	steps := make([]models.EscalationPolicyStep, 0, len(items))
+	for idx, item := range items {
+		channel := models.Channel(strings.ToUpper(item.Channel))
+		switch channel {
+		case models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail:
+		default:
+			return nil, fmt.Errorf("step %d: invalid channel: %s", idx, item.Channel)
+		}
+		if item.Template == "" {
+			return nil, fmt.Errorf("step %d: template is required", idx)
+		}
+		if len(item.To) == 0 {
+			return nil, fmt.Errorf("step %d: at least one recipient is required", idx)
+		}
+		if item.WaitSeconds < 0 {
+			return nil, fmt.Errorf("step %d: waitSeconds cannot be negative", idx)
+		}
+
+		recipients := make([]models.NotificationRecipient, len(item.To))
+		for i, recipient := range item.To {
+			recipients[i] = models.NotificationRecipient{
+				Name:      recipient.Name,
+				Telephone: recipient.Telephone,
+				WhatsApp:  recipient.WhatsApp,
+				Email:     recipient.Email,
+			}
+		}
+		recipientsJSON, err := models.RecipientsToJSON(recipients)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: invalid recipients: %v", idx, err)
+		}
+
+		steps = append(steps, models.EscalationPolicyStep{
+			Position:     idx,
+			Channel:      channel,
+			TemplateCode: item.Template,
+			ProviderUUID: item.Provider,
+			Recipients:   recipientsJSON,
+			WaitSeconds:  item.WaitSeconds,
+		})
+	}
+	return steps, nil
+}
+
+// toNotificationRecipients converts a stored recipient group to its API representation
+func toNotificationRecipients(data models.JSON) []NotificationRecipient {
+	stored := models.RecipientsFromJSON(data)
+	recipients := make([]NotificationRecipient, len(stored))
+	for i, recipient := range stored {
+		recipients[i] = NotificationRecipient{
+			Name:      recipient.Name,
+			Telephone: recipient.Telephone,
+			WhatsApp:  recipient.WhatsApp,
+			Email:     recipient.Email,
+		}
+	}
+	return recipients
+}
+
+// toEscalationPolicyResponseItem converts a policy model with its steps to its response representation
+func toEscalationPolicyResponseItem(policy models.EscalationPolicy) EscalationPolicyResponseItem {
+	item := EscalationPolicyResponseItem{
+		UUID:      policy.UUID,
+		Code:      policy.Code,
+		Name:      policy.Name,
+		Status:    policy.Status,
+		Steps:     make([]EscalationStepItem, 0, len(policy.Steps)),
+		TenantID:  policy.TenantID,
+		CreatedAt: policy.CreatedAt.Format(helper.TimeFormat),
+		UpdatedAt: policy.UpdatedAt.Format(helper.TimeFormat),
+	}
+	for _, step := range policy.Steps {
+		item.Steps = append(item.Steps, EscalationStepItem{
+			Channel:     string(step.Channel),
+			Template:    step.TemplateCode,
+			Provider:    step.ProviderUUID,
+			To:          toNotificationRecipients(step.Recipients),
+			WaitSeconds: step.WaitSeconds,
+		})
+	}
+	return item
+}
+
+// toEscalationResponseItem converts an escalation model with its steps to its response representation
+func toEscalationResponseItem(escalation models.Escalation) EscalationResponseItem {
+	item := EscalationResponseItem{
+		UUID:      escalation.UUID,
+		Policy:    escalation.PolicyUUID,
+		RefNo:     escalation.RefNo,
+		TenantID:  escalation.TenantID,
+		Status:    string(escalation.Status),
+		Reason:    escalation.Reason,
+		Steps:     make([]EscalationStepResponseItem, 0, len(escalation.Steps)),
+		CreatedAt: escalation.CreatedAt.Format(helper.TimeFormat),
+		UpdatedAt: escalation.UpdatedAt.Format(helper.TimeFormat),
+	}
+	for _, step := range escalation.Steps {
+		stepItem := EscalationStepResponseItem{
+			Position:     step.Position,
+			Channel:      string(step.Channel),
+			Template:     step.TemplateCode,
+			Provider:     step.ProviderUUID,
+			To:           toNotificationRecipients(step.Recipients),
+			WaitSeconds:  step.WaitSeconds,
+			Status:       string(step.Status),
+			Reason:       step.Reason,
+			Notification: step.NotificationUUID,
+		}
+		if step.DueAt != nil {
+			stepItem.DueAt = step.DueAt.Format(helper.TimeFormat)
+		}
+		if step.SentAt != nil {
+			stepItem.SentAt = step.SentAt.Format(helper.TimeFormat)
+		}
+		item.Steps = append(item.Steps, stepItem)
+	}
+	return item
+}
+
+// orderSteps preloads policy or escalation steps by position
+func orderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// CreatePolicies creates new escalation policies
+func (a *EscalationAPI) CreatePolicies(request EscalationPolicyRequest) (*EscalationPolicyResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "CreatePolicies",
+		"count":     len(request.Policies),
+	})
+
+	logger.Info("Creating escalation policies")
+
+	// Validate the whole batch before anything is written
+	policies := make([]models.EscalationPolicy, 0, len(request.Policies))
+	for idx, item := range request.Policies {
+		steps, err := toPolicySteps(item.Steps)
+		if err != nil {
+			return nil, fmt.Errorf("policy %d: %v", idx, err)
+		}
+
+		uuid, err := helper.GenerateUUID()
+		if err != nil {
+			logger.WithError(err).Error("Failed to generate UUID for escalation policy")
+			return nil, fmt.Errorf("failed to generate UUID: %v", err)
+		}
+
+		policies = append(policies, models.EscalationPolicy{
+			UUID:     uuid,
+			TenantID: item.TenantID,
+			Code:     item.Code,
+			Name:     item.Name,
+			Status:   item.Status,
+			Steps:    steps,
+		})
+	}
+
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range policies {
+			var count int64
+			if err := tx.Model(&models.EscalationPolicy{}).
+				Where("tenant_id = ? AND code = ?", policies[i].TenantID, policies[i].Code).
+				Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check for duplicate policy: %v", err)
+			}
+			if count > 0 {
+				return fmt.Errorf("%d:%s", helper.CodeDuplicate, helper.MsgDuplicate)
+			}
+
+			if err := tx.Create(&policies[i]).Error; err != nil {
+				return fmt.Errorf("failed to create escalation policy: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to create escalation policies")
+		return nil, err
+	}
+
+	response := &EscalationPolicyResponse{
+		Policies: make([]EscalationPolicyResponseItem, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		response.Policies = append(response.Policies, toEscalationPolicyResponseItem(policy))
+	}
+
+	logger.WithField("created_count", len(response.Policies)).Info("Escalation policies created successfully")
+	return response, nil
+}
+
+// GetPolicy retrieves an escalation policy by UUID
+func (a *EscalationAPI) GetPolicy(uuid string) (*EscalationPolicyResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "GetPolicy",
+		"uuid":      uuid,
+	})
+
+	var policy models.EscalationPolicy
+	if err := a.ReaderDB.Preload("Steps", orderSteps).Where("uuid = ?", uuid).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Escalation policy not found")
+			return nil, errors.New("escalation policy not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve escalation policy")
+		return nil, fmt.Errorf("failed to retrieve escalation policy: %v", err)
+	}
+
+	return &EscalationPolicyResponse{
+		Policies: []EscalationPolicyResponseItem{toEscalationPolicyResponseItem(policy)},
+	}, nil
+}
+
+// ListPolicies lists escalation policies with optional filtering
+func (a *EscalationAPI) ListPolicies(params EscalationPolicyListParams) (*EscalationPolicyResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "ListPolicies",
+		"limit":     params.Limit,
+		"offset":    params.Offset,
+		"tenantId":  params.TenantID,
+	})
+
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	query := a.ReaderDB.Model(&models.EscalationPolicy{}).Preload("Steps", orderSteps)
+	if params.TenantID != "" {
+		query = query.Where("tenant_id = ?", params.TenantID)
+	}
+
+	var policies []models.EscalationPolicy
+	if err := query.Order("id DESC").Limit(params.Limit).Offset(params.Offset).Find(&policies).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve escalation policies")
+		return nil, fmt.Errorf("failed to retrieve escalation policies: %v", err)
+	}
+
+	response := &EscalationPolicyResponse{
+		Policies: make([]EscalationPolicyResponseItem, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		response.Policies = append(response.Policies, toEscalationPolicyResponseItem(policy))
+	}
+
+	logger.WithField("result_count", len(response.Policies)).Info("Escalation policies listed successfully")
+	return response, nil
+}
+
+// UpdatePolicy updates the name, status and steps of an escalation policy.
+// Running escalations keep the steps they were started with.
+func (a *EscalationAPI) UpdatePolicy(uuid string, request EscalationPolicyRequest) (*EscalationPolicyResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "UpdatePolicy",
+		"uuid":      uuid,
+	})
+
+	if len(request.Policies) != 1 {
+		return nil, errors.New("exactly one policy is required")
+	}
+	item := request.Policies[0]
+
+	steps, err := toPolicySteps(item.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy models.EscalationPolicy
+	err = a.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("uuid = ?", uuid).First(&policy).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("escalation policy not found")
+			}
+			return fmt.Errorf("failed to retrieve escalation policy: %v", err)
+		}
+
+		if err := tx.Model(&policy).Updates(map[string]interface{}{
+			"name":   item.Name,
+			"status": item.Status,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update escalation policy: %v", err)
+		}
+
+		// Steps are replaced as a whole so positions stay contiguous
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.EscalationPolicyStep{}).Error; err != nil {
+			return fmt.Errorf("failed to replace escalation policy steps: %v", err)
+		}
+		for i := range steps {
+			steps[i].PolicyID = policy.ID
+		}
+		if err := tx.Create(&steps).Error; err != nil {
+			return fmt.Errorf("failed to replace escalation policy steps: %v", err)
+		}
+		policy.Steps = steps
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to update escalation policy")
+		return nil, err
+	}
+
+	logger.Info("Escalation policy updated successfully")
+	return &EscalationPolicyResponse{
+		Policies: []EscalationPolicyResponseItem{toEscalationPolicyResponseItem(policy)},
+	}, nil
+}
+
+// StartEscalations starts escalations from active policies. The first step runs once its
+// wait has elapsed, later steps are scheduled by the escalation scheduler.
+func (a *EscalationAPI) StartEscalations(request EscalationRequest) (*EscalationResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "StartEscalations",
+		"count":     len(request.Escalations),
+	})
+
+	logger.Info("Starting escalations")
+
+	escalations := make([]models.Escalation, 0, len(request.Escalations))
+	for idx, item := range request.Escalations {
+		var policy models.EscalationPolicy
+		if err := a.ReaderDB.Preload("Steps", orderSteps).
+			Where("code = ? AND tenant_id = ?", item.Policy, item.TenantID).
+			Where("status = 1").
+			First(&policy).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("escalation %d: policy %s not found or inactive", idx, item.Policy)
+			}
+			return nil, fmt.Errorf("failed to retrieve escalation policy: %v", err)
+		}
+		if len(policy.Steps) == 0 {
+			return nil, fmt.Errorf("escalation %d: policy %s has no steps", idx, item.Policy)
+		}
+
+		uuid, err := helper.GenerateUUID()
+		if err != nil {
+			logger.WithError(err).Error("Failed to generate UUID for escalation")
+			return nil, fmt.Errorf("failed to generate UUID: %v", err)
+		}
+
+		// Convert categories and params to JSON
+		categoriesJSON := models.JSON{}
+		for i, category := range item.Categories {
+			categoriesJSON[fmt.Sprintf("%d", i)] = category
+		}
+		paramsJSON := models.JSON{}
+		for key, value := range item.Params {
+			paramsJSON[key] = value
+		}
+
+		escalation := models.Escalation{
+			UUID:        uuid,
+			TenantID:    item.TenantID,
+			PolicyUUID:  policy.UUID,
+			RefNo:       item.RefNo,
+			Status:      models.EscalationStatusActive,
+			Identifiers: item.Identifiers,
+			Categories:  categoriesJSON,
+			Params:      paramsJSON,
+			Subject:     item.Subject,
+		}
+
+		// Copy the policy steps so later policy edits do not affect a running escalation
+		now := time.Now()
+		for i, policyStep := range policy.Steps {
+			step := models.EscalationStep{
+				Position:     policyStep.Position,
+				Channel:      policyStep.Channel,
+				TemplateCode: policyStep.TemplateCode,
+				ProviderUUID: policyStep.ProviderUUID,
+				Recipients:   policyStep.Recipients,
+				WaitSeconds:  policyStep.WaitSeconds,
+				Status:       models.EscalationStepPending,
+			}
+			if i == 0 {
+				dueAt := now.Add(time.Duration(policyStep.WaitSeconds) * time.Second)
+				step.DueAt = &dueAt
+			}
+			escalation.Steps = append(escalation.Steps, step)
+		}
+
+		escalations = append(escalations, escalation)
+	}
+
+	if err := a.DB.Create(&escalations).Error; err != nil {
+		logger.WithError(err).Error("Failed to create escalations")
+		return nil, fmt.Errorf("failed to create escalations: %v", err)
+	}
+
+	response := &EscalationResponse{
+		Escalations: make([]EscalationResponseItem, 0, len(escalations)),
+	}
+	for _, escalation := range escalations {
+		response.Escalations = append(response.Escalations, toEscalationResponseItem(escalation))
+	}
+
+	logger.WithField("started_count", len(response.Escalations)).Info("Escalations started successfully")
+	return response, nil
+}
+
+// GetEscalation retrieves an escalation with its steps by UUID
+func (a *EscalationAPI) GetEscalation(uuid string) (*EscalationResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "GetEscalation",
+		"uuid":      uuid,
+	})
+
+	var escalation models.Escalation
+	if err := a.ReaderDB.Preload("Steps", orderSteps).Where("uuid = ?", uuid).First(&escalation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Escalation not found")
+			return nil, errors.New("escalation not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve escalation")
+		return nil, fmt.Errorf("failed to retrieve escalation: %v", err)
+	}
+
+	return &EscalationResponse{
+		Escalations: []EscalationResponseItem{toEscalationResponseItem(escalation)},
+	}, nil
+}
+
+// ListEscalations lists escalations with optional filtering
+func (a *EscalationAPI) ListEscalations(params EscalationListParams) (*EscalationResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "ListEscalations",
+		"limit":     params.Limit,
+		"offset":    params.Offset,
+		"tenantId":  params.TenantID,
+	})
+
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	query := a.ReaderDB.Model(&models.Escalation{}).Preload("Steps", orderSteps)
+	if params.TenantID != "" {
+		query = query.Where("tenant_id = ?", params.TenantID)
+	}
+	if params.RefNo != "" {
+		query = query.Where("ref_no = ?", params.RefNo)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", strings.ToUpper(params.Status))
+	}
+
+	var escalations []models.Escalation
+	if err := query.Order("id DESC").Limit(params.Limit).Offset(params.Offset).Find(&escalations).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve escalations")
+		return nil, fmt.Errorf("failed to retrieve escalations: %v", err)
+	}
+
+	response := &EscalationResponse{
+		Escalations: make([]EscalationResponseItem, 0, len(escalations)),
+	}
+	for _, escalation := range escalations {
+		response.Escalations = append(response.Escalations, toEscalationResponseItem(escalation))
+	}
+
+	logger.WithField("result_count", len(response.Escalations)).Info("Escalations listed successfully")
+	return response, nil
+}
+
+// CancelEscalation stops an active escalation and cancels its pending steps
+func (a *EscalationAPI) CancelEscalation(uuid string) (*EscalationResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "EscalationAPI",
+		"method":    "CancelEscalation",
+		"uuid":      uuid,
+	})
+
+	if _, err := a.EscalationService.Cancel(uuid); err != nil {
+		logger.WithError(err).Warn("Failed to cancel escalation")
+		return nil, err
+	}
+
+	logger.Info("Escalation cancelled successfully")
+	return a.GetEscalation(uuid)
+}
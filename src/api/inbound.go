@@ -0,0 +1,162 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InboundResponse represents the response body for inbound message APIs
+type InboundResponse struct {
+	Messages []InboundResponseItem `json:"messages"`
+}
+
+// InboundResponseItem represents a single inbound message in the response
+type InboundResponseItem struct {
+	UUID              string      `json:"uuid"`
+	TenantID          string      `json:"tenantId"`
+	Provider          string      `json:"provider"`
+	Channel           string      `json:"channel"`
+	From              string      `json:"from"`
+	To                string      `json:"to"`
+	Body              string      `json:"body"`
+	Media             models.JSON `json:"media,omitempty"`
+	ProviderMessageID string      `json:"providerMessageId"`
+	MessageUUID       string      `json:"messageUuid,omitempty"`
+	AckAction         string      `json:"ackAction,omitempty"`
+	ReceivedAt        string      `json:"receivedAt"`
+}
+
+// InboundListParams represents parameters for listing inbound messages
+type InboundListParams struct {
+	Limit       int    `json:"limit" form:"limit"`
+	Offset      int    `json:"offset" form:"offset"`
+	Channel     string `json:"channel" form:"channel"`
+	TenantID    string `json:"tenantId" form:"tenantId"`
+	From        string `json:"from" form:"from"`
+	MessageUUID string `json:"messageUuid" form:"messageUuid"`
+}
+
+// InboundAPI handles inbound message business logic
+type InboundAPI struct {
+	DB       *gorm.DB
+	ReaderDB *gorm.DB
+}
+
+// NewInboundAPI creates a new inbound message API
+func NewInboundAPI(db *gorm.DB, readerDB *gorm.DB) (*InboundAPI, error) {
+	logger := helper.Log.WithField("component", "InboundAPI")
+
+	if db == nil {
+		logger.Error("Writer database connection is nil")
+		return nil, fmt.Errorf("writer database connection is nil")
+	}
+	if readerDB == nil {
+		logger.Error("Reader database connection is nil")
+		return nil, fmt.Errorf("reader database connection is nil")
+	}
+
+	logger.Info("Inbound API initialized successfully")
+	return &InboundAPI{
+		DB:       db,
+		ReaderDB: readerDB,
+	}, nil
+}
+
+// toInboundResponseItem converts an inbound message model to its response representation
+func toInboundResponseItem(inbound models.InboundMessage) InboundResponseItem {
+	return InboundResponseItem{
+		UUID:              inbound.UUID,
+		TenantID:          inbound.TenantID,
+		Provider:          inbound.ProviderUUID,
+		Channel:           string(inbound.Channel),
+		From:              inbound.From,
+		To:                inbound.To,
+		Body:              inbound.Body,
+		Media:             inbound.Media,
+		ProviderMessageID: inbound.ProviderMessageID,
+		MessageUUID:       inbound.MessageUUID,
+		AckAction:         inbound.AckAction,
+		ReceivedAt:        inbound.ReceivedAt.Format(helper.TimeFormat),
+	}
+}
+
+// GetInboundMessage retrieves an inbound message by UUID
+func (a *InboundAPI) GetInboundMessage(uuid string) (*InboundResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "InboundAPI",
+		"method":    "GetInboundMessage",
+		"uuid":      uuid,
+	})
+
+	var inbound models.InboundMessage
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&inbound).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Inbound message not found")
+			return nil, errors.New("inbound message not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve inbound message")
+		return nil, fmt.Errorf("failed to retrieve inbound message: %v", err)
+	}
+
+	return &InboundResponse{
+		Messages: []InboundResponseItem{toInboundResponseItem(inbound)},
+	}, nil
+}
+
+// ListInboundMessages lists inbound messages with optional filtering, newest first
+func (a *InboundAPI) ListInboundMessages(params InboundListParams) (*InboundResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "InboundAPI",
+		"method":    "ListInboundMessages",
+		"limit":     params.Limit,
+		"offset":    params.Offset,
+		"channel":   params.Channel,
+		"tenantId":  params.TenantID,
+	})
+
+	logger.Info("Listing inbound messages")
+
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	// Build query
+	query := a.ReaderDB.Model(&models.InboundMessage{})
+
+	// Apply filters
+	if params.Channel != "" {
+		query = query.Where("channel = ?", strings.ToUpper(params.Channel))
+	}
+	if params.TenantID != "" {
+		query = query.Where("tenant_id = ?", params.TenantID)
+	}
+	if params.From != "" {
+		query = query.Where("from_address = ?", params.From)
+	}
+	if params.MessageUUID != "" {
+		query = query.Where("message_uuid = ?", params.MessageUUID)
+	}
+
+	var inbound []models.InboundMessage
+	if err := query.Order("id DESC").Limit(params.Limit).Offset(params.Offset).Find(&inbound).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve inbound messages")
+		return nil, fmt.Errorf("failed to retrieve inbound messages: %v", err)
+	}
+
+	response := &InboundResponse{
+		Messages: make([]InboundResponseItem, 0, len(inbound)),
+	}
+	for _, message := range inbound {
+		response.Messages = append(response.Messages, toInboundResponseItem(message))
+	}
+
+	logger.WithField("result_count", len(response.Messages)).Info("Inbound messages listed successfully")
+	return response, nil
+}
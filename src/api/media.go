@@ -0,0 +1,57 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/services/storage"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MediaAPI serves stored message media through signed, expiring URLs
+type MediaAPI struct {
+	Store storage.Store
+}
+
+// NewMediaAPI creates a new media API
+func NewMediaAPI() (*MediaAPI, error) {
+	logger := helper.Log.WithField("component", "MediaAPI")
+
+	store, err := storage.NewStoreFromEnv()
+	if err != nil {
+		logger.WithError(err).Error("Failed to create media store")
+		return nil, err
+	}
+
+	logger.Info("Media API initialized successfully")
+	return &MediaAPI{
+		Store: store,
+	}, nil
+}
+
+// GetMedia verifies the URL signature and returns the stored media
+func (a *MediaAPI) GetMedia(key string, expires string, signature string) (*storage.Object, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "MediaAPI",
+		"method":    "GetMedia",
+		"key":       key,
+	})
+
+	if err := storage.VerifyMediaSignature(key, expires, signature); err != nil {
+		logger.WithError(err).Warn("Rejected media request")
+		return nil, err
+	}
+
+	object, err := a.Store.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logger.Warn("Media not found")
+			return nil, errors.New("media not found")
+		}
+		logger.WithError(err).Error("Failed to read media")
+		return nil, fmt.Errorf("failed to read media: %v", err)
+	}
+
+	return object, nil
+}
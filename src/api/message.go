@@ -0,0 +1,154 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MessageDetailResponse represents a message with its event timeline, replies and escalation
+type MessageDetailResponse struct {
+	UUID            string                  `json:"uuid"`
+	RefNo           string                  `json:"refno"`
+	TenantID        string                  `json:"tenantId"`
+	Channel         string                  `json:"channel"`
+	Status          string                  `json:"status"`
+	Template        string                  `json:"template,omitempty"`
+	TemplateVersion int                     `json:"templateVersion,omitempty"` // Version of the template the message was rendered from
+	Notification    string                  `json:"notification,omitempty"`
+	Identifiers     map[string]interface{}  `json:"identifiers"`
+	Categories      []string                `json:"categories"`
+	Events          []MessageEventItem      `json:"events"`
+	Replies         []InboundResponseItem   `json:"replies"`
+	Escalation      *EscalationResponseItem `json:"escalation,omitempty"`
+	CreatedAt       string                  `json:"createdAt"`
+	UpdatedAt       string                  `json:"updatedAt"`
+}
+
+// MessageEventItem represents a single entry of a message timeline
+type MessageEventItem struct {
+	UUID      string      `json:"uuid"`
+	Status    string      `json:"status"`
+	Reason    string      `json:"reason,omitempty"`
+	Metadata  models.JSON `json:"metadata,omitempty"`
+	Timestamp string      `json:"timestamp"`
+}
+
+// MessageAPI handles message lookup business logic
+type MessageAPI struct {
+	DB       *gorm.DB
+	ReaderDB *gorm.DB
+}
+
+// NewMessageAPI creates a new message API
+func NewMessageAPI(db *gorm.DB, readerDB *gorm.DB) (*MessageAPI, error) {
+	logger := helper.Log.WithField("component", "MessageAPI")
+
+	if db == nil {
+		logger.Error("Writer database connection is nil")
+		return nil, fmt.Errorf("writer database connection is nil")
+	}
+	if readerDB == nil {
+		logger.Error("Reader database connection is nil")
+		return nil, fmt.Errorf("reader database connection is nil")
+	}
+
+	logger.Info("Message API initialized successfully")
+	return &MessageAPI{
+		DB:       db,
+		ReaderDB: readerDB,
+	}, nil
+}
+
+// GetMessage retrieves a message with its event timeline and inbound replies
+func (a *MessageAPI) GetMessage(uuid string) (*MessageDetailResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "MessageAPI",
+		"method":    "GetMessage",
+		"uuid":      uuid,
+	})
+
+	logger.Info("Retrieving message")
+
+	var message models.Message
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Message not found")
+			return nil, errors.New("message not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve message")
+		return nil, fmt.Errorf("failed to retrieve message: %v", err)
+	}
+
+	var events []models.MessageEvent
+	if err := a.ReaderDB.Where("message_id = ?", message.ID).Order("timestamp, id").Find(&events).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve message events")
+		return nil, fmt.Errorf("failed to retrieve message events: %v", err)
+	}
+
+	var replies []models.InboundMessage
+	if err := a.ReaderDB.Where("message_uuid = ?", message.UUID).Order("received_at, id").Find(&replies).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve message replies")
+		return nil, fmt.Errorf("failed to retrieve message replies: %v", err)
+	}
+
+	response := &MessageDetailResponse{
+		UUID:            message.UUID,
+		RefNo:           message.RefNo,
+		TenantID:        message.TenantID,
+		Channel:         string(message.Channel),
+		Status:          string(message.Status),
+		Template:        message.TemplateUUID,
+		TemplateVersion: message.TemplateVersion,
+		Notification:    message.NotificationUUID,
+		Identifiers:     message.Identifiers,
+		Categories:      jsonToStringSlice(message.Categories),
+		Events:          make([]MessageEventItem, 0, len(events)),
+		Replies:         make([]InboundResponseItem, 0, len(replies)),
+		CreatedAt:       message.CreatedAt.Format(helper.TimeFormat),
+		UpdatedAt:       message.UpdatedAt.Format(helper.TimeFormat),
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, MessageEventItem{
+			UUID:      event.UUID,
+			Status:    string(event.Status),
+			Reason:    event.Reason,
+			Metadata:  event.Metadata,
+			Timestamp: event.Timestamp.Format(helper.TimeFormat),
+		})
+	}
+
+	for _, reply := range replies {
+		response.Replies = append(response.Replies, toInboundResponseItem(reply))
+	}
+
+	// Messages sent by an escalation step show the whole escalation chain
+	if message.NotificationUUID != "" {
+		var step models.EscalationStep
+		err := a.ReaderDB.Where("notification_uuid = ?", message.NotificationUUID).First(&step).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.WithError(err).Error("Failed to retrieve escalation step")
+			return nil, fmt.Errorf("failed to retrieve escalation step: %v", err)
+		}
+		if err == nil {
+			var escalation models.Escalation
+			if err := a.ReaderDB.Preload("Steps", orderSteps).Where("id = ?", step.EscalationID).First(&escalation).Error; err != nil {
+				logger.WithError(err).Error("Failed to retrieve escalation")
+				return nil, fmt.Errorf("failed to retrieve escalation: %v", err)
+			}
+			item := toEscalationResponseItem(escalation)
+			response.Escalation = &item
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"event_count": len(response.Events),
+		"reply_count": len(response.Replies),
+	}).Info("Message retrieved successfully")
+	return response, nil
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/queue"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRequest represents the request body for sending multi-channel notifications
+type NotificationRequest struct {
+	Notifications []NotificationItem `json:"notifications" binding:"required,min=1"`
+}
+
+// NotificationItem represents a single multi-channel notification
+type NotificationItem struct {
+	Template    string                  `json:"template" binding:"required"` // Template code, resolved per channel
+	To          []NotificationRecipient `json:"to" binding:"required,min=1"`
+	Channels    []models.Channel        `json:"channels"`  // Channels in order of preference, defaults to WHATSAPP, SMS, EMAIL
+	Strategy    string                  `json:"strategy"`  // ALL or FIRST_AVAILABLE, defaults to ALL
+	Providers   map[string]string       `json:"providers"` // Optional provider UUID per channel
+	RefNo       string                  `json:"refno" binding:"required"`
+	Categories  []string                `json:"categories"`
+	Identifiers map[string]interface{}  `json:"identifiers"`
+	Params      map[string]string       `json:"params"`
+	Subject     string                  `json:"subject"` // Optional email subject override
+	TenantID    string                  `json:"tenantId" binding:"required"`
+}
+
+// NotificationRecipient represents a recipient of a multi-channel notification
+type NotificationRecipient struct {
+	Name      string `json:"name"`
+	Telephone string `json:"telephone"`
+	WhatsApp  string `json:"whatsapp"`
+	Email     string `json:"email"`
+}
+
+// ToModelNotificationMessage converts API NotificationItem to models.NotificationMessage
+func (n *NotificationItem) ToModelNotificationMessage() *models.NotificationMessage {
+	modelMessage := &models.NotificationMessage{
+		Template:    n.Template,
+		Channels:    n.Channels,
+		Strategy:    models.NotificationStrategy(strings.ToUpper(n.Strategy)),
+		RefNo:       n.RefNo,
+		TenantID:    n.TenantID,
+		Categories:  n.Categories,
+		Identifiers: n.Identifiers,
+		Params:      n.Params,
+		Subject:     n.Subject,
+	}
+
+	// Normalise channel names so that lowercase input is accepted
+	for i, channel := range modelMessage.Channels {
+		modelMessage.Channels[i] = models.Channel(strings.ToUpper(string(channel)))
+	}
+
+	modelMessage.Providers = make(map[models.Channel]string, len(n.Providers))
+	for channel, provider := range n.Providers {
+		modelMessage.Providers[models.Channel(strings.ToUpper(channel))] = provider
+	}
+
+	// Convert recipients
+	modelMessage.To = make([]models.NotificationRecipient, len(n.To))
+	for i, recipient := range n.To {
+		modelMessage.To[i] = models.NotificationRecipient{
+			Name:      recipient.Name,
+			Telephone: recipient.Telephone,
+			WhatsApp:  recipient.WhatsApp,
+			Email:     recipient.Email,
+		}
+	}
+
+	return modelMessage
+}
+
+// NotificationResponse represents the response body for sending notifications
+type NotificationResponse struct {
+	Notifications []NotificationItemResponse `json:"notifications"`
+}
+
+// NotificationItemResponse represents a response for a single notification
+type NotificationItemResponse struct {
+	RefNo    string                    `json:"refno"`
+	UUID     string                    `json:"uuid"`
+	Strategy string                    `json:"strategy"`
+	Messages []queue.NotificationChild `json:"messages"`
+	Error    string                    `json:"error,omitempty"` // Set when the notification could not be queued
+}
+
+// NotificationDetailResponse represents a stored notification and its child messages
+type NotificationDetailResponse struct {
+	UUID        string                      `json:"uuid"`
+	RefNo       string                      `json:"refno"`
+	TenantID    string                      `json:"tenantId"`
+	Template    string                      `json:"template"`
+	Strategy    string                      `json:"strategy"`
+	Identifiers map[string]interface{}      `json:"identifiers"`
+	Categories  []string                    `json:"categories"`
+	Messages    []NotificationChildResponse `json:"messages"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+	UpdatedAt   time.Time                   `json:"updatedAt"`
+}
+
+// NotificationChildResponse represents a child message of a notification
+type NotificationChildResponse struct {
+	UUID      string    `json:"uuid"`
+	Channel   string    `json:"channel"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NotificationAPI handles multi-channel notification business logic
+type NotificationAPI struct {
+	DB                   *gorm.DB
+	ReaderDB             *gorm.DB
+	NotificationProducer *queue.NotificationProducer
+}
+
+// NewNotificationAPI creates a new notification API
+func NewNotificationAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClient) (*NotificationAPI, error) {
+	helper.Log.Debug("Initializing Notification API")
+
+	if db == nil {
+		helper.Log.Error("Failed to initialize Notification API: writer database connection is nil")
+		return nil, errors.New("writer database connection is nil")
+	}
+	if readerDB == nil {
+		helper.Log.Error("Failed to initialize Notification API: reader database connection is nil")
+		return nil, errors.New("reader database connection is nil")
+	}
+	if pulsarClient == nil {
+		helper.Log.Error("Failed to initialize Notification API: pulsar client is nil")
+		return nil, errors.New("pulsar client is nil")
+	}
+
+	producer := queue.NewNotificationProducer(pulsarClient, db, readerDB)
+	helper.Log.Info("Notification API initialized successfully")
+
+	return &NotificationAPI{
+		DB:                   db,
+		ReaderDB:             readerDB,
+		NotificationProducer: producer,
+	}, nil
+}
+
+// validateNotification checks the fields that the binding tags cannot express
+func validateNotification(item NotificationItem) error {
+	switch strings.ToUpper(item.Strategy) {
+	case "", string(models.NotificationStrategyAll), string(models.NotificationStrategyFirstAvailable):
+	default:
+		return fmt.Errorf("invalid strategy: %s", item.Strategy)
+	}
+
+	for _, channel := range item.Channels {
+		switch models.Channel(strings.ToUpper(string(channel))) {
+		case models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail:
+		default:
+			return fmt.Errorf("invalid channel: %s", channel)
+		}
+	}
+
+	for _, recipient := range item.To {
+		if recipient.Telephone == "" && recipient.WhatsApp == "" && recipient.Email == "" {
+			return errors.New("each recipient needs at least one of telephone, whatsapp or email")
+		}
+	}
+
+	return nil
+}
+
+// ProcessNotificationBatch processes a batch of multi-channel notifications. Every notification is
+// validated and resolved before any is produced, so an invalid batch queues nothing. A notification
+// that fails to produce afterwards is reported with an error and the others are still queued, the
+// batch only fails when none of them could be queued.
+func (a *NotificationAPI) ProcessNotificationBatch(request NotificationRequest) ([]NotificationItemResponse, error) {
+	batchLogger := helper.Log.WithFields(map[string]interface{}{
+		"batchSize": len(request.Notifications),
+	})
+
+	batchLogger.Info("Starting to process notification batch")
+
+	// Validate the whole batch before anything is queued
+	for _, item := range request.Notifications {
+		if err := validateNotification(item); err != nil {
+			batchLogger.WithError(err).Warn("Invalid notification in batch")
+			return nil, err
+		}
+	}
+
+	// Resolve the channels of the whole batch before anything is queued, a retry of a rejected
+	// batch then never sends a notification twice
+	plans := make([]*queue.NotificationPlan, len(request.Notifications))
+	for idx, item := range request.Notifications {
+		plan, err := a.NotificationProducer.ResolveNotification(item.ToModelNotificationMessage())
+		if err != nil {
+			batchLogger.WithError(err).WithFields(map[string]interface{}{
+				"messageIndex": idx,
+				"refNo":        item.RefNo,
+			}).Warn("Unresolvable notification in batch")
+			return nil, fmt.Errorf("notification %s: %v", item.RefNo, err)
+		}
+		plans[idx] = plan
+	}
+
+	responses := []NotificationItemResponse{}
+	queued := 0
+	for idx, item := range request.Notifications {
+		messageLogger := batchLogger.WithFields(map[string]interface{}{
+			"messageIndex": idx,
+			"template":     item.Template,
+			"refNo":        item.RefNo,
+			"tenantId":     item.TenantID,
+		})
+
+		strategy := strings.ToUpper(item.Strategy)
+		if strategy == "" {
+			strategy = string(models.NotificationStrategyAll)
+		}
+		response := NotificationItemResponse{
+			RefNo:    item.RefNo,
+			Strategy: strategy,
+			Messages: plans[idx].Children(),
+		}
+
+		notificationUUID, err := helper.GenerateUUID()
+		if err != nil {
+			messageLogger.WithError(err).Error("Failed to generate UUID for notification")
+			response.Error = "failed to generate notification ID: " + err.Error()
+			responses = append(responses, response)
+			continue
+		}
+
+		messageLogger = messageLogger.WithField("uuid", notificationUUID)
+		messageLogger.Info("Processing notification")
+
+		children, err := a.NotificationProducer.ProducePlan(plans[idx], notificationUUID)
+		if err != nil {
+			messageLogger.WithError(err).Error("Failed to produce notification")
+			response.Error = "failed to process notification: " + err.Error()
+			if children != nil {
+				// The notification is stored with its failed children and can be looked up
+				response.UUID = notificationUUID
+				response.Messages = children
+			}
+			responses = append(responses, response)
+			continue
+		}
+		queued++
+
+		response.UUID = notificationUUID
+		response.Messages = children
+		responses = append(responses, response)
+	}
+
+	if queued == 0 && len(responses) > 0 {
+		batchLogger.Error("No notification in batch could be queued")
+		return nil, errors.New("failed to process notifications: " + responses[0].Error)
+	}
+
+	batchLogger.WithFields(map[string]interface{}{
+		"responseCount": len(responses),
+		"failed":        len(responses) - queued,
+	}).Info("Successfully processed notification batch")
+	return responses, nil
+}
+
+// GetNotification retrieves a notification and the status of its child messages
+func (a *NotificationAPI) GetNotification(uuid string) (*NotificationDetailResponse, error) {
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"component": "NotificationAPI",
+		"method":    "GetNotification",
+		"uuid":      uuid,
+	})
+
+	logger.Info("Retrieving notification")
+
+	var notification models.Notification
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Notification not found")
+			return nil, errors.New("notification not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve notification")
+		return nil, err
+	}
+
+	var messages []models.Message
+	if err := a.ReaderDB.Where("notification_uuid = ?", uuid).Order("id").Find(&messages).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve notification messages")
+		return nil, err
+	}
+
+	response := &NotificationDetailResponse{
+		UUID:        notification.UUID,
+		RefNo:       notification.RefNo,
+		TenantID:    notification.TenantID,
+		Template:    notification.TemplateCode,
+		Strategy:    string(notification.Strategy),
+		Identifiers: notification.Identifiers,
+		Categories:  jsonToStringSlice(notification.Categories),
+		Messages:    []NotificationChildResponse{},
+		CreatedAt:   notification.CreatedAt,
+		UpdatedAt:   notification.UpdatedAt,
+	}
+
+	for _, message := range messages {
+		response.Messages = append(response.Messages, NotificationChildResponse{
+			UUID:      message.UUID,
+			Channel:   string(message.Channel),
+			Status:    string(message.Status),
+			CreatedAt: message.CreatedAt,
+			UpdatedAt: message.UpdatedAt,
+		})
+	}
+
+	logger.WithField("messageCount", len(response.Messages)).Info("Notification retrieved successfully")
+	return response, nil
+}
+
+// jsonToStringSlice converts an index-keyed JSON array back to a string slice
+func jsonToStringSlice(j models.JSON) []string {
+	result := make([]string, 0, len(j))
+	for i := 0; i < len(j); i++ {
+		if value, ok := j[fmt.Sprintf("%d", i)].(string); ok {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"delivery/models"
+	"reflect"
+	"testing"
+)
+
+func TestValidateNotification(t *testing.T) {
+	recipients := []NotificationRecipient{{Telephone: "+31612345678"}}
+
+	tests := []struct {
+		name    string
+		item    NotificationItem
+		wantErr bool
+	}{
+		{name: "defaults", item: NotificationItem{To: recipients}},
+		{name: "ALL", item: NotificationItem{To: recipients, Strategy: "ALL"}},
+		{name: "FIRST_AVAILABLE in lower case", item: NotificationItem{To: recipients, Strategy: "first_available"}},
+		{name: "unknown strategy", item: NotificationItem{To: recipients, Strategy: "ANY"}, wantErr: true},
+		{name: "channels in lower case", item: NotificationItem{To: recipients, Channels: []models.Channel{"sms", "whatsapp", "email"}}},
+		{name: "unknown channel", item: NotificationItem{To: recipients, Channels: []models.Channel{"SMS", "PUSH"}}, wantErr: true},
+		{name: "recipient without contact details", item: NotificationItem{To: []NotificationRecipient{{Name: "Jane"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNotification(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToModelNotificationMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		item          NotificationItem
+		wantStrategy  models.NotificationStrategy
+		wantChannels  []models.Channel
+		wantProviders map[models.Channel]string
+	}{
+		{
+			name:          "defaults are left to the producer",
+			item:          NotificationItem{},
+			wantProviders: map[models.Channel]string{},
+		},
+		{
+			name: "strategy, channels and providers are upper cased",
+			item: NotificationItem{
+				Strategy:  "first_available",
+				Channels:  []models.Channel{"email", "Sms"},
+				Providers: map[string]string{"sms": "provider-uuid"},
+			},
+			wantStrategy:  models.NotificationStrategyFirstAvailable,
+			wantChannels:  []models.Channel{models.ChannelEmail, models.ChannelSMS},
+			wantProviders: map[models.Channel]string{models.ChannelSMS: "provider-uuid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.item.ToModelNotificationMessage()
+			if message.Strategy != tt.wantStrategy {
+				t.Errorf("Strategy = %q, want %q", message.Strategy, tt.wantStrategy)
+			}
+			if !reflect.DeepEqual(message.Channels, tt.wantChannels) {
+				t.Errorf("Channels = %v, want %v", message.Channels, tt.wantChannels)
+			}
+			if !reflect.DeepEqual(message.Providers, tt.wantProviders) {
+				t.Errorf("Providers = %v, want %v", message.Providers, tt.wantProviders)
+			}
+		})
+	}
+}
@@ -3,13 +3,26 @@ package api
 import (
 	"delivery/helper"
 	"delivery/models"
+	"delivery/services"
+	"delivery/services/providers"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// ErrInvalidProviderConfig is returned when a provider configuration does not match the schema of its implementation
+var ErrInvalidProviderConfig = errors.New("invalid provider configuration")
+
+// testMessage is the body of messages sent by provider tests
+const testMessage = "This is a test message from the delivery service."
+
 // ProviderRequest represents the request body for provider management APIs
 type ProviderRequest struct {
 	Providers []ProviderRequestItem `json:"providers" binding:"required,min=1"`
@@ -47,6 +60,35 @@ type ProviderResponseItem struct {
 	UpdatedAt string      `json:"updatedAt"`
 }
 
+// ProviderTypesResponse represents the response body for the provider types API
+type ProviderTypesResponse struct {
+	ProviderTypes []registry.ProviderType `json:"providerTypes"`
+}
+
+// ProviderHealthResponse is the health of a provider on this instance
+type ProviderHealthResponse struct {
+	services.ProviderHealth
+	Provider string `json:"provider"`
+	Channel  string `json:"channel"`
+}
+
+// ProviderTestRequest represents the request body for testing a provider
+type ProviderTestRequest struct {
+	To string `json:"to,omitempty"` // Address that receives a test message, only the credentials are checked when empty
+}
+
+// ProviderTestResponse represents the result of a provider test
+type ProviderTestResponse struct {
+	UUID        string `json:"uuid"`
+	Provider    string `json:"provider"`
+	Channel     string `json:"channel"`
+	Success     bool   `json:"success"`
+	Credentials string `json:"credentials"` // passed, failed or unsupported
+	TestSend    string `json:"testSend"`    // sent, failed or skipped
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"durationMs"`
+}
+
 // ProviderListParams represents parameters for listing providers
 type ProviderListParams struct {
 	Limit    int    `json:"limit" form:"limit"`
@@ -57,8 +99,9 @@ type ProviderListParams struct {
 
 // ProviderAPI handles provider business logic
 type ProviderAPI struct {
-	DB       *gorm.DB
-	ReaderDB *gorm.DB
+	DB                 *gorm.DB
+	ReaderDB           *gorm.DB
+	KeyRotationService *services.KeyRotationService
 }
 
 // NewProviderAPI creates a new provider API
@@ -74,10 +117,17 @@ func NewProviderAPI(db *gorm.DB, readerDB *gorm.DB) (*ProviderAPI, error) {
 		return nil, fmt.Errorf("reader database connection is nil")
 	}
 
+	keyRotationService, err := services.NewKeyRotationService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create key rotation service")
+		return nil, err
+	}
+
 	logger.Info("Provider API initialized successfully")
 	return &ProviderAPI{
-		DB:       db,
-		ReaderDB: readerDB,
+		DB:                 db,
+		ReaderDB:           readerDB,
+		KeyRotationService: keyRotationService,
 	}, nil
 }
 
@@ -110,6 +160,19 @@ func (a *ProviderAPI) CreateProviders(request ProviderRequest) (*ProviderRespons
 
 		providerLogger.Debug("Processing provider item")
 
+		// Reject configurations the implementation cannot work with before they reach the consumers
+		providerType, err := registry.Resolve(models.Channel(providerItem.Channel), providerItem.Provider)
+		if err == nil {
+			err = providerType.ValidateConfig(providerItem.Config)
+		}
+		if err == nil {
+			err = validateSecureConfig(providerType, providerItem.SecureConfig)
+		}
+		if err != nil {
+			providerLogger.WithError(err).Warn("Invalid provider configuration")
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+		}
+
 		// Encrypt secureConfig
 		encryptedConfig, err := a.encryptSecureConfig(providerItem.SecureConfig)
 		if err != nil {
@@ -246,6 +309,31 @@ func (a *ProviderAPI) UpdateProvider(uuid string, request ProviderRequest) (*Pro
 		return nil, fmt.Errorf("provider implementation cannot be changed")
 	}
 
+	// Validate the configuration the provider ends up with, the new one or the stored one, against the
+	// implementation on the channel the provider ends up on
+	channel := provider.Channel
+	if providerItem.Channel != "" {
+		channel = models.Channel(providerItem.Channel)
+	}
+	config := provider.Config
+	if providerItem.Config != nil {
+		config = providerItem.Config
+	}
+	providerType, err := registry.Resolve(channel, provider.Provider)
+	if err == nil && (providerItem.Config != nil || channel != provider.Channel) {
+		err = providerType.ValidateConfig(config)
+	}
+	if err == nil && providerItem.SecureConfig != nil {
+		err = validateSecureConfig(providerType, providerItem.SecureConfig)
+	} else if err == nil && channel != provider.Channel {
+		// The stored secrets must fit the implementation on the new channel as well
+		err = validateStoredSecureConfig(providerType, provider.SecureConfig)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Invalid provider configuration")
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+	}
+
 	if providerItem.Name != "" {
 		updates["name"] = providerItem.Name
 	}
@@ -315,6 +403,9 @@ func (a *ProviderAPI) UpdateProvider(uuid string, request ProviderRequest) (*Pro
 			return nil, fmt.Errorf("failed to update provider: %v", err)
 		}
 		logger.Debug("Provider updated in database")
+
+		// Consumers on this instance pick up the change with the next message
+		providers.InvalidateProvider(provider.UUID)
 	} else {
 		logger.Debug("No fields to update")
 	}
@@ -485,6 +576,30 @@ func (a *ProviderAPI) ListProviders(limit int, offset int, channel string, tenan
 	return response, total, nil
 }
 
+// validateSecureConfig validates a secure config against the implementation schema. References to
+// external secrets are only checked for their shape, the secret itself is read when the provider is used.
+func validateSecureConfig(providerType *registry.ProviderType, secureConfig models.JSON) error {
+	if secrets.IsReference(secureConfig) {
+		return secrets.ValidateReference(secureConfig)
+	}
+	return providerType.ValidateSecureConfig(secureConfig)
+}
+
+// validateStoredSecureConfig decrypts a stored secure config and checks it like a new one
+func validateStoredSecureConfig(providerType *registry.ProviderType, stored models.JSON) error {
+	if secrets.IsReference(stored) {
+		return secrets.ValidateReference(stored)
+	}
+
+	var secureConfig models.JSON
+	if _, encrypted := stored["encrypted"]; encrypted {
+		if err := helper.DecryptSecureConfig(stored, &secureConfig); err != nil {
+			return err
+		}
+	}
+	return providerType.ValidateSecureConfig(secureConfig)
+}
+
 // encryptSecureConfig encrypts sensitive provider configuration
 func (a *ProviderAPI) encryptSecureConfig(secureConfig models.JSON) (models.JSON, error) {
 	logger := helper.Log.WithFields(logrus.Fields{
@@ -492,6 +607,12 @@ func (a *ProviderAPI) encryptSecureConfig(secureConfig models.JSON) (models.JSON
 		"method":    "encryptSecureConfig",
 	})
 
+	// A reference to an external secret holds no credentials and is stored as is
+	if secrets.IsReference(secureConfig) {
+		logger.WithField("backend", secrets.Backend(secureConfig)).Debug("Secure config references an external secret")
+		return secureConfig, nil
+	}
+
 	logger.Debug("Encrypting secure configuration")
 
 	// Convert JSON to string
@@ -501,25 +622,178 @@ func (a *ProviderAPI) encryptSecureConfig(secureConfig models.JSON) (models.JSON
 		return nil, fmt.Errorf("failed to marshal secure config: %w", err)
 	}
 
-	// Get encryption key from environment
-	encryptionKey := []byte(helper.GetEnv("ENCRYPTION_KEY", ""))
-	if len(encryptionKey) != 32 {
-		// For development, use a fixed key if not provided
-		encryptionKey = []byte("12345678901234567890123456789012")
-		logger.Debug("Using default development encryption key")
-	} else {
-		logger.Debug("Using environment encryption key")
+	// Get the encryption keys from environment
+	keyring, err := helper.LoadKeyring()
+	if err != nil {
+		logger.WithError(err).Error("Invalid encryption key configuration")
+		return nil, fmt.Errorf("invalid encryption key configuration: %w", err)
 	}
 
-	// Encrypt and encode to base64
-	encryptedBase64, err := helper.EncryptAndEncodeBase64(string(secureConfigBytes), encryptionKey)
+	// Encrypt with the current key, the key ID is stored with the ciphertext
+	encrypted, err := keyring.Encrypt(secureConfigBytes)
 	if err != nil {
 		logger.WithError(err).Error("Failed to encrypt secure config")
 		return nil, fmt.Errorf("failed to encrypt secure config: %w", err)
 	}
 
-	logger.WithField("encrypted_length", len(encryptedBase64)).Debug("Secure config encrypted successfully")
+	logger.WithField("key_id", keyring.CurrentKeyID()).Debug("Secure config encrypted successfully")
+	return models.JSON(encrypted), nil
+}
+
+// ListProviderTypes returns the provider implementations that can be configured, optionally for one channel
+func (a *ProviderAPI) ListProviderTypes(channel string) *ProviderTypesResponse {
+	return &ProviderTypesResponse{
+		ProviderTypes: providers.ProviderTypes(models.Channel(strings.ToUpper(channel))),
+	}
+}
 
-	// Create a new JSON object with the encrypted data
-	return models.JSON{"encrypted": encryptedBase64}, nil
+// TestProvider builds a provider through its factory and checks that it works, by verifying the credentials
+// with the provider and, when an address is given, sending a test message to it
+func (a *ProviderAPI) TestProvider(uuid string, request ProviderTestRequest) (*ProviderTestResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "ProviderAPI",
+		"method":    "TestProvider",
+		"uuid":      uuid,
+	})
+
+	if uuid == "" {
+		logger.Error("Missing provider UUID")
+		return nil, fmt.Errorf("missing provider UUID")
+	}
+
+	var provider models.Provider
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&provider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Provider not found")
+			return nil, errors.New("provider not found")
+		}
+		logger.WithError(err).Error("Failed to fetch provider")
+		return nil, fmt.Errorf("failed to fetch provider: %v", err)
+	}
+
+	response := &ProviderTestResponse{
+		UUID:        provider.UUID,
+		Provider:    provider.Provider,
+		Channel:     string(provider.Channel),
+		Credentials: "unsupported",
+		TestSend:    "skipped",
+	}
+	started := time.Now()
+	defer func() {
+		response.DurationMs = time.Since(started).Milliseconds()
+	}()
+
+	// Inactive providers can be tested too, so they can be checked before they are enabled
+	var instance interface{}
+	var send func() error
+	var err error
+	switch provider.Channel {
+	case models.ChannelSMS:
+		var smsProvider services.SMSService
+		smsProvider, err = providers.CreateSMSProvider(&provider)
+		instance = smsProvider
+		send = func() error { return smsProvider.Send(request.To, testMessage) }
+	case models.ChannelEmail:
+		var emailProvider services.EmailService
+		emailProvider, err = providers.CreateEmailProvider(&provider)
+		instance = emailProvider
+		send = func() error {
+			return emailProvider.Send([]string{request.To}, "Delivery service test", testMessage, false, nil)
+		}
+	case models.ChannelWhatsApp:
+		var whatsappProvider services.WhatsAppService
+		whatsappProvider, err = providers.CreateWhatsAppProvider(&provider)
+		instance = whatsappProvider
+		send = func() error { return whatsappProvider.SendText(request.To, testMessage) }
+	default:
+		err = fmt.Errorf("unsupported channel: %s", provider.Channel)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to create provider for test")
+		response.Credentials = "failed"
+		response.Error = err.Error()
+		return response, nil
+	}
+
+	if checker, ok := instance.(services.CredentialChecker); ok {
+		if err := checker.CheckCredentials(); err != nil {
+			logger.WithError(err).Warn("Provider credential check failed")
+			response.Credentials = "failed"
+			response.Error = err.Error()
+			return response, nil
+		}
+		response.Credentials = "passed"
+	}
+
+	if request.To != "" {
+		if err := send(); err != nil {
+			logger.WithError(err).WithField("to", request.To).Warn("Provider test send failed")
+			response.TestSend = "failed"
+			response.Error = err.Error()
+			return response, nil
+		}
+		response.TestSend = "sent"
+	}
+
+	// Without a credential check and a test send nothing was verified
+	if response.Credentials == "unsupported" && response.TestSend == "skipped" {
+		logger.Info("Provider test checked nothing, no credential check and no test recipient")
+		response.Error = "the provider has no credential check, give a to address to test it with a test send"
+		return response, nil
+	}
+
+	response.Success = true
+	logger.WithFields(logrus.Fields{
+		"credentials": response.Credentials,
+		"testSend":    response.TestSend,
+	}).Info("Provider test passed")
+	return response, nil
+}
+
+// GetProviderHealth returns the circuit breaker state and rolling success rates of a provider,
+// as seen by the consumers of this instance
+func (a *ProviderAPI) GetProviderHealth(uuid string) (*ProviderHealthResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "ProviderAPI",
+		"method":    "GetProviderHealth",
+		"uuid":      uuid,
+	})
+
+	if uuid == "" {
+		logger.Error("Missing provider UUID")
+		return nil, fmt.Errorf("missing provider UUID")
+	}
+
+	var provider models.Provider
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&provider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Provider not found")
+			return nil, errors.New("provider not found")
+		}
+		logger.WithError(err).Error("Failed to fetch provider")
+		return nil, fmt.Errorf("failed to fetch provider: %v", err)
+	}
+
+	return &ProviderHealthResponse{
+		ProviderHealth: services.GetProviderHealth(provider.UUID),
+		Provider:       provider.Provider,
+		Channel:        string(provider.Channel),
+	}, nil
+}
+
+// RotateEncryptionKey re-encrypts the provider secure configs with the current encryption key
+func (a *ProviderAPI) RotateEncryptionKey(force bool) (*services.KeyRotationResult, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "ProviderAPI",
+		"method":    "RotateEncryptionKey",
+		"force":     force,
+	})
+
+	logger.Info("Re-encrypting provider secure configs")
+	result, err := a.KeyRotationService.RotateProviderSecrets(force)
+	if err != nil {
+		logger.WithError(err).Error("Failed to re-encrypt provider secure configs")
+		return nil, err
+	}
+	return result, nil
 }
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestValidateStoredSecureConfig(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("ENCRYPTION_KEYS", "one:11111111111111111111111111111111")
+	t.Setenv("ENCRYPTION_KEY_ID", "one")
+	t.Setenv("DEV_MODE", "")
+
+	keyring, err := helper.LoadKeyring()
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	encrypt := func(plaintext string) models.JSON {
+		encrypted, err := keyring.Encrypt([]byte(plaintext))
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		return encrypted
+	}
+
+	providerType := &registry.ProviderType{
+		SecureConfigSchema: []registry.Field{{Name: "authToken", Type: registry.FieldString, Required: true}},
+	}
+
+	tests := []struct {
+		name    string
+		stored  models.JSON
+		wantErr bool
+	}{
+		{name: "encrypted secrets that fit the schema", stored: encrypt(`{"authToken":"a1b2c3"}`)},
+		{name: "encrypted secrets without a required field", stored: encrypt(`{"apiSecret":"a1b2c3"}`), wantErr: true},
+		{name: "no stored secrets", stored: models.JSON{}, wantErr: true},
+		{name: "reference to an external secret", stored: models.JSON{"backend": "env", "ref": "TWILIO"}},
+		{name: "reference without a ref", stored: models.JSON{"backend": "vault"}, wantErr: true},
+		{name: "secrets that cannot be decrypted", stored: models.JSON{"encrypted": "AAAA", "keyId": "one", "version": 2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStoredSecureConfig(providerType, tt.stored)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStoredSecureConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeSMSProvider is an SMS provider without a credential check, its sends fail with sendErr
+type fakeSMSProvider struct {
+	sendErr error
+}
+
+func (p *fakeSMSProvider) Send(to string, message string) error       { return p.sendErr }
+func (p *fakeSMSProvider) SendBulk(to []string, message string) error { return p.sendErr }
+func (p *fakeSMSProvider) SendTemplate(to string, templateName string, params map[string]string) error {
+	return p.sendErr
+}
+func (p *fakeSMSProvider) GetStatus(messageID string) (types.DeliveryStatus, error) {
+	return types.DeliveryStatus{}, nil
+}
+
+// fakeCheckedSMSProvider adds a credential check failing with checkErr
+type fakeCheckedSMSProvider struct {
+	fakeSMSProvider
+	checkErr error
+}
+
+func (p *fakeCheckedSMSProvider) CheckCredentials() error { return p.checkErr }
+
+// configError returns the error named by a config field of a fake provider
+func configError(provider *models.Provider, field string) error {
+	if message, _ := provider.Config[field].(string); message != "" {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:    "FAKE_UNCHECKED",
+		Channel: models.ChannelSMS,
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return &fakeSMSProvider{sendErr: configError(provider, "sendError")}, nil
+		},
+	})
+	registry.Register(registry.ProviderType{
+		Name:    "FAKE_CHECKED",
+		Channel: models.ChannelSMS,
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return &fakeCheckedSMSProvider{
+				fakeSMSProvider: fakeSMSProvider{sendErr: configError(provider, "sendError")},
+				checkErr:        configError(provider, "checkError"),
+			}, nil
+		},
+	})
+}
+
+func TestTestProvider(t *testing.T) {
+	tests := []struct {
+		name            string
+		provider        string
+		config          string
+		to              string
+		wantSuccess     bool
+		wantCredentials string
+		wantTestSend    string
+	}{
+		{
+			name:            "nothing checked without a credential check or recipient",
+			provider:        "FAKE_UNCHECKED",
+			wantCredentials: "unsupported",
+			wantTestSend:    "skipped",
+		},
+		{
+			name:            "test send without a credential check",
+			provider:        "FAKE_UNCHECKED",
+			to:              "+15550100",
+			wantSuccess:     true,
+			wantCredentials: "unsupported",
+			wantTestSend:    "sent",
+		},
+		{
+			name:            "failed test send",
+			provider:        "FAKE_UNCHECKED",
+			config:          `{"sendError":"status code: 401"}`,
+			to:              "+15550100",
+			wantCredentials: "unsupported",
+			wantTestSend:    "failed",
+		},
+		{
+			name:            "passed credential check",
+			provider:        "FAKE_CHECKED",
+			wantSuccess:     true,
+			wantCredentials: "passed",
+			wantTestSend:    "skipped",
+		},
+		{
+			name:            "failed credential check skips the test send",
+			provider:        "FAKE_CHECKED",
+			config:          `{"checkError":"status code: 401"}`,
+			to:              "+15550100",
+			wantCredentials: "failed",
+			wantTestSend:    "skipped",
+		},
+		{
+			name:            "implementation that is not registered",
+			provider:        "UNKNOWN",
+			wantCredentials: "failed",
+			wantTestSend:    "skipped",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			uuid := fmt.Sprintf("provider-test-%d", i)
+			config := tt.config
+			if config == "" {
+				config = `{}`
+			}
+			mock.ExpectQuery(`SELECT \* FROM "providers" WHERE uuid = \$1`).
+				WithArgs(uuid, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "provider", "channel", "config", "secure_config"}).
+					AddRow(1, uuid, tt.provider, models.ChannelSMS, []byte(config), []byte(`{}`)))
+
+			api := &ProviderAPI{DB: db, ReaderDB: db}
+			response, err := api.TestProvider(uuid, ProviderTestRequest{To: tt.to})
+			if err != nil {
+				t.Fatalf("TestProvider() error = %v", err)
+			}
+			if response.Success != tt.wantSuccess || response.Credentials != tt.wantCredentials || response.TestSend != tt.wantTestSend {
+				t.Errorf("TestProvider() = success %v, credentials %s, test send %s, want %v, %s, %s",
+					response.Success, response.Credentials, response.TestSend, tt.wantSuccess, tt.wantCredentials, tt.wantTestSend)
+			}
+			if !response.Success && response.Error == "" {
+				t.Error("TestProvider() failed without an error")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCreateProvidersRejectsInvalidConfig(t *testing.T) {
+	validConfig := models.JSON{"apiKey": "a1b2c3", "fromNumber": "+15550100"}
+
+	tests := []struct {
+		name         string
+		provider     string
+		channel      string
+		config       models.JSON
+		secureConfig models.JSON
+	}{
+		{
+			name:         "implementation not supported on the channel",
+			provider:     "VONAGE",
+			channel:      string(models.ChannelEmail),
+			config:       validConfig,
+			secureConfig: models.JSON{"apiSecret": "s3cr3t"},
+		},
+		{
+			name:         "missing required config field",
+			provider:     "VONAGE",
+			channel:      string(models.ChannelSMS),
+			config:       models.JSON{"apiKey": "a1b2c3"},
+			secureConfig: models.JSON{"apiSecret": "s3cr3t"},
+		},
+		{
+			name:         "missing required secret",
+			provider:     "VONAGE",
+			channel:      string(models.ChannelSMS),
+			config:       validConfig,
+			secureConfig: models.JSON{},
+		},
+		{
+			name:         "placeholder secret",
+			provider:     "NEXMO",
+			channel:      string(models.ChannelSMS),
+			config:       validConfig,
+			secureConfig: models.JSON{"apiSecret": "your-api-secret"},
+		},
+		{
+			name:         "credential stored next to a secret reference",
+			provider:     "VONAGE",
+			channel:      string(models.ChannelSMS),
+			config:       validConfig,
+			secureConfig: models.JSON{"backend": "vault", "ref": "delivery/vonage", "apiSecret": "s3cr3t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No query is expected, invalid configurations are rejected before the database is used
+			db, mock := newMockDB(t)
+			api := &ProviderAPI{DB: db, ReaderDB: db}
+
+			_, err := api.CreateProviders(ProviderRequest{Providers: []ProviderRequestItem{{
+				Code:         "vonage",
+				Provider:     tt.provider,
+				Name:         "Vonage",
+				Channel:      tt.channel,
+				Config:       tt.config,
+				SecureConfig: tt.secureConfig,
+			}}})
+			if !errors.Is(err, ErrInvalidProviderConfig) {
+				t.Errorf("CreateProviders() error = %v, want ErrInvalidProviderConfig", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SuppressionRequest represents the request body for adding suppressions
+type SuppressionRequest struct {
+	Suppressions []SuppressionRequestItem `json:"suppressions" binding:"required,min=1"`
+}
+
+// SuppressionRequestItem represents a single suppression in the suppression request
+type SuppressionRequestItem struct {
+	Channel  string `json:"channel" binding:"required"`
+	Address  string `json:"address" binding:"required"`
+	Reason   string `json:"reason"` // Defaults to MANUAL
+	Details  string `json:"details"`
+	TenantID string `json:"tenantId" binding:"required"`
+}
+
+// SuppressionResponse represents the response body for suppression APIs
+type SuppressionResponse struct {
+	Suppressions []SuppressionResponseItem `json:"suppressions"`
+}
+
+// SuppressionResponseItem represents a single suppression in the suppression response
+type SuppressionResponseItem struct {
+	UUID      string `json:"uuid"`
+	Channel   string `json:"channel"`
+	Address   string `json:"address"`
+	Reason    string `json:"reason"`
+	Source    string `json:"source"`
+	Details   string `json:"details"`
+	TenantID  string `json:"tenantId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SuppressionListParams represents parameters for listing suppressions
+type SuppressionListParams struct {
+	Limit    int    `json:"limit" form:"limit"`
+	Offset   int    `json:"offset" form:"offset"`
+	Channel  string `json:"channel" form:"channel"`
+	TenantID string `json:"tenantId" form:"tenantId"`
+	Address  string `json:"address" form:"address"`
+	Reason   string `json:"reason" form:"reason"`
+}
+
+// SuppressionAPI handles suppression list business logic
+type SuppressionAPI struct {
+	DB                 *gorm.DB
+	ReaderDB           *gorm.DB
+	SuppressionService *services.SuppressionService
+}
+
+// NewSuppressionAPI creates a new suppression API
+func NewSuppressionAPI(db *gorm.DB, readerDB *gorm.DB) (*SuppressionAPI, error) {
+	logger := helper.Log.WithField("component", "SuppressionAPI")
+
+	if db == nil {
+		logger.Error("Writer database connection is nil")
+		return nil, fmt.Errorf("writer database connection is nil")
+	}
+	if readerDB == nil {
+		logger.Error("Reader database connection is nil")
+		return nil, fmt.Errorf("reader database connection is nil")
+	}
+
+	suppressionService, err := services.NewSuppressionService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create suppression service")
+		return nil, err
+	}
+
+	logger.Info("Suppression API initialized successfully")
+	return &SuppressionAPI{
+		DB:                 db,
+		ReaderDB:           readerDB,
+		SuppressionService: suppressionService,
+	}, nil
+}
+
+// toSuppressionResponseItem converts a suppression model to its response representation
+func toSuppressionResponseItem(suppression models.Suppression) SuppressionResponseItem {
+	return SuppressionResponseItem{
+		UUID:      suppression.UUID,
+		Channel:   string(suppression.Channel),
+		Address:   suppression.Address,
+		Reason:    string(suppression.Reason),
+		Source:    string(suppression.Source),
+		Details:   suppression.Details,
+		TenantID:  suppression.TenantID,
+		CreatedAt: suppression.CreatedAt.Format(helper.TimeFormat),
+		UpdatedAt: suppression.UpdatedAt.Format(helper.TimeFormat),
+	}
+}
+
+// CreateSuppressions adds addresses to the suppression list
+func (a *SuppressionAPI) CreateSuppressions(request SuppressionRequest) (*SuppressionResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "SuppressionAPI",
+		"method":    "CreateSuppressions",
+		"count":     len(request.Suppressions),
+	})
+
+	logger.Info("Creating suppressions")
+
+	// Validate the whole batch before anything is written
+	for _, item := range request.Suppressions {
+		switch models.Channel(strings.ToUpper(item.Channel)) {
+		case models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail:
+		default:
+			return nil, fmt.Errorf("invalid channel: %s", item.Channel)
+		}
+
+		switch models.SuppressionReason(strings.ToUpper(item.Reason)) {
+		case "", models.SuppressionReasonBounce, models.SuppressionReasonSpamReport,
+			models.SuppressionReasonUnsubscribe, models.SuppressionReasonOptOut, models.SuppressionReasonManual:
+		default:
+			return nil, fmt.Errorf("invalid reason: %s", item.Reason)
+		}
+	}
+
+	response := &SuppressionResponse{
+		Suppressions: make([]SuppressionResponseItem, 0, len(request.Suppressions)),
+	}
+
+	for idx, item := range request.Suppressions {
+		reason := models.SuppressionReason(strings.ToUpper(item.Reason))
+		if reason == "" {
+			reason = models.SuppressionReasonManual
+		}
+
+		suppression := models.Suppression{
+			TenantID: item.TenantID,
+			Channel:  models.Channel(strings.ToUpper(item.Channel)),
+			Address:  item.Address,
+			Reason:   reason,
+			Source:   models.SuppressionSourceAPI,
+			Details:  item.Details,
+		}
+
+		if err := a.SuppressionService.Suppress(&suppression); err != nil {
+			logger.WithError(err).WithField("index", idx).Error("Failed to create suppression")
+			return nil, err
+		}
+
+		// Re-read the row as an existing entry keeps its original UUID and creation time
+		var saved models.Suppression
+		if err := a.DB.Where("tenant_id = ? AND channel = ? AND address = ?", suppression.TenantID, suppression.Channel, suppression.Address).
+			First(&saved).Error; err != nil {
+			logger.WithError(err).WithField("index", idx).Error("Failed to read back suppression")
+			return nil, fmt.Errorf("failed to read back suppression: %v", err)
+		}
+
+		response.Suppressions = append(response.Suppressions, toSuppressionResponseItem(saved))
+	}
+
+	logger.WithField("created_count", len(response.Suppressions)).Info("Suppressions created successfully")
+	return response, nil
+}
+
+// GetSuppression retrieves a suppression by UUID
+func (a *SuppressionAPI) GetSuppression(uuid string) (*SuppressionResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "SuppressionAPI",
+		"method":    "GetSuppression",
+		"uuid":      uuid,
+	})
+
+	var suppression models.Suppression
+	if err := a.ReaderDB.Where("uuid = ?", uuid).First(&suppression).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Suppression not found")
+			return nil, errors.New("suppression not found")
+		}
+		logger.WithError(err).Error("Failed to retrieve suppression")
+		return nil, fmt.Errorf("failed to retrieve suppression: %v", err)
+	}
+
+	return &SuppressionResponse{
+		Suppressions: []SuppressionResponseItem{toSuppressionResponseItem(suppression)},
+	}, nil
+}
+
+// ListSuppressions lists suppressions with optional filtering
+func (a *SuppressionAPI) ListSuppressions(params SuppressionListParams) (*SuppressionResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "SuppressionAPI",
+		"method":    "ListSuppressions",
+		"limit":     params.Limit,
+		"offset":    params.Offset,
+		"channel":   params.Channel,
+		"tenantId":  params.TenantID,
+	})
+
+	logger.Info("Listing suppressions")
+
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	// Build query
+	query := a.ReaderDB.Model(&models.Suppression{})
+
+	// Apply filters
+	if params.Channel != "" {
+		query = query.Where("channel = ?", strings.ToUpper(params.Channel))
+	}
+	if params.TenantID != "" {
+		query = query.Where("tenant_id = ?", params.TenantID)
+	}
+	if params.Address != "" {
+		query = query.Where("address = ?", services.NormalizeAddress(models.Channel(strings.ToUpper(params.Channel)), params.Address))
+	}
+	if params.Reason != "" {
+		query = query.Where("reason = ?", strings.ToUpper(params.Reason))
+	}
+
+	var suppressions []models.Suppression
+	if err := query.Order("id DESC").Limit(params.Limit).Offset(params.Offset).Find(&suppressions).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve suppressions")
+		return nil, fmt.Errorf("failed to retrieve suppressions: %v", err)
+	}
+
+	response := &SuppressionResponse{
+		Suppressions: make([]SuppressionResponseItem, 0, len(suppressions)),
+	}
+	for _, suppression := range suppressions {
+		response.Suppressions = append(response.Suppressions, toSuppressionResponseItem(suppression))
+	}
+
+	logger.WithField("result_count", len(response.Suppressions)).Info("Suppressions listed successfully")
+	return response, nil
+}
+
+// DeleteSuppression removes a suppression by UUID so the address can be messaged again
+func (a *SuppressionAPI) DeleteSuppression(uuid string) error {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "SuppressionAPI",
+		"method":    "DeleteSuppression",
+		"uuid":      uuid,
+	})
+
+	result := a.DB.Where("uuid = ?", uuid).Delete(&models.Suppression{})
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to delete suppression")
+		return fmt.Errorf("failed to delete suppression: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		logger.Warn("Suppression not found")
+		return errors.New("suppression not found")
+	}
+
+	logger.Info("Suppression deleted successfully")
+	return nil
+}
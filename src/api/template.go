@@ -4,9 +4,11 @@ import (
 	"delivery/helper"
 	"delivery/models"
 	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TemplateRequest represents the request body for template management APIs
@@ -21,8 +23,10 @@ type TemplateRequestItem struct {
 	Name        string      `json:"name" binding:"required"`
 	Subject     string      `json:"subject"`
 	Content     string      `json:"content" binding:"required"`
+	TextContent string      `json:"textContent"` // Plain-text alternative for EMAIL templates
 	Channel     string      `json:"channel" binding:"required"`
 	TemplateIds models.JSON `json:"templateIds"`
+	AckKeywords models.JSON `json:"ackKeywords"` // Reply keyword to action (ACK or ESCALATE)
 	TenantID    string      `json:"tenantId" binding:"required"`
 	Status      *int        `json:"status,omitempty"`
 }
@@ -39,14 +43,53 @@ type TemplateResponseItem struct {
 	Name        string      `json:"name"`
 	Subject     string      `json:"subject"`
 	Content     string      `json:"content"`
+	TextContent string      `json:"textContent,omitempty"`
 	Channel     string      `json:"channel"`
 	TemplateIds models.JSON `json:"templateIds"`
+	AckKeywords models.JSON `json:"ackKeywords,omitempty"`
 	TenantID    string      `json:"tenantId"`
 	Status      int         `json:"status"`
+	Version     int         `json:"version"`
 	CreatedAt   string      `json:"createdAt"`
 	UpdatedAt   string      `json:"updatedAt"`
 }
 
+// toTemplateResponseItem converts a template model to its response representation
+func toTemplateResponseItem(template models.Template) TemplateResponseItem {
+	return TemplateResponseItem{
+		UUID:        template.UUID,
+		Code:        template.Code,
+		Name:        template.Name,
+		Subject:     template.Subject,
+		Content:     template.Content,
+		TextContent: template.TextContent,
+		Channel:     string(template.Channel),
+		TemplateIds: template.TemplateIds,
+		AckKeywords: template.AckKeywords,
+		TenantID:    template.TenantID,
+		Status:      template.Status,
+		Version:     template.Version,
+		CreatedAt:   template.CreatedAt.Format(helper.TimeFormat),
+		UpdatedAt:   template.UpdatedAt.Format(helper.TimeFormat),
+	}
+}
+
+// validateAckKeywords checks that every acknowledgement keyword maps to a known action
+func validateAckKeywords(keywords models.JSON) error {
+	for keyword, value := range keywords {
+		action, ok := value.(string)
+		if !ok || strings.TrimSpace(keyword) == "" {
+			return fmt.Errorf("invalid acknowledgement keyword: %s", keyword)
+		}
+		switch strings.ToUpper(action) {
+		case models.AckActionAcknowledge, models.AckActionEscalate:
+		default:
+			return fmt.Errorf("invalid acknowledgement action for keyword %s: %s", keyword, action)
+		}
+	}
+	return nil
+}
+
 // TemplateListParams represents parameters for listing templates
 type TemplateListParams struct {
 	Limit    int    `json:"limit" form:"limit"`
@@ -82,8 +125,8 @@ func NewTemplateAPI(db *gorm.DB, readerDB *gorm.DB) (*TemplateAPI, error) {
 	}, nil
 }
 
-// CreateTemplates creates new templates
-func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateResponse, error) {
+// CreateTemplates creates new templates, recording their content as version 1. author is recorded on the version.
+func (a *TemplateAPI) CreateTemplates(request TemplateRequest, author string) (*TemplateResponse, error) {
 	logger := helper.Log.WithFields(logrus.Fields{
 		"component": "TemplateAPI",
 		"method":    "CreateTemplates",
@@ -106,6 +149,11 @@ func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateRespons
 
 		templateLogger.Debug("Processing template item")
 
+		if err := validateAckKeywords(templateItem.AckKeywords); err != nil {
+			templateLogger.WithError(err).Warn("Invalid acknowledgement keywords")
+			return nil, err
+		}
+
 		// Generate UUID for the template
 		uuid, err := helper.GenerateUUID()
 		if err != nil {
@@ -120,9 +168,12 @@ func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateRespons
 			Name:        templateItem.Name,
 			Subject:     templateItem.Subject,
 			Content:     templateItem.Content,
+			TextContent: templateItem.TextContent,
 			Channel:     models.Channel(templateItem.Channel),
 			TemplateIds: templateItem.TemplateIds,
+			AckKeywords: templateItem.AckKeywords,
 			TenantID:    templateItem.TenantID,
+			Version:     1,
 		}
 
 		// Set status if provided (otherwise DB default will be used)
@@ -142,8 +193,14 @@ func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateRespons
 			return nil, fmt.Errorf("%d:%s", helper.CodeDuplicate, helper.MsgDuplicate)
 		}
 
-		// Save template to database
-		if err := a.DB.Create(&template).Error; err != nil {
+		// Save template to database with its first version
+		err = a.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&template).Error; err != nil {
+				return err
+			}
+			return recordTemplateVersion(tx, template, author, "")
+		})
+		if err != nil {
 			templateLogger.WithError(err).Error("Failed to create template in database")
 			return nil, fmt.Errorf("failed to create template: %v", err)
 		}
@@ -151,19 +208,7 @@ func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateRespons
 		templateLogger.WithField("uuid", template.UUID).Info("Template created successfully")
 
 		// Add to response
-		responseItem := TemplateResponseItem{
-			UUID:        template.UUID,
-			Code:        template.Code,
-			Name:        template.Name,
-			Subject:     template.Subject,
-			Content:     template.Content,
-			Channel:     string(template.Channel),
-			TemplateIds: template.TemplateIds,
-			TenantID:    template.TenantID,
-			Status:      template.Status,
-			CreatedAt:   template.CreatedAt.Format(helper.TimeFormat),
-			UpdatedAt:   template.UpdatedAt.Format(helper.TimeFormat),
-		}
+		responseItem := toTemplateResponseItem(template)
 
 		response.Templates = append(response.Templates, responseItem)
 	}
@@ -172,8 +217,9 @@ func (a *TemplateAPI) CreateTemplates(request TemplateRequest) (*TemplateRespons
 	return response, nil
 }
 
-// UpdateTemplate updates an existing template
-func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest) (*TemplateResponse, error) {
+// UpdateTemplate updates an existing template. A change to the content, subject or provider template IDs
+// is recorded as a new version with author.
+func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest, author string) (*TemplateResponse, error) {
 	logger := helper.Log.WithFields(logrus.Fields{
 		"component": "TemplateAPI",
 		"method":    "UpdateTemplate",
@@ -226,6 +272,10 @@ func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest) (*Tem
 		updates["content"] = templateItem.Content
 	}
 
+	if templateItem.TextContent != "" {
+		updates["text_content"] = templateItem.TextContent
+	}
+
 	// Add subject update if provided
 	if templateItem.Subject != "" {
 		updates["subject"] = templateItem.Subject
@@ -235,6 +285,14 @@ func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest) (*Tem
 		updates["template_ids"] = templateItem.TemplateIds
 	}
 
+	if templateItem.AckKeywords != nil {
+		if err := validateAckKeywords(templateItem.AckKeywords); err != nil {
+			logger.WithError(err).Warn("Invalid acknowledgement keywords")
+			return nil, err
+		}
+		updates["ack_keywords"] = templateItem.AckKeywords
+	}
+
 	if templateItem.Status != nil {
 		updates["status"] = *templateItem.Status
 	}
@@ -265,7 +323,28 @@ func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest) (*Tem
 
 	// Apply updates if there are any
 	if len(updates) > 0 {
-		if err := a.DB.Model(&template).Updates(updates).Error; err != nil {
+		err := a.DB.Transaction(func(tx *gorm.DB) error {
+			// Lock the template so concurrent updates and rollbacks take the next version in turn
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", uuid).First(&template).Error; err != nil {
+				return err
+			}
+			versioned := templateContentChanged(template, updates)
+			if versioned {
+				updates["version"] = template.Version + 1
+			}
+
+			if err := tx.Model(&template).Updates(updates).Error; err != nil {
+				return err
+			}
+			if !versioned {
+				return nil
+			}
+			if err := tx.Where("uuid = ?", uuid).First(&template).Error; err != nil {
+				return err
+			}
+			return recordTemplateVersion(tx, template, author, "")
+		})
+		if err != nil {
 			logger.WithError(err).Error("Failed to update template")
 			return nil, fmt.Errorf("failed to update template: %v", err)
 		}
@@ -280,21 +359,7 @@ func (a *TemplateAPI) UpdateTemplate(uuid string, request TemplateRequest) (*Tem
 
 	// Create response
 	response := &TemplateResponse{
-		Templates: []TemplateResponseItem{
-			{
-				UUID:        template.UUID,
-				Code:        template.Code,
-				Name:        template.Name,
-				Subject:     template.Subject,
-				Content:     template.Content,
-				Channel:     string(template.Channel),
-				TemplateIds: template.TemplateIds,
-				TenantID:    template.TenantID,
-				Status:      template.Status,
-				CreatedAt:   template.CreatedAt.Format(helper.TimeFormat),
-				UpdatedAt:   template.UpdatedAt.Format(helper.TimeFormat),
-			},
-		},
+		Templates: []TemplateResponseItem{toTemplateResponseItem(template)},
 	}
 
 	logger.Info("Template updated successfully")
@@ -320,21 +385,7 @@ func (a *TemplateAPI) GetTemplate(uuid string) (*TemplateResponse, error) {
 
 	// Create response
 	response := &TemplateResponse{
-		Templates: []TemplateResponseItem{
-			{
-				UUID:        template.UUID,
-				Code:        template.Code,
-				Name:        template.Name,
-				Subject:     template.Subject,
-				Content:     template.Content,
-				Channel:     string(template.Channel),
-				TemplateIds: template.TemplateIds,
-				TenantID:    template.TenantID,
-				Status:      template.Status,
-				CreatedAt:   template.CreatedAt.Format(helper.TimeFormat),
-				UpdatedAt:   template.UpdatedAt.Format(helper.TimeFormat),
-			},
-		},
+		Templates: []TemplateResponseItem{toTemplateResponseItem(template)},
 	}
 
 	logger.Info("Template retrieved successfully")
@@ -393,19 +444,7 @@ func (a *TemplateAPI) ListTemplates(params TemplateListParams) (*TemplateRespons
 	}
 
 	for _, template := range templates {
-		responseItem := TemplateResponseItem{
-			UUID:        template.UUID,
-			Code:        template.Code,
-			Name:        template.Name,
-			Subject:     template.Subject,
-			Content:     template.Content,
-			Channel:     string(template.Channel),
-			TemplateIds: template.TemplateIds,
-			TenantID:    template.TenantID,
-			Status:      template.Status,
-			CreatedAt:   template.CreatedAt.Format(helper.TimeFormat),
-			UpdatedAt:   template.UpdatedAt.Format(helper.TimeFormat),
-		}
+		responseItem := toTemplateResponseItem(template)
 		response.Templates = append(response.Templates, responseItem)
 	}
 
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidRenderRecipient is returned when a sample recipient has no address for the channel of the template
+var ErrInvalidRenderRecipient = errors.New("recipient needs an email for EMAIL templates and a telephone for SMS and WHATSAPP templates")
+
+// TemplateRenderRequest is a request to preview a template with sample params
+type TemplateRenderRequest struct {
+	Params    map[string]string        `json:"params"`
+	Subject   string                   `json:"subject,omitempty"`   // Subject override, as on an email send request
+	Version   int                      `json:"version,omitempty"`   // Recorded version to render, the current version when empty
+	Recipient *TemplateRenderRecipient `json:"recipient,omitempty"` // Checked against the suppression list
+}
+
+// TemplateRenderRecipient is a sample recipient of a template preview
+type TemplateRenderRecipient struct {
+	Email     string `json:"email,omitempty"`
+	Telephone string `json:"telephone,omitempty"`
+}
+
+// TemplateRenderRecipientResult tells how the consumers would treat the sample recipient
+type TemplateRenderRecipientResult struct {
+	Address           string `json:"address"` // Normalized the way the consumers store it
+	Suppressed        bool   `json:"suppressed"`
+	SuppressionReason string `json:"suppressionReason,omitempty"`
+}
+
+// TemplateRenderResponse is a template rendered the way the consumers render it. A template that fails
+// to render is not an error of the request: rendered is false and the diagnostics tell why.
+type TemplateRenderResponse struct {
+	TemplateUUID string                            `json:"templateUuid"`
+	Version      int                               `json:"version"`
+	Channel      string                            `json:"channel"`
+	Rendered     bool                              `json:"rendered"`
+	Subject      string                            `json:"subject,omitempty"`     // EMAIL only
+	Content      string                            `json:"content,omitempty"`     // HTML body for EMAIL, message text otherwise
+	TextContent  string                            `json:"textContent,omitempty"` // EMAIL only, plain-text alternative
+	Error        string                            `json:"error,omitempty"`
+	Recipient    *TemplateRenderRecipientResult    `json:"recipient,omitempty"`
+	Diagnostics  map[string]map[string]interface{} `json:"diagnostics"` // Parse and execute results per template field
+}
+
+// RenderTemplate renders a template with sample params exactly as the consumers would, without sending it
+func (a *TemplateAPI) RenderTemplate(uuid string, request TemplateRenderRequest) (*TemplateRenderResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "TemplateAPI",
+		"method":    "RenderTemplate",
+		"uuid":      uuid,
+	})
+
+	template, err := a.findTemplate(a.ReaderDB, uuid)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find template")
+		return nil, err
+	}
+
+	// Render a recorded version with the current settings of the template
+	if request.Version > 0 && request.Version != template.Version {
+		version, err := a.findTemplateVersion(a.ReaderDB, template, request.Version)
+		if err != nil {
+			logger.WithError(err).WithField("version", request.Version).Warn("Failed to find template version")
+			return nil, err
+		}
+		template.Version = version.Version
+		template.Subject = version.Subject
+		template.Content = version.Content
+		template.TextContent = version.TextContent
+		template.TemplateIds = version.TemplateIds
+	}
+
+	response := &TemplateRenderResponse{
+		TemplateUUID: template.UUID,
+		Version:      template.Version,
+		Channel:      string(template.Channel),
+		Diagnostics: map[string]map[string]interface{}{
+			"content": helper.DebugTemplate(template.Content, request.Params),
+		},
+	}
+
+	if request.Recipient != nil {
+		response.Recipient, err = a.checkRenderRecipient(template, request.Recipient)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to check sample recipient")
+			return nil, err
+		}
+	}
+
+	if template.Channel == models.ChannelEmail {
+		if template.Subject != "" {
+			response.Diagnostics["subject"] = helper.DebugTemplate(template.Subject, request.Params)
+		}
+		if template.TextContent != "" {
+			response.Diagnostics["textContent"] = helper.DebugTemplate(template.TextContent, request.Params)
+		}
+
+		message := &models.EmailMessage{
+			Template: template.UUID,
+			Params:   request.Params,
+			Subject:  request.Subject,
+			TenantID: template.TenantID,
+		}
+		rendered, err := services.RenderEmail(template, message, logger)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Rendered = true
+			response.Subject = rendered.Subject
+			response.Content = rendered.HTMLBody
+			response.TextContent = rendered.TextBody
+		}
+	} else {
+		rendered, err := helper.RenderTemplate(template.Content, request.Params)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Rendered = true
+			response.Content = rendered
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"version":  response.Version,
+		"rendered": response.Rendered,
+	}).Info("Template preview completed")
+	return response, nil
+}
+
+// checkRenderRecipient normalizes the address of a sample recipient and looks it up in the suppression list
+func (a *TemplateAPI) checkRenderRecipient(template *models.Template, recipient *TemplateRenderRecipient) (*TemplateRenderRecipientResult, error) {
+	address := recipient.Telephone
+	if template.Channel == models.ChannelEmail {
+		address = recipient.Email
+	}
+	if address == "" {
+		return nil, ErrInvalidRenderRecipient
+	}
+
+	suppressionService, err := services.NewSuppressionService(a.DB, a.ReaderDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create suppression service: %v", err)
+	}
+	suppression, err := suppressionService.IsSuppressed(template.TenantID, template.Channel, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check suppression list: %v", err)
+	}
+
+	result := &TemplateRenderRecipientResult{
+		Address: services.NormalizeAddress(template.Channel, address),
+	}
+	if suppression != nil {
+		result.Suppressed = true
+		result.SuppressionReason = string(suppression.Reason)
+	}
+	return result, nil
+}
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrTemplateNotFound is returned when no template has the given UUID
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrTemplateVersionNotFound is returned when a template has no version with the given number
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+)
+
+// TemplateVersionItem is a recorded version of a template
+type TemplateVersionItem struct {
+	Version     int         `json:"version"`
+	Current     bool        `json:"current"`
+	Subject     string      `json:"subject,omitempty"`
+	Content     string      `json:"content"`
+	TextContent string      `json:"textContent,omitempty"`
+	TemplateIds models.JSON `json:"templateIds"`
+	Author      string      `json:"author,omitempty"`
+	Comment     string      `json:"comment,omitempty"`
+	CreatedAt   string      `json:"createdAt"`
+}
+
+// TemplateVersionsResponse lists the versions of a template, newest first
+type TemplateVersionsResponse struct {
+	TemplateUUID   string                `json:"templateUuid"`
+	CurrentVersion int                   `json:"currentVersion"`
+	Total          int64                 `json:"total"`
+	Versions       []TemplateVersionItem `json:"versions"`
+}
+
+// TemplateVersionResponse is a single version of a template
+type TemplateVersionResponse struct {
+	TemplateUUID string              `json:"templateUuid"`
+	Version      TemplateVersionItem `json:"version"`
+}
+
+// TemplateFieldDiff is the change of one field between two template versions.
+// Text fields are diffed line by line, provider template IDs are given before and after.
+type TemplateFieldDiff struct {
+	Field string            `json:"field"`
+	Lines []helper.DiffLine `json:"lines,omitempty"`
+	From  models.JSON       `json:"from,omitempty"`
+	To    models.JSON       `json:"to,omitempty"`
+}
+
+// TemplateVersionDiffResponse is the difference between two versions of a template
+type TemplateVersionDiffResponse struct {
+	TemplateUUID string              `json:"templateUuid"`
+	From         int                 `json:"from"`
+	To           int                 `json:"to"`
+	Changes      []TemplateFieldDiff `json:"changes"` // Only fields that differ
+}
+
+// toTemplateVersionItem converts a template version model to its response representation
+func toTemplateVersionItem(version models.TemplateVersion, currentVersion int) TemplateVersionItem {
+	return TemplateVersionItem{
+		Version:     version.Version,
+		Current:     version.Version == currentVersion,
+		Subject:     version.Subject,
+		Content:     version.Content,
+		TextContent: version.TextContent,
+		TemplateIds: version.TemplateIds,
+		Author:      version.Author,
+		Comment:     version.Comment,
+		CreatedAt:   version.CreatedAt.Format(helper.TimeFormat),
+	}
+}
+
+// recordTemplateVersion stores the current content of a template as its version template.Version
+func recordTemplateVersion(tx *gorm.DB, template models.Template, author string, comment string) error {
+	version := models.TemplateVersion{
+		TemplateID:   template.ID,
+		TemplateUUID: template.UUID,
+		Version:      template.Version,
+		Subject:      template.Subject,
+		Content:      template.Content,
+		TextContent:  template.TextContent,
+		TemplateIds:  template.TemplateIds,
+		Author:       author,
+		Comment:      comment,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return fmt.Errorf("failed to record template version: %v", err)
+	}
+	return nil
+}
+
+// templateContentChanged reports whether updates change the versioned fields of a template:
+// content, plain-text content, subject or provider template IDs
+func templateContentChanged(template models.Template, updates map[string]interface{}) bool {
+	if content, ok := updates["content"]; ok && content != template.Content {
+		return true
+	}
+	if textContent, ok := updates["text_content"]; ok && textContent != template.TextContent {
+		return true
+	}
+	if subject, ok := updates["subject"]; ok && subject != template.Subject {
+		return true
+	}
+	if templateIds, ok := updates["template_ids"].(models.JSON); ok && !sameJSON(templateIds, template.TemplateIds) {
+		return true
+	}
+	return false
+}
+
+// sameJSON reports whether two JSON values serialize identically, map keys are sorted when marshalled
+func sameJSON(a models.JSON, b models.JSON) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+// findTemplate returns the template with a UUID, or ErrTemplateNotFound
+func (a *TemplateAPI) findTemplate(db *gorm.DB, uuid string) (*models.Template, error) {
+	var template models.Template
+	if err := db.Where("uuid = ?", uuid).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve template: %v", err)
+	}
+	return &template, nil
+}
+
+// findTemplateVersion returns a version of a template, or ErrTemplateVersionNotFound
+func (a *TemplateAPI) findTemplateVersion(db *gorm.DB, template *models.Template, number int) (*models.TemplateVersion, error) {
+	var version models.TemplateVersion
+	if err := db.Where("template_id = ? AND version = ?", template.ID, number).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve template version: %v", err)
+	}
+	return &version, nil
+}
+
+// ListTemplateVersions lists the recorded versions of a template, newest first
+func (a *TemplateAPI) ListTemplateVersions(uuid string, limit int, offset int) (*TemplateVersionsResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "TemplateAPI",
+		"method":    "ListTemplateVersions",
+		"uuid":      uuid,
+	})
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	template, err := a.findTemplate(a.ReaderDB, uuid)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find template")
+		return nil, err
+	}
+
+	query := a.ReaderDB.Model(&models.TemplateVersion{}).Where("template_id = ?", template.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.WithError(err).Error("Failed to count template versions")
+		return nil, fmt.Errorf("failed to count template versions: %v", err)
+	}
+
+	var versions []models.TemplateVersion
+	if err := query.Order("version DESC").Limit(limit).Offset(offset).Find(&versions).Error; err != nil {
+		logger.WithError(err).Error("Failed to retrieve template versions")
+		return nil, fmt.Errorf("failed to retrieve template versions: %v", err)
+	}
+
+	response := &TemplateVersionsResponse{
+		TemplateUUID:   template.UUID,
+		CurrentVersion: template.Version,
+		Total:          total,
+		Versions:       make([]TemplateVersionItem, 0, len(versions)),
+	}
+	for _, version := range versions {
+		response.Versions = append(response.Versions, toTemplateVersionItem(version, template.Version))
+	}
+
+	logger.WithField("count", len(response.Versions)).Info("Template versions listed successfully")
+	return response, nil
+}
+
+// GetTemplateVersion retrieves one version of a template
+func (a *TemplateAPI) GetTemplateVersion(uuid string, number int) (*TemplateVersionResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "TemplateAPI",
+		"method":    "GetTemplateVersion",
+		"uuid":      uuid,
+		"version":   number,
+	})
+
+	template, err := a.findTemplate(a.ReaderDB, uuid)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find template")
+		return nil, err
+	}
+
+	version, err := a.findTemplateVersion(a.ReaderDB, template, number)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find template version")
+		return nil, err
+	}
+
+	return &TemplateVersionResponse{
+		TemplateUUID: template.UUID,
+		Version:      toTemplateVersionItem(*version, template.Version),
+	}, nil
+}
+
+// DiffTemplateVersions compares two versions of a template. A to of 0 compares with the current
+// version and a from of 0 with the version before to.
+func (a *TemplateAPI) DiffTemplateVersions(uuid string, from int, to int) (*TemplateVersionDiffResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "TemplateAPI",
+		"method":    "DiffTemplateVersions",
+		"uuid":      uuid,
+	})
+
+	template, err := a.findTemplate(a.ReaderDB, uuid)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find template")
+		return nil, err
+	}
+
+	if to == 0 {
+		to = template.Version
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	fromVersion, err := a.findTemplateVersion(a.ReaderDB, template, from)
+	if err != nil {
+		logger.WithError(err).WithField("version", from).Warn("Failed to find template version")
+		return nil, err
+	}
+	toVersion, err := a.findTemplateVersion(a.ReaderDB, template, to)
+	if err != nil {
+		logger.WithError(err).WithField("version", to).Warn("Failed to find template version")
+		return nil, err
+	}
+
+	response := &TemplateVersionDiffResponse{
+		TemplateUUID: template.UUID,
+		From:         from,
+		To:           to,
+		Changes:      []TemplateFieldDiff{},
+	}
+	textFields := []struct {
+		name     string
+		from, to string
+	}{
+		{"subject", fromVersion.Subject, toVersion.Subject},
+		{"content", fromVersion.Content, toVersion.Content},
+		{"textContent", fromVersion.TextContent, toVersion.TextContent},
+	}
+	for _, field := range textFields {
+		if field.from != field.to {
+			response.Changes = append(response.Changes, TemplateFieldDiff{
+				Field: field.name,
+				Lines: helper.DiffLines(field.from, field.to),
+			})
+		}
+	}
+	if !sameJSON(fromVersion.TemplateIds, toVersion.TemplateIds) {
+		response.Changes = append(response.Changes, TemplateFieldDiff{
+			Field: "templateIds",
+			From:  fromVersion.TemplateIds,
+			To:    toVersion.TemplateIds,
+		})
+	}
+
+	logger.WithFields(logrus.Fields{
+		"from":    from,
+		"to":      to,
+		"changes": len(response.Changes),
+	}).Info("Template versions compared successfully")
+	return response, nil
+}
+
+// RollbackTemplate restores the content, subject and provider template IDs of an earlier version.
+// The rollback is recorded as a new version, so the history is never rewritten.
+func (a *TemplateAPI) RollbackTemplate(uuid string, number int, author string) (*TemplateResponse, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component": "TemplateAPI",
+		"method":    "RollbackTemplate",
+		"uuid":      uuid,
+		"version":   number,
+	})
+
+	var template *models.Template
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the template so concurrent updates and rollbacks take the next version in turn
+		var err error
+		template, err = a.findTemplate(tx.Clauses(clause.Locking{Strength: "UPDATE"}), uuid)
+		if err != nil {
+			return err
+		}
+		version, err := a.findTemplateVersion(tx, template, number)
+		if err != nil {
+			return err
+		}
+
+		template.Subject = version.Subject
+		template.Content = version.Content
+		template.TextContent = version.TextContent
+		template.TemplateIds = version.TemplateIds
+		template.Version++
+
+		if err := tx.Model(template).Updates(map[string]interface{}{
+			"subject":      template.Subject,
+			"content":      template.Content,
+			"text_content": template.TextContent,
+			"template_ids": template.TemplateIds,
+			"version":      template.Version,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update template: %v", err)
+		}
+		return recordTemplateVersion(tx, *template, author, fmt.Sprintf("Rolled back to version %d", number))
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to roll back template")
+		return nil, err
+	}
+
+	logger.WithField("newVersion", template.Version).Info("Template rolled back successfully")
+	return &TemplateResponse{
+		Templates: []TemplateResponseItem{toTemplateResponseItem(*template)},
+	}, nil
+}
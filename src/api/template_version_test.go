@@ -0,0 +1,85 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// templateRow returns a templates row at a version
+func templateRow(version int, content string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "uuid", "code", "content", "channel", "tenant_id", "version"}).
+		AddRow(1, "template-uuid", "WELCOME", content, "SMS", "tenant", version)
+}
+
+// The next version is computed from the template row locked inside the transaction, so a
+// concurrent change made after the template was first read is not overwritten
+func TestTemplateVersionLocksTemplate(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		call   func(a *TemplateAPI) (*TemplateResponse, error)
+	}{
+		{
+			name: "update",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "templates" WHERE uuid = \$1 ORDER BY "templates"."id" LIMIT \$2$`).
+					WillReturnRows(templateRow(3, "Hello"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "templates" WHERE uuid = \$1 AND "templates"."id" = \$2 ORDER BY "templates"."id" LIMIT \$3 FOR UPDATE`).
+					WillReturnRows(templateRow(4, "Hi"))
+				mock.ExpectExec(`UPDATE "templates" SET "content"=\$1,"version"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+					WithArgs("Hello there", 5, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT \* FROM "templates" WHERE uuid = \$1`).WillReturnRows(templateRow(5, "Hello there"))
+				mock.ExpectQuery(`INSERT INTO "template_versions"`).
+					WithArgs(1, "template-uuid", 5, "", "Hello there", "", nil, "jane", "", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+				mock.ExpectQuery(`SELECT \* FROM "templates" WHERE uuid = \$1`).WillReturnRows(templateRow(5, "Hello there"))
+			},
+			call: func(a *TemplateAPI) (*TemplateResponse, error) {
+				return a.UpdateTemplate("template-uuid", TemplateRequest{Templates: []TemplateRequestItem{{Content: "Hello there"}}}, "jane")
+			},
+		},
+		{
+			name: "rollback",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "templates" WHERE uuid = \$1 ORDER BY "templates"."id" LIMIT \$2 FOR UPDATE`).
+					WillReturnRows(templateRow(4, "Hi"))
+				mock.ExpectQuery(`SELECT \* FROM "template_versions" WHERE template_id = \$1 AND version = \$2`).
+					WithArgs(1, 2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "template_id", "version", "content"}).AddRow(2, 1, 2, "Hello"))
+				mock.ExpectExec(`UPDATE "templates" SET .*"version"=\$5,"updated_at"=\$6 WHERE "id" = \$7`).
+					WithArgs("Hello", "", nil, "", 5, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "template_versions"`).
+					WithArgs(1, "template-uuid", 5, "", "Hello", "", nil, "jane", "Rolled back to version 2", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+			call: func(a *TemplateAPI) (*TemplateResponse, error) {
+				return a.RollbackTemplate("template-uuid", 2, "jane")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			response, err := tt.call(&TemplateAPI{DB: db, ReaderDB: db})
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := response.Templates[0].Version; got != 5 {
+				t.Errorf("version = %d, want 5", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EmailAddress represents an email address with an optional display name
+type EmailAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// EmailOptions holds the optional envelope and tracking settings of an email
+type EmailOptions struct {
+	Cc         []EmailAddress    `json:"cc,omitempty"`
+	Bcc        []EmailAddress    `json:"bcc,omitempty"`
+	ReplyTo    *EmailAddress     `json:"replyTo,omitempty"`
+	FromName   string            `json:"fromName,omitempty"`   // Display name shown with the provider's from address
+	TextBody   string            `json:"textBody,omitempty"`   // Plain-text alternative sent with an HTML body
+	Headers    map[string]string `json:"headers,omitempty"`    // Custom headers added to the email
+	Categories []string          `json:"categories,omitempty"` // Passed to providers that support categories or tags
+	CustomArgs map[string]string `json:"customArgs,omitempty"` // Passed to providers that echo them back in events
+}
+
+// reservedEmailHeaders are set by the service or the provider and cannot be overridden
+var reservedEmailHeaders = map[string]bool{
+	"to": true, "cc": true, "bcc": true, "from": true, "sender": true, "subject": true, "reply-to": true,
+	"date": true, "message-id": true, "return-path": true,
+	"content-type": true, "content-transfer-encoding": true, "mime-version": true,
+	"received": true, "dkim-signature": true, "x-sg-id": true, "x-sg-eid": true,
+}
+
+// headerNamePattern matches an RFC 5322 field name: printable ASCII without a colon or space
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+\\-.^_`|~]+$")
+
+// ValidateHeaderName checks that a custom header name is a valid field name and not a header set by
+// the service or the provider
+func ValidateHeaderName(name string) error {
+	if !headerNamePattern.MatchString(name) || reservedEmailHeaders[strings.ToLower(name)] {
+		return fmt.Errorf("header %q cannot be set", name)
+	}
+	return nil
+}
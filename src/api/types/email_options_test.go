@@ -0,0 +1,36 @@
+package types
+
+import "testing"
+
+func TestValidateHeaderName(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{name: "custom header", header: "X-Campaign-Id"},
+		{name: "list unsubscribe", header: "List-Unsubscribe"},
+		{name: "reserved header", header: "Subject", wantErr: true},
+		{name: "reserved header in lower case", header: "bcc", wantErr: true},
+		{name: "date", header: "Date", wantErr: true},
+		{name: "message ID", header: "Message-ID", wantErr: true},
+		{name: "sender", header: "Sender", wantErr: true},
+		{name: "return path", header: "Return-Path", wantErr: true},
+		{name: "MIME version", header: "MIME-Version", wantErr: true},
+		{name: "DKIM signature", header: "DKIM-Signature", wantErr: true},
+		{name: "line break in name", header: "X-Test\r\nBcc", wantErr: true},
+		{name: "colon in name", header: "X-Test:", wantErr: true},
+		{name: "space in name", header: "X Test", wantErr: true},
+		{name: "non-ASCII name", header: "X-Tést", wantErr: true},
+		{name: "empty name", header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHeaderName(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHeaderName(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+		})
+	}
+}
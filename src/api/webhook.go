@@ -0,0 +1,822 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"delivery/services/providers/sms"
+	"delivery/services/providers/whatsapp"
+	"delivery/services/queue"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidWebhookSignature is returned when a webhook request fails signature verification
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// SendGridEvent represents a single event posted by the SendGrid event webhook
+type SendGridEvent struct {
+	Email       string `json:"email"`
+	Event       string `json:"event"`
+	Reason      string `json:"reason"`
+	Type        string `json:"type"` // bounce or blocked for bounce events
+	Timestamp   int64  `json:"timestamp"`
+	SGMessageID string `json:"sg_message_id"`
+}
+
+// sendGridSuppressionReasons maps SendGrid event names to suppression reasons. Dropped events are not
+// suppressed, SendGrid also drops messages for reasons that say nothing about the address.
+var sendGridSuppressionReasons = map[string]models.SuppressionReason{
+	"bounce":            models.SuppressionReasonBounce,
+	"spamreport":        models.SuppressionReasonSpamReport,
+	"unsubscribe":       models.SuppressionReasonUnsubscribe,
+	"group_unsubscribe": models.SuppressionReasonUnsubscribe,
+}
+
+// sendGridSuppressionReason returns the suppression reason for a SendGrid event, or false when the
+// event does not suppress the address. Only hard bounces suppress, blocked bounces are usually temporary.
+func sendGridSuppressionReason(event SendGridEvent) (models.SuppressionReason, bool) {
+	name := strings.ToLower(event.Event)
+	reason, ok := sendGridSuppressionReasons[name]
+	if !ok || event.Email == "" {
+		return "", false
+	}
+	if name == "bounce" && !strings.EqualFold(event.Type, "bounce") {
+		return "", false
+	}
+	return reason, true
+}
+
+// WebhookAPI handles callbacks posted by providers
+type WebhookAPI struct {
+	DB                 *gorm.DB
+	ReaderDB           *gorm.DB
+	SuppressionService *services.SuppressionService
+	EscalationService  *services.EscalationService
+	SessionService     *services.WhatsAppSessionService
+	StatusService      *services.DeliveryStatusService
+	InboundProducer    *queue.InboundProducer
+}
+
+// NewWebhookAPI creates a new webhook API
+func NewWebhookAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClient) (*WebhookAPI, error) {
+	logger := helper.Log.WithField("component", "WebhookAPI")
+
+	if db == nil {
+		logger.Error("Writer database connection is nil")
+		return nil, fmt.Errorf("writer database connection is nil")
+	}
+	if readerDB == nil {
+		logger.Error("Reader database connection is nil")
+		return nil, fmt.Errorf("reader database connection is nil")
+	}
+	if pulsarClient == nil {
+		logger.Error("Pulsar client is nil")
+		return nil, fmt.Errorf("pulsar client is nil")
+	}
+
+	suppressionService, err := services.NewSuppressionService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create suppression service")
+		return nil, err
+	}
+
+	escalationService, err := services.NewEscalationService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create escalation service")
+		return nil, err
+	}
+
+	sessionService, err := services.NewWhatsAppSessionService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create WhatsApp session service")
+		return nil, err
+	}
+
+	statusService, err := services.NewDeliveryStatusService(db, readerDB)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create delivery status service")
+		return nil, err
+	}
+
+	logger.Info("Webhook API initialized successfully")
+	return &WebhookAPI{
+		DB:                 db,
+		ReaderDB:           readerDB,
+		SuppressionService: suppressionService,
+		EscalationService:  escalationService,
+		SessionService:     sessionService,
+		StatusService:      statusService,
+		InboundProducer:    queue.NewInboundProducer(pulsarClient),
+	}, nil
+}
+
+// fetchWebhookProvider gets an active provider for a webhook by UUID and channel
+func (a *WebhookAPI) fetchWebhookProvider(providerUUID string, channel models.Channel) (*models.Provider, error) {
+	var provider models.Provider
+	if err := a.ReaderDB.Where("uuid = ? AND channel = ?", providerUUID, channel).
+		Where("status = 1").
+		First(&provider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("provider not found")
+		}
+		return nil, fmt.Errorf("failed to fetch provider: %v", err)
+	}
+	return &provider, nil
+}
+
+// ProcessSendGridEvents handles a batch of SendGrid events, suppressing addresses that bounced,
+// reported spam or unsubscribed
+func (a *WebhookAPI) ProcessSendGridEvents(providerUUID string, body []byte, signature string, timestamp string) (int, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component":     "WebhookAPI",
+		"method":        "ProcessSendGridEvents",
+		"provider_uuid": providerUUID,
+	})
+
+	provider, err := a.fetchWebhookProvider(providerUUID, models.ChannelEmail)
+	if err != nil {
+		logger.WithError(err).Warn("SendGrid webhook for unknown provider")
+		return 0, err
+	}
+
+	// Events write to the suppression list, so they are only accepted with a valid signature
+	publicKey, _ := provider.Config["webhookVerificationKey"].(string)
+	if publicKey == "" {
+		logger.Warn("SendGrid webhook rejected, provider has no webhookVerificationKey configured")
+		return 0, ErrInvalidWebhookSignature
+	}
+	if err := verifySendGridSignature(publicKey, signature, timestamp, body); err != nil {
+		logger.WithError(err).Warn("SendGrid webhook signature verification failed")
+		return 0, ErrInvalidWebhookSignature
+	}
+
+	var events []SendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		logger.WithError(err).Warn("Failed to parse SendGrid events")
+		return 0, fmt.Errorf("invalid event payload: %v", err)
+	}
+
+	suppressed := 0
+	for _, event := range events {
+		reason, ok := sendGridSuppressionReason(event)
+		if !ok {
+			continue
+		}
+
+		suppression := models.Suppression{
+			TenantID: provider.TenantID,
+			Channel:  models.ChannelEmail,
+			Address:  event.Email,
+			Reason:   reason,
+			Source:   models.SuppressionSourceSendGrid,
+			Details:  strings.TrimSpace(event.Event + " " + event.Reason),
+		}
+		if err := a.SuppressionService.Suppress(&suppression); err != nil {
+			logger.WithError(err).WithField("event", event.Event).Error("Failed to suppress address from SendGrid event")
+			return suppressed, err
+		}
+		suppressed++
+	}
+
+	logger.WithFields(logrus.Fields{
+		"events":     len(events),
+		"suppressed": suppressed,
+	}).Info("Processed SendGrid events")
+	return suppressed, nil
+}
+
+// verifySendGridSignature verifies the ECDSA signature of a signed SendGrid event webhook
+func verifySendGridSignature(publicKey string, signature string, timestamp string, body []byte) error {
+	if signature == "" || timestamp == "" {
+		return errors.New("missing signature headers")
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode verification key: %w", err)
+	}
+	parsedKey, err := x509.ParsePKIXPublicKey(keyBytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse verification key: %w", err)
+	}
+	ecdsaKey, ok := parsedKey.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("verification key is not an ECDSA public key")
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(ecdsaKey, digest[:], signatureBytes) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// ProcessTwilioInbound handles an inbound SMS or WhatsApp message posted by Twilio
+func (a *WebhookAPI) ProcessTwilioInbound(providerUUID string, requestURL string, form url.Values, signature string) (*models.InboundMessage, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component":     "WebhookAPI",
+		"method":        "ProcessTwilioInbound",
+		"provider_uuid": providerUUID,
+	})
+
+	var provider models.Provider
+	if err := a.ReaderDB.Where("uuid = ? AND channel IN ?", providerUUID, []models.Channel{models.ChannelSMS, models.ChannelWhatsApp}).
+		Where("status = 1").
+		First(&provider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Twilio inbound webhook for unknown provider")
+			return nil, errors.New("provider not found")
+		}
+		return nil, fmt.Errorf("failed to fetch provider: %v", err)
+	}
+
+	authToken, err := twilioAuthToken(&provider)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load Twilio credentials for signature validation")
+		return nil, err
+	}
+	if !verifyTwilioSignature(authToken, requestURL, form, signature) {
+		logger.Warn("Twilio inbound webhook signature verification failed")
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	// Collect media attachments
+	media := []models.InboundMedia{}
+	numMedia, _ := strconv.Atoi(form.Get("NumMedia"))
+	for i := 0; i < numMedia; i++ {
+		media = append(media, models.InboundMedia{
+			URL:         form.Get(fmt.Sprintf("MediaUrl%d", i)),
+			ContentType: form.Get(fmt.Sprintf("MediaContentType%d", i)),
+		})
+	}
+
+	// Keep the raw fields so nothing Twilio sends is lost
+	metadata := models.JSON{}
+	for key := range form {
+		metadata[key] = form.Get(key)
+	}
+
+	inbound := models.InboundMessage{
+		From:              form.Get("From"),
+		To:                form.Get("To"),
+		Body:              form.Get("Body"),
+		ProviderMessageID: form.Get("MessageSid"),
+		Metadata:          metadata,
+	}
+
+	return a.recordInbound(&provider, &inbound, media)
+}
+
+// ProcessSMPPInbound handles a mobile originated message received on the bind of an SMPP provider,
+// the same way as an inbound webhook
+func (a *WebhookAPI) ProcessSMPPInbound(message sms.InboundMessage) (*models.InboundMessage, error) {
+	provider, err := a.fetchWebhookProvider(message.ProviderUUID, models.ChannelSMS)
+	if err != nil {
+		return nil, err
+	}
+
+	inbound := models.InboundMessage{
+		From:       message.From,
+		To:         message.To,
+		Body:       message.Body,
+		ReceivedAt: message.ReceivedAt,
+		Metadata:   models.JSON{},
+	}
+
+	return a.recordInbound(provider, &inbound, []models.InboundMedia{})
+}
+
+// metaStatuses maps WhatsApp Cloud API statuses to message events, "sent" is already recorded on send
+var metaStatuses = map[string]models.MessageEventType{
+	"delivered": models.EventStatusDelivered,
+	"read":      models.EventStatusRead,
+	"failed":    models.EventStatusFailed,
+}
+
+// MetaWebhookPayload represents a notification posted by the WhatsApp Cloud API webhook
+type MetaWebhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Changes []struct {
+			Field string           `json:"field"`
+			Value MetaWebhookValue `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// MetaWebhookValue holds the statuses and messages of a WhatsApp Cloud API webhook change
+type MetaWebhookValue struct {
+	Metadata struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+		PhoneNumberID      string `json:"phone_number_id"`
+	} `json:"metadata"`
+	Statuses []struct {
+		ID          string `json:"id"`
+		Status      string `json:"status"`
+		Timestamp   string `json:"timestamp"`
+		RecipientID string `json:"recipient_id"`
+		Errors      []struct {
+			Code    int    `json:"code"`
+			Title   string `json:"title"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"statuses"`
+	Messages []struct {
+		ID        string `json:"id"`
+		From      string `json:"from"`
+		Timestamp string `json:"timestamp"`
+		Type      string `json:"type"`
+		Text      struct {
+			Body string `json:"body"`
+		} `json:"text"`
+		Button struct {
+			Text string `json:"text"`
+		} `json:"button"`
+		Image    *MetaWebhookMedia `json:"image"`
+		Video    *MetaWebhookMedia `json:"video"`
+		Audio    *MetaWebhookMedia `json:"audio"`
+		Document *MetaWebhookMedia `json:"document"`
+		Sticker  *MetaWebhookMedia `json:"sticker"`
+	} `json:"messages"`
+}
+
+// MetaWebhookMedia is a media attachment of an inbound WhatsApp Cloud API message
+type MetaWebhookMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption"`
+}
+
+// VerifyMetaWebhook answers the verification request Meta sends when the webhook is registered,
+// returning the challenge when the verify token matches the provider configuration
+func (a *WebhookAPI) VerifyMetaWebhook(providerUUID string, mode string, token string, challenge string) (string, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component":     "WebhookAPI",
+		"method":        "VerifyMetaWebhook",
+		"provider_uuid": providerUUID,
+	})
+
+	provider, err := a.fetchWebhookProvider(providerUUID, models.ChannelWhatsApp)
+	if err != nil {
+		logger.WithError(err).Warn("Meta webhook verification for unknown provider")
+		return "", err
+	}
+
+	metaProvider, err := metaWebhookProvider(provider)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load Meta provider for webhook verification")
+		return "", err
+	}
+
+	if mode != "subscribe" || metaProvider.VerifyToken == "" ||
+		!hmac.Equal([]byte(token), []byte(metaProvider.VerifyToken)) {
+		logger.Warn("Meta webhook verification token mismatch")
+		return "", ErrInvalidWebhookSignature
+	}
+
+	logger.Info("Meta webhook verified")
+	return challenge, nil
+}
+
+// ProcessMetaWebhook handles statuses and inbound messages posted by the WhatsApp Cloud API,
+// returning the number of statuses recorded and inbound messages stored
+func (a *WebhookAPI) ProcessMetaWebhook(providerUUID string, body []byte, signature string) (int, int, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component":     "WebhookAPI",
+		"method":        "ProcessMetaWebhook",
+		"provider_uuid": providerUUID,
+	})
+
+	provider, err := a.fetchWebhookProvider(providerUUID, models.ChannelWhatsApp)
+	if err != nil {
+		logger.WithError(err).Warn("Meta webhook for unknown provider")
+		return 0, 0, err
+	}
+
+	metaProvider, err := metaWebhookProvider(provider)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load Meta provider for signature validation")
+		return 0, 0, err
+	}
+	if !metaProvider.VerifySignature(body, signature) {
+		logger.Warn("Meta webhook signature verification failed")
+		return 0, 0, ErrInvalidWebhookSignature
+	}
+
+	var payload MetaWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		logger.WithError(err).Warn("Failed to parse Meta webhook payload")
+		return 0, 0, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+
+	statuses, received := 0, 0
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			value := change.Value
+
+			for _, status := range value.Statuses {
+				eventStatus, ok := metaStatuses[status.Status]
+				if !ok {
+					continue
+				}
+
+				update := services.DeliveryStatusUpdate{
+					ProviderUUID:      provider.UUID,
+					ProviderMessageID: status.ID,
+					Status:            eventStatus,
+					Metadata:          models.JSON{"source": "meta", "metaStatus": status.Status},
+					Timestamp:         metaTimestamp(status.Timestamp),
+				}
+				if len(status.Errors) > 0 {
+					update.Reason = strings.TrimSpace(status.Errors[0].Title + " " + status.Errors[0].Message)
+					update.Metadata["errorCode"] = status.Errors[0].Code
+				}
+
+				if _, err := a.StatusService.Record(update); err != nil {
+					if errors.Is(err, services.ErrUnknownProviderMessage) {
+						// Messages sent outside this service report statuses to the same webhook
+						logger.WithField("message_id", status.ID).Debug("Ignoring status for unknown Meta message")
+						continue
+					}
+					logger.WithError(err).WithField("message_id", status.ID).Error("Failed to record Meta status")
+					return statuses, received, err
+				}
+				statuses++
+			}
+
+			for _, message := range value.Messages {
+				inbound := models.InboundMessage{
+					From:              "+" + strings.TrimPrefix(message.From, "+"),
+					To:                "+" + strings.TrimPrefix(value.Metadata.DisplayPhoneNumber, "+"),
+					ProviderMessageID: message.ID,
+					ReceivedAt:        metaTimestamp(message.Timestamp),
+					Metadata: models.JSON{
+						"type":          message.Type,
+						"phoneNumberId": value.Metadata.PhoneNumberID,
+					},
+				}
+
+				media := []models.InboundMedia{}
+				switch message.Type {
+				case "text":
+					inbound.Body = message.Text.Body
+				case "button":
+					inbound.Body = message.Button.Text
+				}
+				for _, attachment := range []*MetaWebhookMedia{message.Image, message.Video, message.Audio, message.Document, message.Sticker} {
+					if attachment == nil {
+						continue
+					}
+					if inbound.Body == "" {
+						inbound.Body = attachment.Caption
+					}
+					media = append(media, models.InboundMedia{
+						URL:         metaProvider.MediaURL(attachment.ID),
+						ContentType: attachment.MimeType,
+					})
+				}
+
+				if _, err := a.recordInbound(provider, &inbound, media); err != nil {
+					logger.WithError(err).WithField("message_id", message.ID).Error("Failed to record Meta inbound message")
+					return statuses, received, err
+				}
+				received++
+			}
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"statuses": statuses,
+		"received": received,
+	}).Info("Processed Meta webhook")
+	return statuses, received, nil
+}
+
+// recordInbound stores an inbound message received by a provider, correlates it with the most recent
+// outbound message to the sender, applies opt-out and acknowledgement keywords and forwards it to the
+// inbound topic. A message already stored under its provider message ID is returned without side effects.
+func (a *WebhookAPI) recordInbound(provider *models.Provider, inbound *models.InboundMessage, media []models.InboundMedia) (*models.InboundMessage, error) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"component":     "WebhookAPI",
+		"method":        "recordInbound",
+		"provider_uuid": provider.UUID,
+		"channel":       provider.Channel,
+	})
+
+	inbound.From = services.NormalizeAddress(provider.Channel, inbound.From)
+	inbound.To = services.NormalizeAddress(provider.Channel, inbound.To)
+	if inbound.From == "" {
+		return nil, errors.New("missing sender address")
+	}
+
+	inboundUUID, err := helper.GenerateUUID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID for inbound message: %v", err)
+	}
+	inbound.UUID = inboundUUID
+	inbound.TenantID = provider.TenantID
+	inbound.ProviderUUID = provider.UUID
+	inbound.Channel = provider.Channel
+	if inbound.ReceivedAt.IsZero() {
+		inbound.ReceivedAt = time.Now().UTC()
+	}
+
+	// Convert media array to JSON
+	mediaJSON := models.JSON{}
+	for i, item := range media {
+		mediaJSON[fmt.Sprintf("%d", i)] = map[string]interface{}{
+			"url":         item.URL,
+			"contentType": item.ContentType,
+		}
+	}
+	inbound.Media = mediaJSON
+
+	// Correlate with the most recent outbound message to this address
+	var outbound *models.Message
+	var recipient models.MessageRecipient
+	if err := a.ReaderDB.Where("tenant_id = ? AND channel = ? AND address = ?", inbound.TenantID, inbound.Channel, inbound.From).
+		Order("id DESC").
+		First(&recipient).Error; err == nil {
+		inbound.MessageUUID = recipient.MessageUUID
+
+		var message models.Message
+		if err := a.ReaderDB.Where("id = ?", recipient.MessageID).First(&message).Error; err == nil {
+			outbound = &message
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.WithError(err).Warn("Failed to correlate inbound message")
+	}
+
+	inbound.AckAction = a.matchAckKeyword(outbound, inbound.Body)
+
+	// Providers retry webhooks, a message already stored under its provider message ID is not acted on again
+	result := a.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(inbound)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to store inbound message")
+		return nil, fmt.Errorf("failed to store inbound message: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if inbound.ProviderMessageID == "" {
+			return nil, errors.New("failed to store inbound message: conflicting UUID")
+		}
+		var existing models.InboundMessage
+		if err := a.DB.Where("provider_uuid = ? AND provider_message_id = ?", inbound.ProviderUUID, inbound.ProviderMessageID).
+			First(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch stored inbound message: %v", err)
+		}
+		logger.WithField("provider_message_id", inbound.ProviderMessageID).Info("Duplicate inbound message ignored")
+		return &existing, nil
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"inbound_uuid": inbound.UUID,
+		"message_uuid": inbound.MessageUUID,
+		"ack_action":   inbound.AckAction,
+	})
+	logger.Info("Stored inbound message")
+
+	// Any inbound WhatsApp message opens the 24-hour window for free-form replies
+	if inbound.Channel == models.ChannelWhatsApp {
+		if err := a.SessionService.Touch(inbound.TenantID, inbound.ProviderUUID, inbound.From, inbound.ReceivedAt); err != nil {
+			logger.WithError(err).Error("Failed to update WhatsApp session")
+		}
+	}
+
+	a.handleOptKeywords(inbound)
+	if inbound.AckAction != "" {
+		a.recordAcknowledgement(outbound, inbound)
+	}
+
+	payload := &models.InboundMessagePayload{
+		UUID:         inbound.UUID,
+		TenantID:     inbound.TenantID,
+		ProviderUUID: inbound.ProviderUUID,
+		Channel:      inbound.Channel,
+		From:         inbound.From,
+		To:           inbound.To,
+		Body:         inbound.Body,
+		Media:        media,
+		MessageUUID:  inbound.MessageUUID,
+		AckAction:    inbound.AckAction,
+		ReceivedAt:   inbound.ReceivedAt,
+	}
+	if outbound != nil {
+		payload.RefNo = outbound.RefNo
+		payload.Identifiers = outbound.Identifiers
+	}
+
+	// The message is already stored, so a queue failure is logged rather than failing the webhook
+	if err := a.InboundProducer.ProduceInboundMessage(payload); err != nil {
+		logger.WithError(err).Error("Failed to forward inbound message to queue")
+	}
+
+	return inbound, nil
+}
+
+// matchAckKeyword returns the acknowledgement action configured on the outbound message's template
+// for a reply, or an empty string when the reply is not an acknowledgement keyword
+func (a *WebhookAPI) matchAckKeyword(outbound *models.Message, body string) string {
+	if outbound == nil || outbound.TemplateUUID == "" {
+		return ""
+	}
+
+	// Opt-out keywords always win over template keywords
+	if services.IsOptOutKeyword(body) {
+		return ""
+	}
+
+	var template models.Template
+	if err := a.ReaderDB.Where("uuid = ?", outbound.TemplateUUID).First(&template).Error; err != nil {
+		return ""
+	}
+
+	keyword := strings.ToUpper(strings.TrimSpace(body))
+	for key, value := range template.AckKeywords {
+		if strings.ToUpper(strings.TrimSpace(key)) != keyword {
+			continue
+		}
+		action, _ := value.(string)
+		return strings.ToUpper(action)
+	}
+	return ""
+}
+
+// recordAcknowledgement records an ACKNOWLEDGED or ESCALATED event on the original message
+func (a *WebhookAPI) recordAcknowledgement(outbound *models.Message, inbound *models.InboundMessage) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"inbound_uuid": inbound.UUID,
+		"message_uuid": outbound.UUID,
+		"ack_action":   inbound.AckAction,
+	})
+
+	status := models.EventStatusAcknowledged
+	reason := fmt.Sprintf("Acknowledged by %s", inbound.From)
+	if inbound.AckAction == models.AckActionEscalate {
+		status = models.EventStatusEscalated
+		reason = fmt.Sprintf("Escalation requested by %s", inbound.From)
+	}
+
+	event := models.MessageEvent{
+		MessageID: outbound.ID,
+		Status:    status,
+		Reason:    reason,
+		Metadata: models.JSON{
+			"from":        inbound.From,
+			"channel":     string(inbound.Channel),
+			"keyword":     strings.TrimSpace(inbound.Body),
+			"inboundUuid": inbound.UUID,
+		},
+		Timestamp: inbound.ReceivedAt,
+	}
+	if err := helper.InsertMessageEvent(a.DB, event); err != nil {
+		logger.WithError(err).Error("Failed to record acknowledgement event")
+		return
+	}
+
+	if status == models.EventStatusAcknowledged {
+		if err := a.DB.Model(outbound).Update("status", models.StatusAcknowledged).Error; err != nil {
+			logger.WithError(err).Error("Failed to update message status to ACKNOWLEDGED")
+		}
+	}
+
+	// Stop or advance the escalation chain that sent the message, if any
+	if outbound.NotificationUUID != "" {
+		if err := a.EscalationService.HandleReply(outbound.NotificationUUID, inbound.AckAction, inbound.From); err != nil {
+			logger.WithError(err).Error("Failed to apply reply to escalation")
+		}
+	}
+
+	logger.Info("Recorded reply acknowledgement")
+}
+
+// handleOptKeywords suppresses or releases the sender when an inbound message is an opt-out or opt-in keyword
+func (a *WebhookAPI) handleOptKeywords(inbound *models.InboundMessage) {
+	logger := helper.Log.WithFields(logrus.Fields{
+		"inbound_uuid": inbound.UUID,
+		"channel":      inbound.Channel,
+	})
+
+	switch {
+	case services.IsOptOutKeyword(inbound.Body):
+		suppression := models.Suppression{
+			TenantID: inbound.TenantID,
+			Channel:  inbound.Channel,
+			Address:  inbound.From,
+			Reason:   models.SuppressionReasonOptOut,
+			Source:   models.SuppressionSourceInbound,
+			Details:  "Inbound keyword: " + strings.ToUpper(strings.TrimSpace(inbound.Body)),
+		}
+		if err := a.SuppressionService.Suppress(&suppression); err != nil {
+			logger.WithError(err).Error("Failed to suppress sender after opt-out keyword")
+		}
+
+	case services.IsOptInKeyword(inbound.Body):
+		// Only lift opt-outs, bounces and manual entries must stay in place
+		suppression, err := a.SuppressionService.IsSuppressed(inbound.TenantID, inbound.Channel, inbound.From)
+		if err != nil {
+			logger.WithError(err).Error("Failed to check suppression list after opt-in keyword")
+			return
+		}
+		if suppression != nil && suppression.Reason == models.SuppressionReasonOptOut {
+			if err := a.SuppressionService.Unsuppress(inbound.TenantID, inbound.Channel, inbound.From); err != nil {
+				logger.WithError(err).Error("Failed to release sender after opt-in keyword")
+			}
+		}
+	}
+}
+
+// twilioAuthToken loads the Twilio auth token of a provider, used to validate webhook signatures
+func twilioAuthToken(provider *models.Provider) (string, error) {
+	if strings.ToUpper(provider.Provider) != "TWILIO" {
+		return "", fmt.Errorf("provider %s is not a Twilio provider", provider.UUID)
+	}
+
+	switch provider.Channel {
+	case models.ChannelSMS:
+		twilioProvider, err := sms.NewTwilioProviderFromDB(provider)
+		if err != nil {
+			return "", err
+		}
+		return twilioProvider.AuthToken, nil
+	case models.ChannelWhatsApp:
+		twilioProvider, err := whatsapp.NewTwilioProviderFromDB(provider)
+		if err != nil {
+			return "", err
+		}
+		return twilioProvider.AuthToken, nil
+	}
+
+	return "", fmt.Errorf("unsupported channel for Twilio webhook: %s", provider.Channel)
+}
+
+// metaWebhookProvider loads the Meta provider used to validate webhook requests
+func metaWebhookProvider(provider *models.Provider) (*whatsapp.MetaProvider, error) {
+	if strings.ToUpper(provider.Provider) != "META" {
+		return nil, fmt.Errorf("provider %s is not a Meta provider", provider.UUID)
+	}
+	return whatsapp.NewMetaProviderFromDB(provider)
+}
+
+// metaTimestamp parses the unix timestamps used by the WhatsApp Cloud API
+func metaTimestamp(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// verifyTwilioSignature validates the X-Twilio-Signature header of a form-encoded webhook request
+func verifyTwilioSignature(authToken string, requestURL string, form url.Values, signature string) bool {
+	if signature == "" {
+		return false
+	}
+
+	// Twilio signs the full URL followed by every POST parameter sorted by name
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data strings.Builder
+	data.WriteString(requestURL)
+	for _, key := range keys {
+		for _, value := range form[key] {
+			data.WriteString(key)
+			data.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(data.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"delivery/models"
+	"testing"
+)
+
+func TestSendGridSuppressionReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      SendGridEvent
+		wantReason models.SuppressionReason
+		wantOK     bool
+	}{
+		{name: "hard bounce", event: SendGridEvent{Email: "jane@example.com", Event: "bounce", Type: "bounce"}, wantReason: models.SuppressionReasonBounce, wantOK: true},
+		{name: "blocked bounce", event: SendGridEvent{Email: "jane@example.com", Event: "bounce", Type: "blocked"}},
+		{name: "bounce without type", event: SendGridEvent{Email: "jane@example.com", Event: "bounce"}},
+		{name: "dropped", event: SendGridEvent{Email: "jane@example.com", Event: "dropped", Reason: "Invalid SMTPAPI header"}},
+		{name: "spam report", event: SendGridEvent{Email: "jane@example.com", Event: "spamreport"}, wantReason: models.SuppressionReasonSpamReport, wantOK: true},
+		{name: "unsubscribe", event: SendGridEvent{Email: "jane@example.com", Event: "unsubscribe"}, wantReason: models.SuppressionReasonUnsubscribe, wantOK: true},
+		{name: "group unsubscribe", event: SendGridEvent{Email: "jane@example.com", Event: "group_unsubscribe"}, wantReason: models.SuppressionReasonUnsubscribe, wantOK: true},
+		{name: "delivered", event: SendGridEvent{Email: "jane@example.com", Event: "delivered"}},
+		{name: "missing email", event: SendGridEvent{Event: "spamreport"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := sendGridSuppressionReason(tt.event)
+			if reason != tt.wantReason || ok != tt.wantOK {
+				t.Errorf("sendGridSuppressionReason() = %q, %v, want %q, %v", reason, ok, tt.wantReason, tt.wantOK)
+			}
+		})
+	}
+}
@@ -3,8 +3,12 @@ package api
 import (
 	"delivery/helper"
 	"delivery/models"
+	"delivery/services"
 	"delivery/services/queue"
+	"delivery/services/storage"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,7 +20,9 @@ type WhatsAppRequest struct {
 
 // WhatsAppMessage represents a single WhatsApp message
 type WhatsAppMessage struct {
-	Template    string                 `json:"template" validate:"required"`
+	Template    string                 `json:"template"`        // Required unless text or media is given
+	Text        string                 `json:"text,omitempty"`  // Free-form text, only sent inside an open session
+	Media       *WhatsAppMedia         `json:"media,omitempty"` // Free-form media, only sent inside an open session
 	To          []WhatsAppRecipient    `json:"to" validate:"required,min=1"`
 	Provider    string                 `json:"provider" validate:"required,uuid4"`
 	RefNo       string                 `json:"refno" validate:"required"`
@@ -31,6 +37,7 @@ type WhatsAppMessage struct {
 func (w *WhatsAppMessage) ToModelWhatsAppMessage() *models.WhatsAppMessage {
 	modelMessage := &models.WhatsAppMessage{
 		Template:    w.Template,
+		Text:        w.Text,
 		Provider:    w.Provider,
 		RefNo:       w.RefNo,
 		Categories:  w.Categories,
@@ -48,6 +55,14 @@ func (w *WhatsAppMessage) ToModelWhatsAppMessage() *models.WhatsAppMessage {
 		}
 	}
 
+	if w.Media != nil {
+		modelMessage.Media = &models.WhatsAppMedia{
+			URL:     w.Media.URL,
+			Type:    w.Media.Type,
+			Caption: w.Media.Caption,
+		}
+	}
+
 	// Convert attachments if present
 	if w.Attachments != nil {
 		modelAttachments := &models.WhatsAppAttachments{
@@ -58,6 +73,7 @@ func (w *WhatsAppMessage) ToModelWhatsAppMessage() *models.WhatsAppMessage {
 				Filename:  attachment.Filename,
 				Type:      attachment.Type,
 				Content:   attachment.Content,
+				URL:       attachment.URL,
 				ContentID: attachment.ContentID,
 			}
 		}
@@ -73,6 +89,13 @@ type WhatsAppRecipient struct {
 	Telephone string `json:"telephone" validate:"required,e164"`
 }
 
+// WhatsAppMedia represents free-form media for a WhatsApp message
+type WhatsAppMedia struct {
+	URL     string `json:"url"`
+	Type    string `json:"type"` // MIME type, e.g. image/jpeg
+	Caption string `json:"caption"`
+}
+
 // WhatsAppAttachments represents attachments for a WhatsApp message
 type WhatsAppAttachments struct {
 	Inline []WhatsAppInlineAttachment `json:"inline"`
@@ -82,7 +105,8 @@ type WhatsAppAttachments struct {
 type WhatsAppInlineAttachment struct {
 	Filename  string `json:"filename" validate:"required"`
 	Type      string `json:"type" validate:"required"`
-	Content   string `json:"content" validate:"required"`
+	Content   string `json:"content"` // base64 encoded, required unless url is given
+	URL       string `json:"url,omitempty"`
 	ContentID string `json:"contentId" validate:"required"`
 }
 
@@ -110,6 +134,8 @@ type WhatsAppAPI struct {
 	DB              *gorm.DB
 	ReaderDB        *gorm.DB
 	MessageProducer *queue.WhatsAppProducer
+	SessionService  *services.WhatsAppSessionService
+	Store           storage.Store
 }
 
 // NewWhatsAppAPI creates a new WhatsApp API
@@ -129,6 +155,18 @@ func NewWhatsAppAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarCl
 		return nil, errors.New("pulsar client is nil")
 	}
 
+	sessionService, err := services.NewWhatsAppSessionService(db, readerDB)
+	if err != nil {
+		helper.Log.WithError(err).Error("Failed to initialize WhatsApp API: could not create session service")
+		return nil, err
+	}
+
+	store, err := storage.NewStoreFromEnv()
+	if err != nil {
+		helper.Log.WithError(err).Error("Failed to initialize WhatsApp API: could not create attachment store")
+		return nil, err
+	}
+
 	producer := queue.NewWhatsAppProducer(pulsarClient, db)
 	helper.Log.Info("WhatsApp API initialized successfully")
 
@@ -136,9 +174,42 @@ func NewWhatsAppAPI(db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarCl
 		DB:              db,
 		ReaderDB:        readerDB,
 		MessageProducer: producer,
+		SessionService:  sessionService,
+		Store:           store,
 	}, nil
 }
 
+// validateContent checks that a message can be delivered to every recipient. Free-form text,
+// media or attachments without a template is only accepted when every recipient has an open session; with a
+// template the consumer switches to it for recipients whose session is closed.
+func (a *WhatsAppAPI) validateContent(message WhatsAppMessage) error {
+	hasAttachments := message.Attachments != nil && len(message.Attachments.Inline) > 0
+	if message.Template == "" && message.Text == "" && message.Media == nil && !hasAttachments {
+		return errors.New("template, text or media is required")
+	}
+	if message.Media != nil && (message.Media.URL == "" || message.Media.Type == "") {
+		return errors.New("media url and type are required")
+	}
+	if err := validateWhatsAppAttachments(message.Attachments); err != nil {
+		return err
+	}
+	if message.Template != "" {
+		return nil
+	}
+
+	now := time.Now()
+	for _, recipient := range message.To {
+		session, err := a.SessionService.OpenSession(message.TenantID, message.Provider, recipient.Telephone, now)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return fmt.Errorf("no open WhatsApp session for %s, a template is required", recipient.Telephone)
+		}
+	}
+	return nil
+}
+
 // ProcessMessageBatch processes a batch of WhatsApp messages
 func (a *WhatsAppAPI) ProcessMessageBatch(request WhatsAppRequest) ([]WhatsAppMessageResponse, error) {
 	batchLogger := helper.Log.WithFields(map[string]interface{}{
@@ -148,6 +219,14 @@ func (a *WhatsAppAPI) ProcessMessageBatch(request WhatsAppRequest) ([]WhatsAppMe
 	batchLogger.Info("Starting to process WhatsApp message batch")
 	responses := []WhatsAppMessageResponse{}
 
+	// Validate the whole batch before anything is queued
+	for idx, message := range request.Messages {
+		if err := a.validateContent(message); err != nil {
+			batchLogger.WithError(err).WithField("messageIndex", idx).Warn("Rejected WhatsApp message")
+			return nil, fmt.Errorf("message %d: %v", idx, err)
+		}
+	}
+
 	for idx, message := range request.Messages {
 		messageLogger := batchLogger.WithFields(map[string]interface{}{
 			"messageIndex": idx,
@@ -182,6 +261,12 @@ func (a *WhatsAppAPI) ProcessMessageBatch(request WhatsAppRequest) ([]WhatsAppMe
 		// Convert to model message
 		modelMessage := message.ToModelWhatsAppMessage()
 
+		// Attachment bytes go to the blob store, the queued message only references them
+		if err := offloadWhatsAppAttachments(a.Store, messageUUID, modelMessage.Attachments); err != nil {
+			messageLogger.WithError(err).Error("Failed to store WhatsApp attachments")
+			return nil, errors.New("failed to process message: " + err.Error())
+		}
+
 		// Send to queue
 		messageLogger.Debug("Sending WhatsApp message to queue")
 		if err := a.MessageProducer.ProduceWhatsAppMessage(modelMessage, messageUUID); err != nil {
@@ -220,6 +305,11 @@ func (a *WhatsAppAPI) DirectPushWhatsAppMessage(modelMessage *models.WhatsAppMes
 	// Log with UUID
 	logger = logger.WithField("uuid", directPushMessage.UUID)
 
+	if err := offloadWhatsAppAttachments(a.Store, directPushMessage.UUID, directPushMessage.Message.Attachments); err != nil {
+		logger.WithError(err).Error("Failed to store WhatsApp attachments")
+		return "", err
+	}
+
 	// Push the message directly to Pulsar
 	logger.Debug("Pushing WhatsApp message directly to queue")
 	if err := directPushMessage.Push(); err != nil {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"delivery/database/migrations"
@@ -101,8 +102,13 @@ func getSortedVersions() []string {
 
 		for k := 0; k < len(v1Parts) && k < len(v2Parts); k++ {
 			if v1Parts[k] != v2Parts[k] {
-				// Return true if v1 < v2
-				return v1Parts[k] < v2Parts[k]
+				// Return true if v1 < v2, compared as numbers so 0.0.10 comes after 0.0.9
+				n1, err1 := strconv.Atoi(v1Parts[k])
+				n2, err2 := strconv.Atoi(v2Parts[k])
+				if err1 != nil || err2 != nil {
+					return v1Parts[k] < v2Parts[k]
+				}
+				return n1 < n2
 			}
 		}
 
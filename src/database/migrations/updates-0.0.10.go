@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"delivery/helper"
+	"delivery/services"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("0010", ApplyMigrationV010)
+}
+
+// ApplyMigrationV010 re-encrypts the provider secure configs still stored with AES-CFB using AES-GCM.
+// Providers that cannot be converted are logged and left unchanged, so the service still starts and
+// they can be converted with the rotate-encryption-key command and ALLOW_LEGACY_CFB once their key
+// is configured. Until then their secure config is rejected.
+func ApplyMigrationV010(db *gorm.DB) error {
+	rotationService, err := services.NewKeyRotationService(db, db)
+	if err != nil {
+		return fmt.Errorf("failed to create key rotation service: %v", err)
+	}
+
+	result, err := rotationService.MigrateLegacyProviderSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to update providers table: %v", err)
+	}
+
+	for _, failure := range result.Failed {
+		helper.Log.WithField("providerUUID", failure.ProviderUUID).
+			WithField("error", failure.Error).
+			Error("Provider secure config cannot be converted from legacy AES-CFB, run rotate-encryption-key with ALLOW_LEGACY_CFB=true once its key is configured")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("0011", ApplyMigrationV011)
+}
+
+// ApplyMigrationV011 adds template versioning and records the template version used by each message
+func ApplyMigrationV011(db *gorm.DB) error {
+	// Add version column to templates table
+	if err := db.AutoMigrate(&models.Template{}); err != nil {
+		return fmt.Errorf("failed to update templates table: %v", err)
+	}
+
+	// Create template_versions table
+	if err := db.AutoMigrate(&models.TemplateVersion{}); err != nil {
+		return fmt.Errorf("failed to create template_versions table: %v", err)
+	}
+
+	// Add template_version column to messages table
+	if err := db.AutoMigrate(&models.Message{}); err != nil {
+		return fmt.Errorf("failed to update messages table: %v", err)
+	}
+
+	// The current content of existing templates becomes their first version
+	err := db.Exec(`INSERT INTO template_versions (template_id, template_uuid, version, subject, content, text_content, template_ids, author, comment, created_at)
+		SELECT id, uuid, version, subject, content, text_content, template_ids, '', 'Recorded when template versioning was introduced', updated_at
+		FROM templates
+		WHERE NOT EXISTS (SELECT 1 FROM template_versions WHERE template_versions.template_id = templates.id)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to update template_versions table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("0012", ApplyMigrationV012)
+}
+
+// ApplyMigrationV012 adds the IN_PROGRESS escalation step status, set while the scheduler queues a step
+func ApplyMigrationV012(db *gorm.DB) error {
+	if err := db.Exec("ALTER TABLE escalation_steps DROP CONSTRAINT IF EXISTS chk_escalation_steps_status").Error; err != nil {
+		return fmt.Errorf("failed to drop escalation_steps status constraint: %v", err)
+	}
+
+	// Recreate the status constraint
+	if err := db.AutoMigrate(&models.EscalationStep{}); err != nil {
+		return fmt.Errorf("failed to update escalation_steps table: %v", err)
+	}
+
+	return nil
+}
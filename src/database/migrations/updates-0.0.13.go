@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("0013", ApplyMigrationV013)
+}
+
+// ApplyMigrationV013 makes inbound messages unique per provider message ID, so a webhook retried by the
+// provider is stored and acted on once
+func ApplyMigrationV013(db *gorm.DB) error {
+	// Remove the copies stored by earlier retries, keeping the first
+	if err := db.Exec(`DELETE FROM inbound_messages duplicate
+		USING inbound_messages original
+		WHERE duplicate.provider_uuid = original.provider_uuid
+		AND duplicate.provider_message_id = original.provider_message_id
+		AND duplicate.provider_message_id <> ''
+		AND duplicate.id > original.id`).Error; err != nil {
+		return fmt.Errorf("failed to remove duplicate inbound messages: %v", err)
+	}
+
+	if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_inbound_messages_provider_message
+		ON inbound_messages (provider_uuid, provider_message_id)
+		WHERE provider_message_id <> ''`).Error; err != nil {
+		return fmt.Errorf("failed to create inbound_messages provider message index: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("0014", ApplyMigrationV014)
+}
+
+// ApplyMigrationV014 keys WhatsApp sessions by tenant as well as provider and address, so sessions
+// of different tenants never match each other
+func ApplyMigrationV014(db *gorm.DB) error {
+	// The new unique index starts with tenant_id, which makes the single column index redundant
+	for _, index := range []string{"idx_whatsapp_session_provider_address", "idx_whatsapp_sessions_tenant_id"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			return fmt.Errorf("failed to drop whatsapp_sessions index %s: %v", index, err)
+		}
+	}
+
+	if err := db.AutoMigrate(&models.WhatsAppSession{}); err != nil {
+		return fmt.Errorf("failed to update whatsapp_sessions table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("002", ApplyMigrationV002)
+}
+
+// ApplyMigrationV002 adds multi-channel notifications
+func ApplyMigrationV002(db *gorm.DB) error {
+	// Create notifications table
+	if err := db.AutoMigrate(&models.Notification{}); err != nil {
+		return fmt.Errorf("failed to create notifications table: %v", err)
+	}
+
+	// Create GIN index for JSON fields in notifications table
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_notifications_identifiers ON notifications USING GIN (identifiers jsonb_path_ops)").Error; err != nil {
+		return fmt.Errorf("failed to create GIN index on notifications.identifiers: %v", err)
+	}
+
+	// Add notification_uuid column to messages table
+	if err := db.AutoMigrate(&models.Message{}); err != nil {
+		return fmt.Errorf("failed to update messages table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("003", ApplyMigrationV003)
+}
+
+// ApplyMigrationV003 adds the suppression list and the SUPPRESSED status
+func ApplyMigrationV003(db *gorm.DB) error {
+	// Create suppressions table
+	if err := db.AutoMigrate(&models.Suppression{}); err != nil {
+		return fmt.Errorf("failed to create suppressions table: %v", err)
+	}
+
+	// Drop the status check constraints so they are recreated with the SUPPRESSED status
+	if err := db.Exec("ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_messages_status").Error; err != nil {
+		return fmt.Errorf("failed to drop messages status constraint: %v", err)
+	}
+
+	if err := db.Exec("ALTER TABLE message_events DROP CONSTRAINT IF EXISTS chk_message_events_status").Error; err != nil {
+		return fmt.Errorf("failed to drop message_events status constraint: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Message{}); err != nil {
+		return fmt.Errorf("failed to update messages table: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.MessageEvent{}); err != nil {
+		return fmt.Errorf("failed to update message_events table: %v", err)
+	}
+
+	return nil
+}
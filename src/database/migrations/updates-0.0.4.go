@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("004", ApplyMigrationV004)
+}
+
+// ApplyMigrationV004 adds inbound messages and outbound recipient tracking for reply correlation
+func ApplyMigrationV004(db *gorm.DB) error {
+	// Create inbound_messages table
+	if err := db.AutoMigrate(&models.InboundMessage{}); err != nil {
+		return fmt.Errorf("failed to create inbound_messages table: %v", err)
+	}
+
+	// Create message_recipients table
+	if err := db.AutoMigrate(&models.MessageRecipient{}); err != nil {
+		return fmt.Errorf("failed to create message_recipients table: %v", err)
+	}
+
+	return nil
+}
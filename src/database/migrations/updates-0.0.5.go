@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("005", ApplyMigrationV005)
+}
+
+// ApplyMigrationV005 adds acknowledgement keywords and the ACKNOWLEDGED and ESCALATED statuses
+func ApplyMigrationV005(db *gorm.DB) error {
+	// Add ack_keywords column to templates table
+	if err := db.AutoMigrate(&models.Template{}); err != nil {
+		return fmt.Errorf("failed to update templates table: %v", err)
+	}
+
+	// Widen the status columns, ACKNOWLEDGED does not fit in 10 characters
+	if err := db.Exec("ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_messages_status").Error; err != nil {
+		return fmt.Errorf("failed to drop messages status constraint: %v", err)
+	}
+
+	if err := db.Exec("ALTER TABLE messages ALTER COLUMN status TYPE varchar(20)").Error; err != nil {
+		return fmt.Errorf("failed to widen messages.status: %v", err)
+	}
+
+	if err := db.Exec("ALTER TABLE message_events DROP CONSTRAINT IF EXISTS chk_message_events_status").Error; err != nil {
+		return fmt.Errorf("failed to drop message_events status constraint: %v", err)
+	}
+
+	if err := db.Exec("ALTER TABLE message_events ALTER COLUMN status TYPE varchar(20)").Error; err != nil {
+		return fmt.Errorf("failed to widen message_events.status: %v", err)
+	}
+
+	// Add template_uuid column and recreate the status constraints
+	if err := db.AutoMigrate(&models.Message{}); err != nil {
+		return fmt.Errorf("failed to update messages table: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.MessageEvent{}); err != nil {
+		return fmt.Errorf("failed to update message_events table: %v", err)
+	}
+
+	// Add ack_action column to inbound_messages table
+	if err := db.AutoMigrate(&models.InboundMessage{}); err != nil {
+		return fmt.Errorf("failed to update inbound_messages table: %v", err)
+	}
+
+	return nil
+}
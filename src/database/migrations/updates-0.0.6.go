@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("006", ApplyMigrationV006)
+}
+
+// ApplyMigrationV006 adds escalation policies and escalation runs
+func ApplyMigrationV006(db *gorm.DB) error {
+	// Create escalation_policies table
+	if err := db.AutoMigrate(&models.EscalationPolicy{}); err != nil {
+		return fmt.Errorf("failed to create escalation_policies table: %v", err)
+	}
+
+	// Create escalation_policy_steps table
+	if err := db.AutoMigrate(&models.EscalationPolicyStep{}); err != nil {
+		return fmt.Errorf("failed to create escalation_policy_steps table: %v", err)
+	}
+
+	// Create escalations table
+	if err := db.AutoMigrate(&models.Escalation{}); err != nil {
+		return fmt.Errorf("failed to create escalations table: %v", err)
+	}
+
+	// Create escalation_steps table
+	if err := db.AutoMigrate(&models.EscalationStep{}); err != nil {
+		return fmt.Errorf("failed to create escalation_steps table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("007", ApplyMigrationV007)
+}
+
+// ApplyMigrationV007 adds WhatsApp session tracking
+func ApplyMigrationV007(db *gorm.DB) error {
+	// Create whatsapp_sessions table
+	if err := db.AutoMigrate(&models.WhatsAppSession{}); err != nil {
+		return fmt.Errorf("failed to create whatsapp_sessions table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("008", ApplyMigrationV008)
+}
+
+// ApplyMigrationV008 adds the plain-text alternative to templates
+func ApplyMigrationV008(db *gorm.DB) error {
+	// Add text_content column to templates table
+	if err := db.AutoMigrate(&models.Template{}); err != nil {
+		return fmt.Errorf("failed to update templates table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"delivery/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration("009", ApplyMigrationV009)
+}
+
+// ApplyMigrationV009 stores the provider message ID of each recipient so delivery receipts can be matched
+func ApplyMigrationV009(db *gorm.DB) error {
+	// Add provider_message_id column to message_recipients table
+	if err := db.AutoMigrate(&models.MessageRecipient{}); err != nil {
+		return fmt.Errorf("failed to update message_recipients table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EscalationHandler handles escalation policy and escalation endpoints
+type EscalationHandler struct {
+	api *api.EscalationAPI
+}
+
+// NewEscalationHandler creates a new escalation handler
+func NewEscalationHandler(db *gorm.DB, readerDB *gorm.DB) *EscalationHandler {
+	escalationAPI, err := api.NewEscalationAPI(db, readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create escalation API: %v", err)
+		return nil
+	}
+
+	return &EscalationHandler{
+		api: escalationAPI,
+	}
+}
+
+// RegisterEscalationRoutes registers all escalation-related routes
+func RegisterEscalationRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
+	handler := NewEscalationHandler(db, readerDB)
+	if handler == nil {
+		helper.Log.Error("Failed to create escalation handler")
+		return
+	}
+
+	// Escalation policy management endpoints
+	r.HandleFunc("/api/v1/escalation-policies", handler.CreatePolicies).Methods("POST")
+	r.HandleFunc("/api/v1/escalation-policies", handler.ListPolicies).Methods("GET")
+	r.HandleFunc("/api/v1/escalation-policies/{uuid}", handler.GetPolicy).Methods("GET")
+	r.HandleFunc("/api/v1/escalation-policies/{uuid}", handler.UpdatePolicy).Methods("PUT")
+
+	// Escalation endpoints
+	r.HandleFunc("/api/v1/escalations", handler.StartEscalations).Methods("POST")
+	r.HandleFunc("/api/v1/escalations", handler.ListEscalations).Methods("GET")
+	r.HandleFunc("/api/v1/escalations/{uuid}", handler.GetEscalation).Methods("GET")
+	r.HandleFunc("/api/v1/escalations/{uuid}/cancel", handler.CancelEscalation).Methods("POST")
+}
+
+// parseLimitOffset reads the limit and offset query parameters
+func parseLimitOffset(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+
+	limit, offset := 0, 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid limit parameter")
+		}
+		limit = value
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		value, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid offset parameter")
+		}
+		offset = value
+	}
+	return limit, offset, nil
+}
+
+// CreatePolicies handles the creation of new escalation policies
+func (h *EscalationHandler) CreatePolicies(w http.ResponseWriter, r *http.Request) {
+	var request api.EscalationPolicyRequest
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "CreatePolicies",
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.api.CreatePolicies(request)
+	if err != nil {
+		if err.Error() == fmt.Sprintf("%d:%s", helper.CodeDuplicate, helper.MsgDuplicate) {
+			helper.RespondWithError(w, http.StatusConflict, helper.CodeDuplicate, helper.MsgDuplicate)
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "CreatePolicies",
+			"error":   err.Error(),
+		}).Warn("Failed to create escalation policies")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusCreated, "Escalation policies created successfully", response)
+}
+
+// GetPolicy handles retrieving an escalation policy by UUID
+func (h *EscalationHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetPolicy(uuid)
+	if err != nil {
+		if err.Error() == "escalation policy not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Escalation policy not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetPolicy",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get escalation policy")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalation policy retrieved successfully", response)
+}
+
+// ListPolicies handles listing escalation policies with optional filtering
+func (h *EscalationHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parseLimitOffset(r)
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	params := api.EscalationPolicyListParams{
+		Limit:    limit,
+		Offset:   offset,
+		TenantID: r.URL.Query().Get("tenantId"),
+	}
+
+	response, err := h.api.ListPolicies(params)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "ListPolicies",
+			"error":   err.Error(),
+			"params":  params,
+		}).Error("Failed to list escalation policies")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalation policies retrieved successfully", response)
+}
+
+// UpdatePolicy handles updating an existing escalation policy
+func (h *EscalationHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	var request api.EscalationPolicyRequest
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "UpdatePolicy",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.api.UpdatePolicy(uuid, request)
+	if err != nil {
+		if err.Error() == "escalation policy not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Escalation policy not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "UpdatePolicy",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Warn("Failed to update escalation policy")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalation policy updated successfully", response)
+}
+
+// StartEscalations handles starting escalations from policies
+func (h *EscalationHandler) StartEscalations(w http.ResponseWriter, r *http.Request) {
+	var request api.EscalationRequest
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "StartEscalations",
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.api.StartEscalations(request)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "StartEscalations",
+			"error":   err.Error(),
+		}).Warn("Failed to start escalations")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusAccepted, "Escalations started successfully", response)
+}
+
+// GetEscalation handles retrieving an escalation by UUID
+func (h *EscalationHandler) GetEscalation(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetEscalation(uuid)
+	if err != nil {
+		if err.Error() == "escalation not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Escalation not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetEscalation",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get escalation")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalation retrieved successfully", response)
+}
+
+// ListEscalations handles listing escalations with optional filtering
+func (h *EscalationHandler) ListEscalations(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parseLimitOffset(r)
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	params := api.EscalationListParams{
+		Limit:    limit,
+		Offset:   offset,
+		TenantID: query.Get("tenantId"),
+		RefNo:    query.Get("refno"),
+		Status:   query.Get("status"),
+	}
+
+	response, err := h.api.ListEscalations(params)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "ListEscalations",
+			"error":   err.Error(),
+			"params":  params,
+		}).Error("Failed to list escalations")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalations retrieved successfully", response)
+}
+
+// CancelEscalation handles cancelling an active escalation
+func (h *EscalationHandler) CancelEscalation(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.CancelEscalation(uuid)
+	if err != nil {
+		switch err.Error() {
+		case "escalation not found":
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Escalation not found")
+			return
+		case "escalation is not active":
+			helper.RespondWithError(w, http.StatusConflict, helper.CodeBadRequest, "Escalation is not active")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "CancelEscalation",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to cancel escalation")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Escalation cancelled successfully", response)
+}
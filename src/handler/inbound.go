@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InboundHandler handles inbound message endpoints
+type InboundHandler struct {
+	api *api.InboundAPI
+}
+
+// RegisterInboundRoutes registers all inbound message routes
+func RegisterInboundRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
+	inboundAPI, err := api.NewInboundAPI(db, readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create inbound API: %v", err)
+		return
+	}
+
+	handler := &InboundHandler{
+		api: inboundAPI,
+	}
+
+	r.HandleFunc("/api/v1/inbound", handler.ListInboundMessages).Methods("GET")
+	r.HandleFunc("/api/v1/inbound/{uuid}", handler.GetInboundMessage).Methods("GET")
+}
+
+// GetInboundMessage handles retrieving an inbound message by UUID
+func (h *InboundHandler) GetInboundMessage(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetInboundMessage(uuid)
+	if err != nil {
+		if err.Error() == "inbound message not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Inbound message not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetInboundMessage",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get inbound message")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Inbound message retrieved successfully", response)
+}
+
+// ListInboundMessages handles listing inbound messages with optional filtering
+func (h *InboundHandler) ListInboundMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var params api.InboundListParams
+
+	// Parse limit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid limit parameter")
+			return
+		}
+		params.Limit = limit
+	}
+
+	// Parse offset
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid offset parameter")
+			return
+		}
+		params.Offset = offset
+	}
+
+	// Get other filters
+	params.Channel = query.Get("channel")
+	params.TenantID = query.Get("tenantId")
+	params.From = query.Get("from")
+	params.MessageUUID = query.Get("messageUuid")
+
+	response, err := h.api.ListInboundMessages(params)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "ListInboundMessages",
+			"error":   err.Error(),
+			"params":  params,
+		}).Error("Failed to list inbound messages")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Inbound messages retrieved successfully", response)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"delivery/services/storage"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// MediaHandler serves stored message media to providers
+type MediaHandler struct {
+	api *api.MediaAPI
+}
+
+// RegisterMediaRoutes registers the signed media route
+func RegisterMediaRoutes(r *mux.Router) {
+	mediaAPI, err := api.NewMediaAPI()
+	if err != nil {
+		helper.Log.Errorf("Failed to create media API: %v", err)
+		return
+	}
+
+	handler := &MediaHandler{
+		api: mediaAPI,
+	}
+
+	r.HandleFunc("/api/v1/media/{key}", handler.GetMedia).Methods("GET")
+}
+
+// GetMedia handles serving a stored media file. Access is granted by the URL signature alone
+// because providers fetch the file without credentials.
+func (h *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	query := r.URL.Query()
+
+	object, err := h.api.GetMedia(key, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidSignature) {
+			helper.RespondWithError(w, http.StatusForbidden, http.StatusForbidden, "Invalid or expired media link")
+			return
+		}
+		if err.Error() == "media not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Media not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetMedia",
+			"key":     key,
+			"error":   err.Error(),
+		}).Error("Failed to get media")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.Data)))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(object.Data); err != nil {
+		helper.Log.WithError(err).WithField("key", key).Warn("Failed to write media response")
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MessageHandler handles message lookup endpoints
+type MessageHandler struct {
+	api *api.MessageAPI
+}
+
+// RegisterMessageRoutes registers all message lookup routes
+func RegisterMessageRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
+	messageAPI, err := api.NewMessageAPI(db, readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create message API: %v", err)
+		return
+	}
+
+	handler := &MessageHandler{
+		api: messageAPI,
+	}
+
+	r.HandleFunc("/api/v1/messages/{uuid}", handler.GetMessage).Methods("GET")
+}
+
+// GetMessage handles retrieving a message with its timeline by UUID
+func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetMessage(uuid)
+	if err != nil {
+		if err.Error() == "message not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Message not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetMessage",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get message")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Message retrieved successfully", response)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"delivery/services/queue"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler handles multi-channel notification endpoints
+type NotificationHandler struct {
+	api *api.NotificationAPI
+}
+
+// RegisterNotificationRoutes registers all notification-related routes
+func RegisterNotificationRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClient) {
+	notificationAPI, err := api.NewNotificationAPI(db, readerDB, pulsarClient)
+	if err != nil {
+		helper.Log.Errorf("Failed to create notification API: %v", err)
+		return
+	}
+
+	handler := &NotificationHandler{
+		api: notificationAPI,
+	}
+
+	r.HandleFunc("/api/v1/notifications", handler.HandleNotificationRequest).Methods("POST")
+	r.HandleFunc("/api/v1/notifications/{uuid}", handler.GetNotification).Methods("GET")
+}
+
+// HandleNotificationRequest handles the multi-channel notification request
+func (h *NotificationHandler) HandleNotificationRequest(w http.ResponseWriter, r *http.Request) {
+	var request api.NotificationRequest
+
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "HandleNotificationRequest",
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, helper.MsgInvalidRequestBody)
+		return
+	}
+
+	// Use the API layer to process the request
+	responses, err := h.api.ProcessNotificationBatch(request)
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	// Wrap responses in a "notifications" object
+	responseWrapper := api.NotificationResponse{
+		Notifications: responses,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	helper.WriteJSON(w, responseWrapper)
+}
+
+// GetNotification handles retrieving a notification and its child messages by UUID
+func (h *NotificationHandler) GetNotification(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetNotification(uuid)
+	if err != nil {
+		if err.Error() == "notification not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Notification not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetNotification",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get notification")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Notification retrieved successfully", response)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"delivery/api"
 	"delivery/helper"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -43,6 +45,10 @@ func RegisterProviderRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
 	r.HandleFunc("/api/v1/providers", handler.ListProviders).Methods("GET")
 	r.HandleFunc("/api/v1/providers/{uuid}", handler.GetProvider).Methods("GET")
 	r.HandleFunc("/api/v1/providers/{uuid}", handler.UpdateProvider).Methods("PUT")
+	r.HandleFunc("/api/v1/providers/{uuid}/test", handler.TestProvider).Methods("POST")
+	r.HandleFunc("/api/v1/providers/{uuid}/health", handler.GetProviderHealth).Methods("GET")
+	r.HandleFunc("/api/v1/provider-types", handler.ListProviderTypes).Methods("GET")
+	r.HandleFunc("/api/v1/admin/rotate-encryption-key", handler.RotateEncryptionKey).Methods("POST")
 }
 
 // CreateProviders handles the creation of new providers
@@ -70,6 +76,14 @@ func (h *ProviderHandler) CreateProviders(w http.ResponseWriter, r *http.Request
 			helper.RespondWithError(w, http.StatusConflict, helper.CodeDuplicate, helper.MsgDuplicate)
 			return
 		}
+		if errors.Is(err, api.ErrInvalidProviderConfig) {
+			helper.Log.WithFields(logrus.Fields{
+				"handler": "CreateProviders",
+				"error":   errStr,
+			}).Warn("Bad request - invalid provider configuration")
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, errStr)
+			return
+		}
 		helper.Log.WithFields(logrus.Fields{
 			"handler": "CreateProviders",
 			"error":   errStr,
@@ -127,6 +141,15 @@ func (h *ProviderHandler) UpdateProvider(w http.ResponseWriter, r *http.Request)
 			helper.RespondWithError(w, http.StatusConflict, helper.CodeDuplicate, helper.MsgDuplicate)
 			return
 		}
+		if errors.Is(err, api.ErrInvalidProviderConfig) {
+			helper.Log.WithFields(logrus.Fields{
+				"handler": "UpdateProvider",
+				"uuid":    uuid,
+				"error":   errStr,
+			}).Warn("Bad request - invalid provider configuration")
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, errStr)
+			return
+		}
 		if errStr == "provider not found" {
 			helper.Log.WithFields(logrus.Fields{
 				"handler": "UpdateProvider",
@@ -256,3 +279,101 @@ func (h *ProviderHandler) ListProviders(w http.ResponseWriter, r *http.Request)
 	// Return success response without data wrapper
 	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Providers retrieved successfully", response)
 }
+
+// ListProviderTypes lists the provider implementations with their capabilities and configuration schemas
+func (h *ProviderHandler) ListProviderTypes(w http.ResponseWriter, r *http.Request) {
+	channel := r.URL.Query().Get("channel")
+
+	response := h.api.ListProviderTypes(channel)
+
+	helper.Log.WithFields(logrus.Fields{
+		"handler": "ListProviderTypes",
+		"channel": channel,
+		"count":   len(response.ProviderTypes),
+	}).Debug("Provider types retrieved successfully")
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Provider types retrieved successfully", response)
+}
+
+// TestProvider checks the credentials of a provider and optionally sends a test message
+func (h *ProviderHandler) TestProvider(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	// The body is optional, without an address only the credentials are checked
+	var request api.ProviderTestRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			helper.Log.WithFields(logrus.Fields{
+				"handler": "TestProvider",
+				"uuid":    uuid,
+				"error":   err.Error(),
+			}).Warn("Bad request - invalid request body")
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	response, err := h.api.TestProvider(uuid, request)
+	if err != nil {
+		if err.Error() == "provider not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+			return
+		}
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "TestProvider",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to test provider")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	message := "Provider test passed"
+	if !response.Success {
+		message = "Provider test failed"
+	}
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, message, response)
+}
+
+// GetProviderHealth returns the circuit breaker state and rolling success rates of a provider
+func (h *ProviderHandler) GetProviderHealth(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetProviderHealth(uuid)
+	if err != nil {
+		if err.Error() == "provider not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+			return
+		}
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetProviderHealth",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get provider health")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Provider health retrieved successfully", response)
+}
+
+// RotateEncryptionKey re-encrypts all provider secure configs with the current encryption key
+func (h *ProviderHandler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "true"
+
+	result, err := h.api.RotateEncryptionKey(force)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "RotateEncryptionKey",
+			"error":   err.Error(),
+		}).Error("Failed to rotate encryption key")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, err.Error())
+		return
+	}
+
+	message := "Provider secure configs re-encrypted successfully"
+	if len(result.Failed) > 0 {
+		message = "Some provider secure configs could not be re-encrypted"
+	}
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, message, result)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SuppressionHandler handles suppression list endpoints
+type SuppressionHandler struct {
+	api *api.SuppressionAPI
+}
+
+// NewSuppressionHandler creates a new suppression handler
+func NewSuppressionHandler(db *gorm.DB, readerDB *gorm.DB) *SuppressionHandler {
+	suppressionAPI, err := api.NewSuppressionAPI(db, readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create suppression API: %v", err)
+		return nil
+	}
+
+	return &SuppressionHandler{
+		api: suppressionAPI,
+	}
+}
+
+// RegisterSuppressionRoutes registers all suppression-related routes
+func RegisterSuppressionRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
+	handler := NewSuppressionHandler(db, readerDB)
+	if handler == nil {
+		helper.Log.Error("Failed to create suppression handler")
+		return
+	}
+
+	// Suppression management endpoints
+	r.HandleFunc("/api/v1/suppressions", handler.CreateSuppressions).Methods("POST")
+	r.HandleFunc("/api/v1/suppressions", handler.ListSuppressions).Methods("GET")
+	r.HandleFunc("/api/v1/suppressions/{uuid}", handler.GetSuppression).Methods("GET")
+	r.HandleFunc("/api/v1/suppressions/{uuid}", handler.DeleteSuppression).Methods("DELETE")
+}
+
+// CreateSuppressions handles adding addresses to the suppression list
+func (h *SuppressionHandler) CreateSuppressions(w http.ResponseWriter, r *http.Request) {
+	var request api.SuppressionRequest
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "CreateSuppressions",
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.api.CreateSuppressions(request)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "CreateSuppressions",
+			"error":   err.Error(),
+		}).Warn("Failed to create suppressions")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusCreated, "Suppressions created successfully", response)
+}
+
+// GetSuppression handles retrieving a suppression by UUID
+func (h *SuppressionHandler) GetSuppression(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	response, err := h.api.GetSuppression(uuid)
+	if err != nil {
+		if err.Error() == "suppression not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Suppression not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "GetSuppression",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to get suppression")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Suppression retrieved successfully", response)
+}
+
+// ListSuppressions handles listing suppressions with optional filtering
+func (h *SuppressionHandler) ListSuppressions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var params api.SuppressionListParams
+
+	// Parse limit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid limit parameter")
+			return
+		}
+		params.Limit = limit
+	}
+
+	// Parse offset
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid offset parameter")
+			return
+		}
+		params.Offset = offset
+	}
+
+	// Get other filters
+	params.Channel = query.Get("channel")
+	params.TenantID = query.Get("tenantId")
+	params.Address = query.Get("address")
+	params.Reason = query.Get("reason")
+
+	response, err := h.api.ListSuppressions(params)
+	if err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "ListSuppressions",
+			"error":   err.Error(),
+			"params":  params,
+		}).Error("Failed to list suppressions")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Suppressions retrieved successfully", response)
+}
+
+// DeleteSuppression handles removing an address from the suppression list
+func (h *SuppressionHandler) DeleteSuppression(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	if err := h.api.DeleteSuppression(uuid); err != nil {
+		if err.Error() == "suppression not found" {
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Suppression not found")
+			return
+		}
+
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "DeleteSuppression",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Failed to delete suppression")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Suppression deleted successfully", nil)
+}
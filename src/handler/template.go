@@ -43,10 +43,20 @@ func RegisterTemplateRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB) {
 	r.HandleFunc("/api/v1/templates", handler.ListTemplates).Methods("GET")
 	r.HandleFunc("/api/v1/templates/{uuid}", handler.GetTemplate).Methods("GET")
 	r.HandleFunc("/api/v1/templates/{uuid}", handler.UpdateTemplate).Methods("PUT")
+	r.HandleFunc("/api/v1/templates/{uuid}/render", handler.RenderTemplate).Methods("POST")
+	r.HandleFunc("/api/v1/templates/{uuid}/versions", handler.ListTemplateVersions).Methods("GET")
+	r.HandleFunc("/api/v1/templates/{uuid}/versions/diff", handler.DiffTemplateVersions).Methods("GET")
+	r.HandleFunc("/api/v1/templates/{uuid}/versions/{version:[0-9]+}", handler.GetTemplateVersion).Methods("GET")
+	r.HandleFunc("/api/v1/templates/{uuid}/versions/{version:[0-9]+}/rollback", handler.RollbackTemplate).Methods("POST")
 }
 
 // CreateTemplates handles the creation of new templates
 func (h *TemplateHandler) CreateTemplates(w http.ResponseWriter, r *http.Request) {
+	author, ok := templateAuthor(w, r, "CreateTemplates")
+	if !ok {
+		return
+	}
+
 	var request api.TemplateRequest
 	if err := helper.ValidateRequestBody(r, &request); err != nil {
 		helper.Log.WithFields(logrus.Fields{
@@ -58,7 +68,7 @@ func (h *TemplateHandler) CreateTemplates(w http.ResponseWriter, r *http.Request
 	}
 
 	// Call API to create templates
-	response, err := h.api.CreateTemplates(request)
+	response, err := h.api.CreateTemplates(request, author)
 	if err != nil {
 		errStr := err.Error()
 		if errStr == fmt.Sprintf("%d:%s", helper.CodeDuplicate, helper.MsgDuplicate) {
@@ -103,6 +113,11 @@ func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	author, ok := templateAuthor(w, r, "UpdateTemplate")
+	if !ok {
+		return
+	}
+
 	var request api.TemplateRequest
 	if err := helper.ValidateRequestBody(r, &request); err != nil {
 		helper.Log.WithFields(logrus.Fields{
@@ -115,7 +130,7 @@ func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Call API to update template
-	response, err := h.api.UpdateTemplate(uuid, request)
+	response, err := h.api.UpdateTemplate(uuid, request, author)
 	if err != nil {
 		errStr := err.Error()
 		if errStr == fmt.Sprintf("%d:%s", helper.CodeDuplicate, helper.MsgDuplicate) {
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RenderTemplate handles previewing a template rendered with sample params
+func (h *TemplateHandler) RenderTemplate(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+
+	var request api.TemplateRenderRequest
+	if err := helper.ValidateRequestBody(r, &request); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler": "RenderTemplate",
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Warn("Bad request - invalid request body")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.api.RenderTemplate(uuid, request)
+	if err != nil {
+		if errors.Is(err, api.ErrInvalidRenderRecipient) {
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+			return
+		}
+		respondTemplateVersionError(w, "RenderTemplate", uuid, err)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Template rendered successfully", response)
+}
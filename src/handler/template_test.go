@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	helper.InitLogger()
+	helper.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestTemplateChangesRequireAuthor(t *testing.T) {
+	const body = `{"templates":[{"code":"WELCOME","name":"Welcome","content":"Hello","channel":"SMS","tenantId":"tenant"}]}`
+
+	tests := []struct {
+		name    string
+		method  string
+		vars    map[string]string
+		author  string
+		handler func(h *TemplateHandler) http.HandlerFunc
+	}{
+		{name: "create", method: "POST", handler: func(h *TemplateHandler) http.HandlerFunc { return h.CreateTemplates }},
+		{name: "update", method: "PUT", vars: map[string]string{"uuid": "template-uuid"}, handler: func(h *TemplateHandler) http.HandlerFunc { return h.UpdateTemplate }},
+		{name: "rollback", method: "POST", vars: map[string]string{"uuid": "template-uuid", "version": "2"}, handler: func(h *TemplateHandler) http.HandlerFunc { return h.RollbackTemplate }},
+		{name: "blank author", method: "POST", author: "  ", handler: func(h *TemplateHandler) http.HandlerFunc { return h.CreateTemplates }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sqlDB.Close()
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := &TemplateHandler{api: &api.TemplateAPI{DB: db, ReaderDB: db}}
+
+			req := httptest.NewRequest(tt.method, "/api/v1/templates", strings.NewReader(body))
+			if tt.author != "" {
+				req.Header.Set("X-Author", tt.author)
+			}
+			req = mux.SetURLVars(req, tt.vars)
+			rec := httptest.NewRecorder()
+			tt.handler(h)(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(rec.Body.String(), "X-Author") {
+				t.Errorf("body = %s, want a message about the X-Author header", rec.Body.String())
+			}
+			// Nothing may be written without an author
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// templateAuthor returns who made a template change, as given by the X-Author header. The header is
+// required so every version has an author, a 400 response is written and false returned without it.
+func templateAuthor(w http.ResponseWriter, r *http.Request, handlerName string) (string, bool) {
+	author := strings.TrimSpace(r.Header.Get("X-Author"))
+	if author == "" {
+		helper.Log.WithField("handler", handlerName).Warn("Bad request - missing X-Author header")
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Missing X-Author header")
+		return "", false
+	}
+	return author, true
+}
+
+// respondTemplateVersionError writes the response for an error of the template version API
+func respondTemplateVersionError(w http.ResponseWriter, handlerName string, uuid string, err error) {
+	switch {
+	case errors.Is(err, api.ErrTemplateNotFound):
+		helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Template not found")
+	case errors.Is(err, api.ErrTemplateVersionNotFound):
+		helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Template version not found")
+	default:
+		helper.Log.WithFields(logrus.Fields{
+			"handler": handlerName,
+			"uuid":    uuid,
+			"error":   err.Error(),
+		}).Error("Template version request failed")
+		helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+	}
+}
+
+// ListTemplateVersions handles listing the versions of a template
+func (h *TemplateHandler) ListTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	query := r.URL.Query()
+
+	limit, err := optionalInt(query.Get("limit"))
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid limit parameter")
+		return
+	}
+	offset, err := optionalInt(query.Get("offset"))
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid offset parameter")
+		return
+	}
+
+	response, err := h.api.ListTemplateVersions(uuid, limit, offset)
+	if err != nil {
+		respondTemplateVersionError(w, "ListTemplateVersions", uuid, err)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Template versions retrieved successfully", response)
+}
+
+// GetTemplateVersion handles retrieving one version of a template
+func (h *TemplateHandler) GetTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	uuid := vars["uuid"]
+	version, _ := strconv.Atoi(vars["version"])
+
+	response, err := h.api.GetTemplateVersion(uuid, version)
+	if err != nil {
+		respondTemplateVersionError(w, "GetTemplateVersion", uuid, err)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Template version retrieved successfully", response)
+}
+
+// DiffTemplateVersions handles comparing two versions of a template
+func (h *TemplateHandler) DiffTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	query := r.URL.Query()
+
+	from, err := optionalInt(query.Get("from"))
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid from parameter")
+		return
+	}
+	to, err := optionalInt(query.Get("to"))
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, "Invalid to parameter")
+		return
+	}
+
+	response, err := h.api.DiffTemplateVersions(uuid, from, to)
+	if err != nil {
+		respondTemplateVersionError(w, "DiffTemplateVersions", uuid, err)
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Template versions compared successfully", response)
+}
+
+// RollbackTemplate handles restoring an earlier version of a template
+func (h *TemplateHandler) RollbackTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	uuid := vars["uuid"]
+	version, _ := strconv.Atoi(vars["version"])
+
+	author, ok := templateAuthor(w, r, "RollbackTemplate")
+	if !ok {
+		return
+	}
+
+	response, err := h.api.RollbackTemplate(uuid, version, author)
+	if err != nil {
+		respondTemplateVersionError(w, "RollbackTemplate", uuid, err)
+		return
+	}
+
+	helper.Log.WithFields(logrus.Fields{
+		"handler": "RollbackTemplate",
+		"uuid":    uuid,
+		"version": version,
+	}).Info("Template rolled back successfully")
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Template rolled back successfully", response)
+}
+
+// optionalInt parses an optional integer query parameter, 0 when it is empty
+func optionalInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
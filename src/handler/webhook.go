@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"delivery/api"
+	"delivery/helper"
+	"delivery/services/providers/sms"
+	"delivery/services/queue"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler handles callbacks posted by providers
+type WebhookHandler struct {
+	api *api.WebhookAPI
+}
+
+// RegisterWebhookRoutes registers all provider webhook routes
+func RegisterWebhookRoutes(r *mux.Router, db *gorm.DB, readerDB *gorm.DB, pulsarClient *queue.PulsarClient) {
+	webhookAPI, err := api.NewWebhookAPI(db, readerDB, pulsarClient)
+	if err != nil {
+		helper.Log.Errorf("Failed to create webhook API: %v", err)
+		return
+	}
+
+	handler := &WebhookHandler{
+		api: webhookAPI,
+	}
+
+	r.HandleFunc("/api/v1/webhooks/sendgrid/{providerUUID}", handler.HandleSendGridEvents).Methods("POST")
+	r.HandleFunc("/api/v1/webhooks/twilio/{providerUUID}/inbound", handler.HandleTwilioInbound).Methods("POST")
+	r.HandleFunc("/api/v1/webhooks/meta/{providerUUID}", handler.HandleMetaVerification).Methods("GET")
+	r.HandleFunc("/api/v1/webhooks/meta/{providerUUID}", handler.HandleMetaWebhook).Methods("POST")
+
+	// Mobile originated messages of SMPP binds arrive outside HTTP and take the same inbound path
+	sms.SetInboundMessageHandler(handler.HandleSMPPInbound)
+}
+
+// HandleSMPPInbound handles a mobile originated message received on an SMPP bind
+func (h *WebhookHandler) HandleSMPPInbound(message sms.InboundMessage) {
+	if _, err := h.api.ProcessSMPPInbound(message); err != nil {
+		helper.Log.WithFields(logrus.Fields{
+			"handler":       "HandleSMPPInbound",
+			"provider_uuid": message.ProviderUUID,
+			"error":         err.Error(),
+		}).Error("Failed to process SMPP inbound message")
+	}
+}
+
+// HandleSendGridEvents handles the SendGrid event webhook
+func (h *WebhookHandler) HandleSendGridEvents(w http.ResponseWriter, r *http.Request) {
+	providerUUID := mux.Vars(r)["providerUUID"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, helper.MsgInvalidRequestBody)
+		return
+	}
+	defer r.Body.Close()
+
+	suppressed, err := h.api.ProcessSendGridEvents(
+		providerUUID,
+		body,
+		r.Header.Get("X-Twilio-Email-Event-Webhook-Signature"),
+		r.Header.Get("X-Twilio-Email-Event-Webhook-Timestamp"),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, api.ErrInvalidWebhookSignature):
+			helper.RespondWithError(w, http.StatusUnauthorized, http.StatusUnauthorized, "Invalid webhook signature")
+		case err.Error() == "provider not found":
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+		default:
+			helper.Log.WithFields(logrus.Fields{
+				"handler":       "HandleSendGridEvents",
+				"provider_uuid": providerUUID,
+				"error":         err.Error(),
+			}).Error("Failed to process SendGrid events")
+			helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, err.Error())
+		}
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Events processed successfully", map[string]int{
+		"suppressed": suppressed,
+	})
+}
+
+// HandleTwilioInbound handles inbound SMS and WhatsApp messages posted by Twilio
+func (h *WebhookHandler) HandleTwilioInbound(w http.ResponseWriter, r *http.Request) {
+	providerUUID := mux.Vars(r)["providerUUID"]
+
+	if err := r.ParseForm(); err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, helper.MsgInvalidRequestBody)
+		return
+	}
+
+	_, err := h.api.ProcessTwilioInbound(providerUUID, publicRequestURL(r), r.PostForm, r.Header.Get("X-Twilio-Signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, api.ErrInvalidWebhookSignature):
+			helper.RespondWithError(w, http.StatusForbidden, http.StatusForbidden, "Invalid webhook signature")
+		case err.Error() == "provider not found":
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+		default:
+			helper.Log.WithFields(logrus.Fields{
+				"handler":       "HandleTwilioInbound",
+				"provider_uuid": providerUUID,
+				"error":         err.Error(),
+			}).Error("Failed to process Twilio inbound message")
+			helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		}
+		return
+	}
+
+	// An empty TwiML response tells Twilio not to send an automatic reply
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Response></Response>`))
+}
+
+// HandleMetaVerification answers the verification request sent when the Meta webhook is registered
+func (h *WebhookHandler) HandleMetaVerification(w http.ResponseWriter, r *http.Request) {
+	providerUUID := mux.Vars(r)["providerUUID"]
+	query := r.URL.Query()
+
+	challenge, err := h.api.VerifyMetaWebhook(providerUUID, query.Get("hub.mode"), query.Get("hub.verify_token"), query.Get("hub.challenge"))
+	if err != nil {
+		switch {
+		case errors.Is(err, api.ErrInvalidWebhookSignature):
+			helper.RespondWithError(w, http.StatusForbidden, http.StatusForbidden, "Invalid verify token")
+		case err.Error() == "provider not found":
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+		default:
+			helper.Log.WithFields(logrus.Fields{
+				"handler":       "HandleMetaVerification",
+				"provider_uuid": providerUUID,
+				"error":         err.Error(),
+			}).Error("Failed to verify Meta webhook")
+			helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		}
+		return
+	}
+
+	// Meta expects the challenge echoed back as the plain response body
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(challenge))
+}
+
+// HandleMetaWebhook handles statuses and inbound messages posted by the WhatsApp Cloud API
+func (h *WebhookHandler) HandleMetaWebhook(w http.ResponseWriter, r *http.Request) {
+	providerUUID := mux.Vars(r)["providerUUID"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		helper.RespondWithError(w, http.StatusBadRequest, helper.CodeBadRequest, helper.MsgInvalidRequestBody)
+		return
+	}
+	defer r.Body.Close()
+
+	statuses, received, err := h.api.ProcessMetaWebhook(providerUUID, body, r.Header.Get("X-Hub-Signature-256"))
+	if err != nil {
+		switch {
+		case errors.Is(err, api.ErrInvalidWebhookSignature):
+			helper.RespondWithError(w, http.StatusUnauthorized, http.StatusUnauthorized, "Invalid webhook signature")
+		case err.Error() == "provider not found":
+			helper.RespondWithError(w, http.StatusNotFound, helper.CodeNotFound, "Provider not found")
+		default:
+			helper.Log.WithFields(logrus.Fields{
+				"handler":       "HandleMetaWebhook",
+				"provider_uuid": providerUUID,
+				"error":         err.Error(),
+			}).Error("Failed to process Meta webhook")
+			helper.RespondWithError(w, http.StatusInternalServerError, helper.CodeServerError, helper.MsgServerError)
+		}
+		return
+	}
+
+	helper.RespondWithSuccessNoDataWrapper(w, http.StatusOK, "Webhook processed successfully", map[string]int{
+		"statuses": statuses,
+		"received": received,
+	})
+}
+
+// publicRequestURL rebuilds the URL the provider called, which is what webhook signatures are computed over.
+// PUBLIC_BASE_URL should be set when the service runs behind a proxy that rewrites the host.
+func publicRequestURL(r *http.Request) string {
+	baseURL := strings.TrimRight(helper.GetEnv("PUBLIC_BASE_URL", ""), "/")
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+			scheme = forwarded
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+	return baseURL + r.URL.RequestURI()
+}
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// CheckCredentials sends a read-only provider request that is used to verify credentials,
+// failing when the provider answers with an error status
+func CheckCredentials(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("credential check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("credentials rejected, status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -9,30 +9,55 @@ import (
 	"io"
 )
 
-// EncryptAES256 encrypts data using AES-256
-func EncryptAES256(plaintext []byte, key []byte) ([]byte, error) {
-	if len(key) != 32 {
-		return nil, errors.New("encryption key must be 32 bytes for AES-256")
+// EncryptAESGCM encrypts data using AES-256-GCM. The random nonce is prepended to the sealed data and
+// additionalData is authenticated but not encrypted.
+func EncryptAESGCM(plaintext []byte, key []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
 	}
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, err
 	}
 
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// DecryptAESGCM decrypts data encrypted by EncryptAESGCM, failing when the data or additionalData was altered
+func DecryptAESGCM(ciphertext []byte, key []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
 		return nil, err
 	}
 
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
 
-	return ciphertext, nil
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.New("ciphertext failed authentication")
+	}
+	return plaintext, nil
 }
 
-// DecryptAES256 decrypts data using AES-256
+// newGCM creates an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes for AES-256")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DecryptAES256 decrypts data using AES-256-CFB, the unauthenticated format secure configs were stored in
+// before AES-GCM. A wrong key or altered data is not detected.
 func DecryptAES256(ciphertext []byte, key []byte) ([]byte, error) {
 	if len(key) != 32 {
 		return nil, errors.New("decryption key must be 32 bytes for AES-256")
@@ -56,21 +81,7 @@ func DecryptAES256(ciphertext []byte, key []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// EncryptAndBase64Encode encrypts the data with AES-256 and encodes it as base64
-func EncryptAndBase64Encode(data []byte, key []byte) (string, error) {
-	encrypted, err := EncryptAES256(data, key)
-	if err != nil {
-		return "", err
-	}
-	return base64.StdEncoding.EncodeToString(encrypted), nil
-}
-
-// EncryptAndEncodeBase64 encrypts a string with AES-256 and encodes it as base64
-func EncryptAndEncodeBase64(plaintext string, key []byte) (string, error) {
-	return EncryptAndBase64Encode([]byte(plaintext), key)
-}
-
-// DecodeBase64AndDecrypt decodes base64 data and decrypts it with AES-256
+// DecodeBase64AndDecrypt decodes base64 data and decrypts it with AES-256-CFB
 func DecodeBase64AndDecrypt(encoded string, key []byte) ([]byte, error) {
 	decoded, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
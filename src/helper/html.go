@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlHiddenBlocks = regexp.MustCompile(`(?is)<(script|style|head|title)[^>]*>.*?</(script|style|head|title)>`)
+	htmlComments     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlLinks        = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	htmlLineBreaks   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlBlockEnds    = regexp.MustCompile(`(?i)</(p|div|h[1-6]|tr|table|ul|ol|blockquote|pre)>`)
+	htmlListItems    = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlCells        = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTags         = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRuns        = regexp.MustCompile(`[ \t\r\f\v]+`)
+	blankLineRuns    = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText converts an HTML email body to a readable plain-text version.
+// Links keep their target in brackets, block elements and line breaks become new lines.
+func HTMLToText(content string) string {
+	text := htmlHiddenBlocks.ReplaceAllString(content, "")
+	text = htmlComments.ReplaceAllString(text, "")
+	text = htmlLinks.ReplaceAllStringFunc(text, func(link string) string {
+		match := htmlLinks.FindStringSubmatch(link)
+		label := strings.TrimSpace(htmlTags.ReplaceAllString(match[2], ""))
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+		return label + " (" + match[1] + ")"
+	})
+
+	// Collapse source formatting first, the structure comes from the tags
+	text = strings.ReplaceAll(text, "\n", " ")
+	text = htmlLineBreaks.ReplaceAllString(text, "\n")
+	text = htmlBlockEnds.ReplaceAllString(text, "\n\n")
+	text = htmlListItems.ReplaceAllString(text, "\n- ")
+	text = htmlCells.ReplaceAllString(text, " ")
+	text = htmlTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRuns.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLineRuns.ReplaceAllString(text, "\n\n")
+
+	return strings.TrimSpace(text)
+}
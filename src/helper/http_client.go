@@ -0,0 +1,22 @@
+package helper
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewProviderHTTPClient creates an HTTP client with its own connection pool for one provider.
+// Provider instances are cached, so connections and TLS sessions are reused across messages.
+func NewProviderHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 64
+	transport.MaxIdleConnsPerHost = 32 // The default of 2 forces new handshakes under concurrent sends
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ExpectContinueTimeout = time.Second
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
@@ -0,0 +1,257 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// LegacyEncryptionKeyID is the key ID of ENCRYPTION_KEY, which encrypted every secure config stored
+// before key IDs were recorded
+const LegacyEncryptionKeyID = "default"
+
+// DevEncryptionKeyID is the key ID of the fixed development key used in dev mode when no key is configured
+const DevEncryptionKeyID = "dev"
+
+// devEncryptionKey is the fixed development key, it is public and must never protect real credentials
+const devEncryptionKey = "12345678901234567890123456789012"
+
+// SecureConfigVersionGCM marks secure configs encrypted with AES-256-GCM. Secure configs without a
+// version were encrypted with unauthenticated AES-256-CFB. Migration 0.0.10 converts them, afterwards they
+// are only decrypted when ALLOW_LEGACY_CFB=true.
+const SecureConfigVersionGCM = 2
+
+// Keyring holds the keys used for provider secure configs. New secure configs are encrypted with the
+// current key and store its ID next to the ciphertext, so older ones can still be decrypted after a rotation.
+type Keyring struct {
+	currentID string
+	keys      map[string][]byte
+	order     []string // Key IDs in the order they were configured, tried for ciphertexts without a key ID
+	legacyCFB bool     // Whether legacy AES-CFB secure configs are decrypted
+}
+
+// LoadKeyring builds the keyring from the environment.
+// ENCRYPTION_KEYS lists keys as comma separated "id:key" pairs, ENCRYPTION_KEY_ID selects the current
+// key and defaults to the first one listed. ENCRYPTION_KEY is added under the ID "default" and is the
+// current key when ENCRYPTION_KEYS is not set. In dev mode (DEV_MODE=true) the fixed development key
+// is added under the ID "dev" and used when no other key is configured. Legacy AES-CFB secure configs
+// are only decrypted when ALLOW_LEGACY_CFB=true.
+func LoadKeyring() (*Keyring, error) {
+	keyring := &Keyring{
+		keys:      map[string][]byte{},
+		legacyCFB: strings.EqualFold(GetEnv("ALLOW_LEGACY_CFB", ""), "true"),
+	}
+
+	for _, entry := range strings.Split(GetEnv("ENCRYPTION_KEYS", ""), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, errors.New("ENCRYPTION_KEYS entries must be in the form id:key")
+		}
+		if err := keyring.add(id, []byte(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	if legacyKey := GetEnv("ENCRYPTION_KEY", ""); legacyKey != "" {
+		if _, exists := keyring.keys[LegacyEncryptionKeyID]; !exists {
+			if err := keyring.add(LegacyEncryptionKeyID, []byte(legacyKey)); err != nil {
+				return nil, errors.New("ENCRYPTION_KEY environment variable invalid (must be exactly 32 bytes)")
+			}
+		}
+	}
+
+	if IsDevMode() {
+		if _, exists := keyring.keys[DevEncryptionKeyID]; !exists {
+			if err := keyring.add(DevEncryptionKeyID, []byte(devEncryptionKey)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(keyring.order) == 0 {
+		return keyring, nil
+	}
+
+	keyring.currentID = GetEnv("ENCRYPTION_KEY_ID", keyring.order[0])
+	if _, ok := keyring.keys[keyring.currentID]; !ok {
+		return nil, fmt.Errorf("ENCRYPTION_KEY_ID %q is not one of the configured encryption keys", keyring.currentID)
+	}
+	return keyring, nil
+}
+
+// IsDevMode reports whether the service runs in the explicit development mode (DEV_MODE=true)
+func IsDevMode() bool {
+	return strings.EqualFold(GetEnv("DEV_MODE", ""), "true")
+}
+
+// CheckEncryptionKeys verifies a usable encryption key is configured, the service refuses to start without one
+func CheckEncryptionKeys() error {
+	keyring, err := LoadKeyring()
+	if err != nil {
+		return err
+	}
+	if keyring.CurrentKeyID() == "" {
+		return errors.New("no encryption key configured, set ENCRYPTION_KEY or ENCRYPTION_KEYS (or DEV_MODE=true for local development)")
+	}
+	if keyring.CurrentKeyID() == DevEncryptionKeyID {
+		Log.Warn("Dev mode: provider secure configs are encrypted with the public development key")
+	}
+	return nil
+}
+
+// add puts a key on the keyring
+func (k *Keyring) add(id string, key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("encryption key %q must be exactly 32 bytes", id)
+	}
+	if _, exists := k.keys[id]; exists {
+		return fmt.Errorf("encryption key %q is configured more than once", id)
+	}
+	k.keys[id] = key
+	k.order = append(k.order, id)
+	return nil
+}
+
+// AllowLegacyCFB lets the keyring decrypt legacy AES-CFB secure configs, for converting them to AES-GCM
+func (k *Keyring) AllowLegacyCFB() {
+	k.legacyCFB = true
+}
+
+// CurrentKeyID returns the ID of the key new secure configs are encrypted with, empty when no key is configured
+func (k *Keyring) CurrentKeyID() string {
+	return k.currentID
+}
+
+// DeriveKey derives a 32 byte key for another purpose from the current key with HKDF-SHA256. The purpose
+// label separates the derived keys, so the encryption key itself is never used for anything else.
+func (k *Keyring) DeriveKey(purpose string) ([]byte, error) {
+	if k.currentID == "" {
+		return nil, errors.New("no encryption key configured, set ENCRYPTION_KEY or ENCRYPTION_KEYS")
+	}
+	derived := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, k.keys[k.currentID], nil, []byte(purpose)), derived); err != nil {
+		return nil, fmt.Errorf("failed to derive %s key: %w", purpose, err)
+	}
+	return derived, nil
+}
+
+// Encrypt encrypts a plain text secure config with the current key using AES-256-GCM, returning
+// {"encrypted": ..., "keyId": ..., "version": 2}. The key ID is authenticated with the ciphertext.
+func (k *Keyring) Encrypt(plaintext []byte) (map[string]interface{}, error) {
+	if k.currentID == "" {
+		return nil, errors.New("no encryption key configured, set ENCRYPTION_KEY or ENCRYPTION_KEYS")
+	}
+
+	encrypted, err := EncryptAESGCM(plaintext, k.keys[k.currentID], []byte(k.currentID))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"encrypted": Base64Encode(encrypted),
+		"keyId":     k.currentID,
+		"version":   SecureConfigVersionGCM,
+	}, nil
+}
+
+// Decrypt returns the plain text of an encrypted secure config. AES-GCM secure configs fail to decrypt
+// when they were tampered with. The key named by the key ID is used. Legacy AES-CFB secure configs are
+// rejected unless they are allowed, those stored without a key ID are then tried with every key, starting
+// with the legacy key.
+func (k *Keyring) Decrypt(secureConfig map[string]interface{}) ([]byte, error) {
+	encrypted, ok := secureConfig["encrypted"].(string)
+	if !ok || encrypted == "" {
+		return nil, errors.New("secure config is not in the expected format")
+	}
+
+	keyID := SecureConfigKeyID(secureConfig)
+	if version := SecureConfigVersion(secureConfig); version == SecureConfigVersionGCM {
+		key, ok := k.keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("secure config is encrypted with unknown key %q", keyID)
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+		if err != nil {
+			return nil, err
+		}
+		return DecryptAESGCM(ciphertext, key, []byte(keyID))
+	} else if version != 0 {
+		return nil, fmt.Errorf("secure config version %d is not supported", version)
+	}
+
+	// AES-CFB is not authenticated, a secure config without a version must not be trusted by default
+	if !k.legacyCFB {
+		return nil, errors.New("secure config uses legacy AES-CFB encryption, set ALLOW_LEGACY_CFB=true and run the rotate-encryption-key command to re-encrypt it")
+	}
+
+	if keyID != "" {
+		key, ok := k.keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("secure config is encrypted with unknown key %q", keyID)
+		}
+		return DecodeBase64AndDecrypt(encrypted, key)
+	}
+
+	if len(k.order) == 0 {
+		return nil, errors.New("ENCRYPTION_KEY environment variable not set or invalid (must be exactly 32 bytes)")
+	}
+
+	candidates := k.order
+	if _, ok := k.keys[LegacyEncryptionKeyID]; ok {
+		candidates = append([]string{LegacyEncryptionKeyID}, k.order...)
+	}
+	for _, keyID := range candidates {
+		// AES-CFB has no integrity check, a wrong key shows up as plain text that is not JSON
+		plaintext, err := DecodeBase64AndDecrypt(encrypted, k.keys[keyID])
+		if err == nil && json.Valid(plaintext) {
+			return plaintext, nil
+		}
+	}
+	return nil, errors.New("secure config cannot be decrypted with any configured key")
+}
+
+// SecureConfigKeyID returns the ID of the key a secure config was encrypted with, empty for secure
+// configs stored before key IDs were recorded
+func SecureConfigKeyID(secureConfig map[string]interface{}) string {
+	keyID, _ := secureConfig["keyId"].(string)
+	return keyID
+}
+
+// SecureConfigVersion returns the format version of an encrypted secure config, 0 for legacy AES-CFB
+func SecureConfigVersion(secureConfig map[string]interface{}) int {
+	switch version := secureConfig["version"].(type) {
+	case float64:
+		return int(version)
+	case int:
+		return version
+	}
+	return 0
+}
+
+// DecryptSecureConfig decrypts a provider secure config stored as {"encrypted": "...", "keyId": "..."}
+// with the keyring and unmarshals the result into target
+func DecryptSecureConfig(secureConfig map[string]interface{}, target interface{}) error {
+	keyring, err := LoadKeyring()
+	if err != nil {
+		return err
+	}
+
+	decodedBytes, err := keyring.Decrypt(secureConfig)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt provider secure config: %w", err)
+	}
+
+	if err := json.Unmarshal(decodedBytes, target); err != nil {
+		return fmt.Errorf("failed to parse provider secure config: %w", err)
+	}
+	return nil
+}
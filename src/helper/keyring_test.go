@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+const (
+	testKeyOne   = "11111111111111111111111111111111"
+	testKeyTwo   = "22222222222222222222222222222222"
+	testKeyOther = "33333333333333333333333333333333"
+)
+
+// testKeyring loads a keyring from the given ENCRYPTION_KEYS and ENCRYPTION_KEY_ID
+func testKeyring(t *testing.T, keys string, currentID string, allowLegacy bool) *Keyring {
+	t.Helper()
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("ENCRYPTION_KEYS", keys)
+	t.Setenv("ENCRYPTION_KEY_ID", currentID)
+	t.Setenv("DEV_MODE", "")
+	t.Setenv("ALLOW_LEGACY_CFB", "")
+
+	keyring, err := LoadKeyring()
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	if allowLegacy {
+		keyring.AllowLegacyCFB()
+	}
+	return keyring
+}
+
+// encryptCFB encrypts plaintext the way secure configs were stored before AES-GCM
+func encryptCFB(t *testing.T, plaintext string, key string) string {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+	copy(ciphertext, "0123456789abcdef")
+	cipher.NewCFBEncrypter(block, ciphertext[:aes.BlockSize]).XORKeyStream(ciphertext[aes.BlockSize:], []byte(plaintext))
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// tamper flips a bit in the last byte of a base64 ciphertext
+func tamper(t *testing.T, encoded string) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0x01
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestKeyringDecrypt(t *testing.T) {
+	const plaintext = `{"authToken":"secret"}`
+
+	writer := testKeyring(t, "one:"+testKeyOne+",two:"+testKeyTwo, "one", false)
+	gcm, err := writer.Encrypt([]byte(plaintext))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if gcm["keyId"] != "one" || gcm["version"] != SecureConfigVersionGCM {
+		t.Fatalf("Encrypt() = %v, want key ID one and version %d", gcm, SecureConfigVersionGCM)
+	}
+	encrypted := gcm["encrypted"].(string)
+
+	tests := []struct {
+		name         string
+		keys         string
+		currentID    string
+		allowLegacy  bool
+		secureConfig map[string]interface{}
+		wantErr      string
+	}{
+		{
+			name:         "GCM with the current key",
+			keys:         "one:" + testKeyOne + ",two:" + testKeyTwo,
+			currentID:    "one",
+			secureConfig: gcm,
+		},
+		{
+			name:         "GCM with a previous key after rotation",
+			keys:         "two:" + testKeyTwo + ",one:" + testKeyOne,
+			currentID:    "two",
+			secureConfig: gcm,
+		},
+		{
+			name:         "GCM version read from JSON",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"encrypted": encrypted, "keyId": "one", "version": float64(2)},
+		},
+		{
+			name:         "GCM with an unknown key ID",
+			keys:         "two:" + testKeyTwo,
+			currentID:    "two",
+			secureConfig: gcm,
+			wantErr:      `unknown key "one"`,
+		},
+		{
+			name:         "GCM with the wrong key",
+			keys:         "one:" + testKeyOther,
+			currentID:    "one",
+			secureConfig: gcm,
+			wantErr:      "failed authentication",
+		},
+		{
+			name:         "GCM with tampered ciphertext",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"encrypted": tamper(t, encrypted), "keyId": "one", "version": 2},
+			wantErr:      "failed authentication",
+		},
+		{
+			name:         "GCM with a swapped key ID",
+			keys:         "one:" + testKeyOne + ",two:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"encrypted": encrypted, "keyId": "two", "version": 2},
+			wantErr:      "failed authentication",
+		},
+		{
+			name:         "unsupported version",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"encrypted": encrypted, "keyId": "one", "version": 3},
+			wantErr:      "version 3 is not supported",
+		},
+		{
+			name:         "missing ciphertext",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"keyId": "one", "version": 2},
+			wantErr:      "not in the expected format",
+		},
+		{
+			name:         "legacy CFB rejected by default",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne)},
+			wantErr:      "ALLOW_LEGACY_CFB",
+		},
+		{
+			name:         "legacy CFB without key ID",
+			keys:         "two:" + testKeyTwo + ",one:" + testKeyOne,
+			currentID:    "two",
+			allowLegacy:  true,
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne)},
+		},
+		{
+			name:         "legacy CFB with key ID",
+			keys:         "one:" + testKeyOne,
+			currentID:    "one",
+			allowLegacy:  true,
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne), "keyId": "one"},
+		},
+		{
+			name:         "legacy CFB with the wrong key",
+			keys:         "one:" + testKeyOther,
+			currentID:    "one",
+			allowLegacy:  true,
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne)},
+			wantErr:      "cannot be decrypted with any configured key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring := testKeyring(t, tt.keys, tt.currentID, tt.allowLegacy)
+			got, err := keyring.Decrypt(tt.secureConfig)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decrypt() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if string(got) != plaintext {
+				t.Errorf("Decrypt() = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestLoadKeyringAllowLegacyCFB(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", testKeyOne)
+	t.Setenv("ENCRYPTION_KEYS", "")
+	t.Setenv("ENCRYPTION_KEY_ID", "")
+	t.Setenv("DEV_MODE", "")
+	t.Setenv("ALLOW_LEGACY_CFB", "true")
+
+	keyring, err := LoadKeyring()
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	got, err := keyring.Decrypt(map[string]interface{}{"encrypted": encryptCFB(t, `{}`, testKeyOne)})
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if string(got) != `{}` {
+		t.Errorf("Decrypt() = %q, want %q", got, `{}`)
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"encoding/base64"
 	"html/template"
+	"io"
+	texttemplate "text/template"
 	"time"
 )
 
-// ProcessTemplate processes a template string with the given parameters
-func ProcessTemplate(templateString string, params map[string]string) (string, error) {
-	// Create a custom function map to support the {{var "key"}} syntax
-	funcMap := template.FuncMap{
+// templateExecutor is implemented by both html/template and text/template templates
+type templateExecutor interface {
+	Execute(wr io.Writer, data interface{}) error
+}
+
+// templateFuncs returns the functions available to templates, {{var "key"}} reads a parameter
+func templateFuncs(params map[string]string) map[string]interface{} {
+	return map[string]interface{}{
 		"var": func(key string) string {
 			if val, ok := params[key]; ok {
 				return val
@@ -18,30 +24,20 @@ func ProcessTemplate(templateString string, params map[string]string) (string, e
 			return ""
 		},
 	}
+}
 
-	// Get key names from the map, prioritizing string keys over numeric keys
-	// This is helpful for templates that use simple {{.name}} syntax
+// executeTemplate runs a template with the parameters as data to support the {{.key}} syntax, and
+// falls back to no data for templates that only use {{var "key"}}
+func executeTemplate(tmpl templateExecutor, params map[string]string) (string, error) {
 	mappedParams := make(map[string]interface{})
 	for k, v := range params {
 		mappedParams[k] = v
 	}
 
-	// Create the template with our functions
-	tmpl, err := template.New("content").Funcs(funcMap).Parse(templateString)
-	if err != nil {
-		return "", err
-	}
-
-	// First try executing with mappedParams to support {{.key}} syntax
 	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, mappedParams)
-
-	// If there's an error, log it for debugging
-	if err != nil {
-		// Try executing with nil to support {{var "key"}} syntax
+	if err := tmpl.Execute(&buf, mappedParams); err != nil {
 		buf.Reset()
-		err = tmpl.Execute(&buf, nil)
-		if err != nil {
+		if err := tmpl.Execute(&buf, nil); err != nil {
 			return "", err
 		}
 	}
@@ -49,6 +45,25 @@ func ProcessTemplate(templateString string, params map[string]string) (string, e
 	return buf.String(), nil
 }
 
+// ProcessTemplate processes a template string with the given parameters
+func ProcessTemplate(templateString string, params map[string]string) (string, error) {
+	tmpl, err := template.New("content").Funcs(templateFuncs(params)).Parse(templateString)
+	if err != nil {
+		return "", err
+	}
+	return executeTemplate(tmpl, params)
+}
+
+// ProcessTextTemplate processes a plain-text template string with the same syntax as ProcessTemplate,
+// without HTML escaping the parameters
+func ProcessTextTemplate(templateString string, params map[string]string) (string, error) {
+	tmpl, err := texttemplate.New("text").Funcs(templateFuncs(params)).Parse(templateString)
+	if err != nil {
+		return "", err
+	}
+	return executeTemplate(tmpl, params)
+}
+
 // DecodeBase64 decodes a base64 encoded string to bytes
 func DecodeBase64(encoded string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(encoded)
@@ -65,6 +80,14 @@ func DebugTemplate(templateString string, params map[string]string) map[string]i
 	result["template"] = templateString
 	result["params"] = params
 
+	// Try the {{key}} placeholders used by SMS and WhatsApp messages
+	rendered, err := RenderTemplate(templateString, params)
+	if err != nil {
+		result["renderError"] = err.Error()
+	} else {
+		result["renderResult"] = rendered
+	}
+
 	// Try using standard template processing
 	tmpl, err := template.New("content").Parse(templateString)
 	if err != nil {
@@ -82,16 +105,7 @@ func DebugTemplate(templateString string, params map[string]string) map[string]i
 	}
 
 	// Try with var function
-	funcMap := template.FuncMap{
-		"var": func(key string) string {
-			if val, ok := params[key]; ok {
-				return val
-			}
-			return ""
-		},
-	}
-
-	tmpl, err = template.New("content").Funcs(funcMap).Parse(templateString)
+	tmpl, err = template.New("content").Funcs(templateFuncs(params)).Parse(templateString)
 	if err != nil {
 		result["varFuncParseError"] = err.Error()
 		return result
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
 
@@ -8,14 +9,23 @@ import (
 	_ "delivery/database/migrations" // Import migrations package for init() registration
 	"delivery/handler"
 	"delivery/helper"
+	"delivery/services"
 	"delivery/services/queue"
+	"delivery/services/storage"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 func main() {
 	helper.InitLogger()
 
+	// Provider secure configs cannot be read or written without an encryption key
+	if err := helper.CheckEncryptionKeys(); err != nil {
+		helper.Log.Fatalf("Invalid encryption key configuration: %v", err)
+		return
+	}
+
 	// Connect to databases
 	dbConn, err := database.Connect()
 	if err != nil {
@@ -34,6 +44,17 @@ func main() {
 		return
 	}
 
+	// "rotate-encryption-key [--force]" re-encrypts the provider secure configs and exits
+	if len(os.Args) > 1 && os.Args[1] == "rotate-encryption-key" {
+		rotateEncryptionKey(db, readerDB, len(os.Args) > 2 && os.Args[2] == "--force")
+		return
+	}
+
+	// Stored attachments and media are deleted once they are past their retention
+	if err := storage.StartJanitor(); err != nil {
+		helper.Log.Errorf("Failed to start media janitor: %v", err)
+	}
+
 	// Create and configure router
 	r := mux.NewRouter()
 
@@ -52,12 +73,19 @@ func main() {
 		helper.Log.Fatalf("Failed to start consumers: %v", err)
 	}
 
-	// Register API routes for WhatsApp, Email, SMS, Providers, and Templates
+	// Register API routes for WhatsApp, Email, SMS, Notifications, Providers, Templates, Suppressions, Webhooks, Inbound messages, Message lookup, Escalations, and Media
 	handler.RegisterWhatsAppRoutes(r, db, readerDB, consumerManager.GetPulsarClient())
 	handler.RegisterEmailRoutes(r, db, readerDB, consumerManager.GetPulsarClient())
 	handler.RegisterSMSRoutes(r, db, readerDB, consumerManager.GetPulsarClient())
+	handler.RegisterNotificationRoutes(r, db, readerDB, consumerManager.GetPulsarClient())
 	handler.RegisterProviderRoutes(r, db, readerDB)
 	handler.RegisterTemplateRoutes(r, db, readerDB)
+	handler.RegisterSuppressionRoutes(r, db, readerDB)
+	handler.RegisterWebhookRoutes(r, db, readerDB, consumerManager.GetPulsarClient())
+	handler.RegisterInboundRoutes(r, db, readerDB)
+	handler.RegisterMessageRoutes(r, db, readerDB)
+	handler.RegisterEscalationRoutes(r, db, readerDB)
+	handler.RegisterMediaRoutes(r)
 
 	// Start HTTP server
 	port := os.Getenv("PORT")
@@ -71,3 +99,22 @@ func main() {
 		helper.Log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// rotateEncryptionKey runs the key rotation as a one-off command, exiting with 1 when a provider failed
+func rotateEncryptionKey(db *gorm.DB, readerDB *gorm.DB, force bool) {
+	rotationService, err := services.NewKeyRotationService(db, readerDB)
+	if err != nil {
+		helper.Log.Fatalf("Failed to create key rotation service: %v", err)
+	}
+
+	result, err := rotationService.RotateProviderSecrets(force)
+	if err != nil {
+		helper.Log.Fatalf("Failed to rotate encryption key: %v", err)
+	}
+
+	output, _ := json.MarshalIndent(result, "", "  ")
+	os.Stdout.Write(append(output, '\n'))
+	if len(result.Failed) > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,16 +2,23 @@ package models
 
 // EmailMessage represents a single email message in the internal system
 type EmailMessage struct {
-	Template    string                 `json:"template"`
-	To          []EmailRecipient       `json:"to"`
-	Provider    string                 `json:"provider"`
-	RefNo       string                 `json:"refno"`
-	Categories  []string               `json:"categories"`
-	Identifiers map[string]interface{} `json:"identifiers"`
-	Params      map[string]string      `json:"params"`
-	Subject     string                 `json:"subject,omitempty"`
-	Attachments []AttachmentMetadata   `json:"attachments,omitempty"`
-	TenantID    string                 `json:"tenantId"`
+	Template         string                 `json:"template"`
+	To               []EmailRecipient       `json:"to"`
+	Provider         string                 `json:"provider"`
+	RefNo            string                 `json:"refno"`
+	Categories       []string               `json:"categories"`
+	Identifiers      map[string]interface{} `json:"identifiers"`
+	Params           map[string]string      `json:"params"`
+	Subject          string                 `json:"subject,omitempty"`
+	Cc               []EmailRecipient       `json:"cc,omitempty"`
+	Bcc              []EmailRecipient       `json:"bcc,omitempty"`
+	ReplyTo          *EmailRecipient        `json:"replyTo,omitempty"`
+	FromName         string                 `json:"fromName,omitempty"`
+	Headers          map[string]string      `json:"headers,omitempty"`
+	CustomArgs       map[string]string      `json:"customArgs,omitempty"`
+	Attachments      []AttachmentMetadata   `json:"attachments,omitempty"`
+	TenantID         string                 `json:"tenantId"`
+	NotificationUUID string                 `json:"notificationUuid,omitempty"`
 }
 
 // EmailRecipient represents an email recipient with name and email
@@ -22,9 +29,13 @@ type EmailRecipient struct {
 
 // EmailIdentifiers represents identifiers for an email message
 
-// AttachmentMetadata represents metadata for an email attachment
+// AttachmentMetadata represents metadata for an email attachment.
+// Attachments accepted by the API are offloaded to the blob store and only carry a StorageKey.
 type AttachmentMetadata struct {
 	Filename    string `json:"filename"`
 	ContentType string `json:"contentType"`
-	Content     string `json:"content"` // base64 encoded
+	Content     string `json:"content,omitempty"`    // base64 encoded, empty once offloaded
+	URL         string `json:"url,omitempty"`        // Fetched from an allowlisted host at send time instead of content
+	StorageKey  string `json:"storageKey,omitempty"` // Key of the attachment bytes in the blob store
+	Size        int64  `json:"size,omitempty"`
 }
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EscalationStatus represents the state of an escalation run
+type EscalationStatus string
+
+const (
+	// EscalationStatusActive indicates the escalation still has steps to execute
+	EscalationStatusActive EscalationStatus = "ACTIVE"
+
+	// EscalationStatusAcknowledged indicates a recipient acknowledged one of the escalation messages
+	EscalationStatusAcknowledged EscalationStatus = "ACKNOWLEDGED"
+
+	// EscalationStatusRead indicates a recipient read one of the escalation messages
+	EscalationStatusRead EscalationStatus = "READ"
+
+	// EscalationStatusCancelled indicates the escalation was cancelled through the API
+	EscalationStatusCancelled EscalationStatus = "CANCELLED"
+
+	// EscalationStatusCompleted indicates every step was executed without an acknowledgement
+	EscalationStatusCompleted EscalationStatus = "COMPLETED"
+)
+
+// EscalationStepStatus represents the state of a single escalation step
+type EscalationStepStatus string
+
+const (
+	// EscalationStepPending indicates the step has not been executed yet
+	EscalationStepPending EscalationStepStatus = "PENDING"
+
+	// EscalationStepInProgress indicates the scheduler claimed the step and is queueing its notification
+	EscalationStepInProgress EscalationStepStatus = "IN_PROGRESS"
+
+	// EscalationStepSent indicates the step notification was queued
+	EscalationStepSent EscalationStepStatus = "SENT"
+
+	// EscalationStepFailed indicates the step notification could not be queued
+	EscalationStepFailed EscalationStepStatus = "FAILED"
+
+	// EscalationStepCancelled indicates the step will not run because the escalation stopped
+	EscalationStepCancelled EscalationStepStatus = "CANCELLED"
+)
+
+// EscalationPolicy represents a reusable, ordered list of escalation steps
+type EscalationPolicy struct {
+	ID        uint                   `gorm:"primarykey"`
+	UUID      string                 `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID  string                 `gorm:"column:tenant_id;type:varchar(255);not null;uniqueIndex:idx_escalation_policy_tenant_code,priority:1"`
+	Code      string                 `gorm:"type:varchar(50);not null;uniqueIndex:idx_escalation_policy_tenant_code,priority:2"`
+	Name      string                 `gorm:"type:varchar(255);not null"`
+	Status    int                    `gorm:"type:smallint;not null;default:0"` // 0=inactive, 1=active
+	Steps     []EscalationPolicyStep `gorm:"foreignKey:PolicyID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt time.Time              `gorm:"autoCreateTime;not null"`
+	UpdatedAt time.Time              `gorm:"autoUpdateTime;not null"`
+}
+
+// EscalationPolicyStep represents one step of an escalation policy
+type EscalationPolicyStep struct {
+	ID           uint      `gorm:"primarykey"`
+	PolicyID     uint      `gorm:"not null;uniqueIndex:idx_escalation_policy_step_position,priority:1"`
+	Position     int       `gorm:"not null;uniqueIndex:idx_escalation_policy_step_position,priority:2"`
+	Channel      Channel   `gorm:"type:varchar(10);not null;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
+	TemplateCode string    `gorm:"type:varchar(50);not null"`
+	ProviderUUID string    `gorm:"column:provider_uuid;type:varchar(36)"` // Optional, defaults to the first active provider
+	Recipients   JSON      `gorm:"type:jsonb;not null"`                   // Recipient group, see RecipientsToJSON
+	WaitSeconds  int       `gorm:"not null;default:0"`                    // Delay after the previous step before this step runs
+	CreatedAt    time.Time `gorm:"autoCreateTime;not null"`
+}
+
+// Escalation represents a running instance of an escalation policy
+type Escalation struct {
+	ID          uint             `gorm:"primarykey"`
+	UUID        string           `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID    string           `gorm:"column:tenant_id;type:varchar(255);not null;index"`
+	PolicyUUID  string           `gorm:"column:policy_uuid;type:varchar(36);not null;index"`
+	RefNo       string           `gorm:"type:varchar(255);not null;index"`
+	Status      EscalationStatus `gorm:"type:varchar(20);not null;default:'ACTIVE';index;check:status IN ('ACTIVE', 'ACKNOWLEDGED', 'READ', 'CANCELLED', 'COMPLETED')"`
+	Reason      string           `gorm:"type:text"` // Why the escalation stopped
+	Identifiers JSON             `gorm:"type:jsonb"`
+	Categories  JSON             `gorm:"type:jsonb"`
+	Params      JSON             `gorm:"type:jsonb"`
+	Subject     string           `gorm:"type:varchar(255)"`
+	Steps       []EscalationStep `gorm:"foreignKey:EscalationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime;not null;index"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime;not null"`
+}
+
+// EscalationStep represents the execution of one policy step within an escalation
+type EscalationStep struct {
+	ID               uint                 `gorm:"primarykey"`
+	EscalationID     uint                 `gorm:"not null;index"`
+	Position         int                  `gorm:"not null"`
+	Channel          Channel              `gorm:"type:varchar(10);not null"`
+	TemplateCode     string               `gorm:"type:varchar(50);not null"`
+	ProviderUUID     string               `gorm:"column:provider_uuid;type:varchar(36)"`
+	Recipients       JSON                 `gorm:"type:jsonb;not null"`
+	WaitSeconds      int                  `gorm:"not null;default:0"`
+	Status           EscalationStepStatus `gorm:"type:varchar(20);not null;default:'PENDING';check:status IN ('PENDING', 'IN_PROGRESS', 'SENT', 'FAILED', 'CANCELLED')"`
+	Reason           string               `gorm:"type:text"`
+	DueAt            *time.Time           `gorm:"index"` // Set once the previous step has run
+	NotificationUUID string               `gorm:"column:notification_uuid;type:varchar(36);index"`
+	SentAt           *time.Time
+	CreatedAt        time.Time `gorm:"autoCreateTime;not null"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime;not null"`
+}
+
+// RecipientsToJSON stores a recipient group as an index-keyed JSON object
+func RecipientsToJSON(recipients []NotificationRecipient) (JSON, error) {
+	result := JSON{}
+	for i, recipient := range recipients {
+		data, err := json.Marshal(recipient)
+		if err != nil {
+			return nil, err
+		}
+		var value map[string]interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		result[fmt.Sprintf("%d", i)] = value
+	}
+	return result, nil
+}
+
+// RecipientsFromJSON reads a recipient group stored by RecipientsToJSON
+func RecipientsFromJSON(data JSON) []NotificationRecipient {
+	recipients := make([]NotificationRecipient, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		value, ok := data[fmt.Sprintf("%d", i)]
+		if !ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var recipient NotificationRecipient
+		if err := json.Unmarshal(raw, &recipient); err != nil {
+			continue
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// InboundMessage represents a message received from a recipient in the database
+type InboundMessage struct {
+	ID                uint      `gorm:"primarykey"`
+	UUID              string    `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID          string    `gorm:"column:tenant_id;type:varchar(255);not null;index"`
+	ProviderUUID      string    `gorm:"column:provider_uuid;type:varchar(36);not null;index"`
+	Channel           Channel   `gorm:"type:varchar(10);not null;index;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
+	From              string    `gorm:"column:from_address;type:varchar(255);not null;index"`
+	To                string    `gorm:"column:to_address;type:varchar(255);not null"`
+	Body              string    `gorm:"type:text"`
+	Media             JSON      `gorm:"type:jsonb"`                                         // Media URLs and content types keyed by index
+	ProviderMessageID string    `gorm:"column:provider_message_id;type:varchar(255);index"` // Provider message reference (e.g. Twilio MessageSid), unique per provider when set
+	MessageUUID       string    `gorm:"column:message_uuid;type:varchar(36);index"`         // Outbound message this is a reply to, if any
+	AckAction         string    `gorm:"column:ack_action;type:varchar(20)"`                 // Acknowledgement action matched from the template keywords, if any
+	Metadata          JSON      `gorm:"type:jsonb"`                                         // Raw provider fields not mapped above
+	ReceivedAt        time.Time `gorm:"not null;index"`                                     // Timestamp of when the message was received
+	CreatedAt         time.Time `gorm:"autoCreateTime;not null;index"`
+}
+
+// MessageRecipient records each address an outbound message was sent to, so replies can be correlated
+type MessageRecipient struct {
+	ID                uint      `gorm:"primarykey"`
+	MessageID         uint      `gorm:"not null;index"` // Foreign key to Message.ID
+	MessageUUID       string    `gorm:"column:message_uuid;type:varchar(36);not null;index"`
+	TenantID          string    `gorm:"column:tenant_id;type:varchar(255);not null;index:idx_message_recipients_lookup,priority:1"`
+	ProviderUUID      string    `gorm:"column:provider_uuid;type:varchar(36);not null"`
+	Channel           Channel   `gorm:"type:varchar(10);not null;index:idx_message_recipients_lookup,priority:2;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
+	Address           string    `gorm:"type:varchar(255);not null;index:idx_message_recipients_lookup,priority:3"` // Replies are matched on tenant, channel and address
+	ProviderMessageID string    `gorm:"column:provider_message_id;type:varchar(255);index"`                        // Provider message reference used to match delivery receipts (e.g. SMPP message_id)
+	CreatedAt         time.Time `gorm:"autoCreateTime;not null"`
+}
+
+// InboundMessagePayload is the message produced to the inbound topic for downstream consumers
+type InboundMessagePayload struct {
+	UUID         string                 `json:"uuid"`
+	TenantID     string                 `json:"tenantId"`
+	ProviderUUID string                 `json:"providerUuid"`
+	Channel      Channel                `json:"channel"`
+	From         string                 `json:"from"`
+	To           string                 `json:"to"`
+	Body         string                 `json:"body"`
+	Media        []InboundMedia         `json:"media,omitempty"`
+	MessageUUID  string                 `json:"messageUuid,omitempty"`
+	AckAction    string                 `json:"ackAction,omitempty"`
+	RefNo        string                 `json:"refno,omitempty"`
+	Identifiers  map[string]interface{} `json:"identifiers,omitempty"`
+	ReceivedAt   time.Time              `json:"receivedAt"`
+}
+
+// InboundMedia describes a media item attached to an inbound message
+type InboundMedia struct {
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+}
@@ -37,8 +37,17 @@ const (
 	// StatusDelivered represents message is delivered to recipient - matches EventStatusDelivered
 	StatusDelivered Status = "DELIVERED"
 
+	// StatusFailed represents the provider could not deliver the message - matches EventStatusFailed
+	StatusFailed Status = "FAILED"
+
 	// StatusOpened represents message is opened by recipient - matches EventStatusRead
 	StatusOpened Status = "READ"
+
+	// StatusSuppressed represents every recipient is on the suppression list - matches EventStatusSuppressed
+	StatusSuppressed Status = "SUPPRESSED"
+
+	// StatusAcknowledged represents a recipient acknowledged the message by reply - matches EventStatusAcknowledged
+	StatusAcknowledged Status = "ACKNOWLEDGED"
 )
 
 // JSON type for storing JSON in database
@@ -67,14 +76,17 @@ func (j *JSON) Scan(value interface{}) error {
 
 // Message represents a delivery message in the database
 type Message struct {
-	ID          uint      `gorm:"primarykey"`
-	UUID        string    `gorm:"type:varchar(36);uniqueIndex;not null"`
-	TenantID    string    `gorm:"column:tenant_id;type:varchar(100);not null;index:idx_detection_events_tenant,priority:1,sort:desc;"`
-	Channel     Channel   `gorm:"type:varchar(10);not null;index;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
-	Identifiers JSON      `gorm:"type:jsonb;not null"`
-	Categories  JSON      `gorm:"type:jsonb"`
-	RefNo       string    `gorm:"type:varchar(255);not null"`
-	Status      Status    `gorm:"type:varchar(10);default:'ACCEPTED';not null;index;check:status IN ('ACCEPTED', 'SENT', 'DELIVERED', 'REJECTED', 'READ', 'FAILED')"`
-	CreatedAt   time.Time `gorm:"autoCreateTime;not null;index"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime;not null"`
+	ID               uint      `gorm:"primarykey"`
+	UUID             string    `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID         string    `gorm:"column:tenant_id;type:varchar(100);not null;index:idx_detection_events_tenant,priority:1,sort:desc;"`
+	Channel          Channel   `gorm:"type:varchar(10);not null;index;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
+	Identifiers      JSON      `gorm:"type:jsonb;not null"`
+	Categories       JSON      `gorm:"type:jsonb"`
+	RefNo            string    `gorm:"type:varchar(255);not null"`
+	NotificationUUID string    `gorm:"column:notification_uuid;type:varchar(36);index"` // Parent notification for multi-channel sends
+	TemplateUUID     string    `gorm:"column:template_uuid;type:varchar(36);index"`     // Template used to render the message
+	TemplateVersion  int       `gorm:"column:template_version"`                         // Version of the template that was rendered, 0 before it was sent
+	Status           Status    `gorm:"type:varchar(20);default:'ACCEPTED';not null;index;check:status IN ('ACCEPTED', 'SENT', 'DELIVERED', 'REJECTED', 'READ', 'FAILED', 'SUPPRESSED', 'ACKNOWLEDGED')"`
+	CreatedAt        time.Time `gorm:"autoCreateTime;not null;index"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime;not null"`
 }
@@ -17,9 +17,21 @@ const (
 	// EventStatusSent indicates the message was sent by the provider
 	EventStatusSent MessageEventType = "SENT"
 
+	// EventStatusFailed indicates the provider reported the message could not be delivered
+	EventStatusFailed MessageEventType = "FAILED"
+
 	// Additional status types that match Status in message.go
 	EventStatusAccepted MessageEventType = "ACCEPTED"
 	EventStatusRejected MessageEventType = "REJECTED"
+
+	// EventStatusSuppressed indicates the recipient is on the suppression list and was not contacted
+	EventStatusSuppressed MessageEventType = "SUPPRESSED"
+
+	// EventStatusAcknowledged indicates a recipient replied with an acknowledgement keyword
+	EventStatusAcknowledged MessageEventType = "ACKNOWLEDGED"
+
+	// EventStatusEscalated indicates a recipient replied with an escalation keyword
+	EventStatusEscalated MessageEventType = "ESCALATED"
 )
 
 // MessageEvent represents an event related to a message in the database
@@ -27,7 +39,7 @@ type MessageEvent struct {
 	ID        uint             `gorm:"primarykey"`
 	UUID      string           `gorm:"type:varchar(36);uniqueIndex;not null"`
 	MessageID uint             `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;references:ID"` // Foreign key to Message.ID
-	Status    MessageEventType `gorm:"type:varchar(10);not null;index;check:status IN ('DELIVERED', 'FAILED', 'READ', 'SENT', 'ACCEPTED', 'REJECTED')"`
+	Status    MessageEventType `gorm:"type:varchar(20);not null;index;check:status IN ('DELIVERED', 'FAILED', 'READ', 'SENT', 'ACCEPTED', 'REJECTED', 'SUPPRESSED', 'ACKNOWLEDGED', 'ESCALATED')"`
 	Reason    string           `gorm:"type:text;column:reason"` // Reason for status change, especially for failures
 	Metadata  JSON             `gorm:"type:jsonb"`
 	Timestamp time.Time        `gorm:"not null;index"` // Timestamp of when the event occurred
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationStrategy controls how a notification is fanned out across channels
+type NotificationStrategy string
+
+const (
+	// NotificationStrategyAll sends the notification on every requested channel that can be resolved
+	NotificationStrategyAll NotificationStrategy = "ALL"
+
+	// NotificationStrategyFirstAvailable sends the notification on the first requested channel that can be resolved
+	NotificationStrategyFirstAvailable NotificationStrategy = "FIRST_AVAILABLE"
+)
+
+// Notification represents a logical multi-channel notification in the database.
+// Child messages reference it through Message.NotificationUUID.
+type Notification struct {
+	ID           uint                 `gorm:"primarykey"`
+	UUID         string               `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID     string               `gorm:"column:tenant_id;type:varchar(255);not null;index"`
+	RefNo        string               `gorm:"type:varchar(255);not null;index"`
+	TemplateCode string               `gorm:"type:varchar(50);not null"`
+	Strategy     NotificationStrategy `gorm:"type:varchar(20);not null;default:'ALL';check:strategy IN ('ALL', 'FIRST_AVAILABLE')"`
+	Channels     JSON                 `gorm:"type:jsonb;not null"`
+	Identifiers  JSON                 `gorm:"type:jsonb"`
+	Categories   JSON                 `gorm:"type:jsonb"`
+	CreatedAt    time.Time            `gorm:"autoCreateTime;not null;index"`
+	UpdatedAt    time.Time            `gorm:"autoUpdateTime;not null"`
+}
+
+// NotificationMessage represents a single multi-channel notification in the internal system
+type NotificationMessage struct {
+	Template    string                  `json:"template"` // Template code, resolved per channel
+	To          []NotificationRecipient `json:"to"`
+	Channels    []Channel               `json:"channels"`
+	Strategy    NotificationStrategy    `json:"strategy"`
+	Providers   map[Channel]string      `json:"providers"` // Optional provider UUID per channel
+	RefNo       string                  `json:"refno"`
+	TenantID    string                  `json:"tenantId"`
+	Categories  []string                `json:"categories"`
+	Identifiers map[string]interface{}  `json:"identifiers"`
+	Params      map[string]string       `json:"params"`
+	Subject     string                  `json:"subject,omitempty"`
+}
+
+// NotificationRecipient holds the contact details of a recipient across channels
+type NotificationRecipient struct {
+	Name      string `json:"name,omitempty"`
+	Telephone string `json:"telephone,omitempty"` // Used for SMS, and for WhatsApp unless WhatsApp is set
+	WhatsApp  string `json:"whatsapp,omitempty"`  // Optional WhatsApp number when it differs from Telephone
+	Email     string `json:"email,omitempty"`
+}
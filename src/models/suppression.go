@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// SuppressionReason describes why an address was suppressed
+type SuppressionReason string
+
+// SuppressionSource describes where a suppression came from
+type SuppressionSource string
+
+const (
+	// SuppressionReasonBounce indicates the address hard bounced
+	SuppressionReasonBounce SuppressionReason = "BOUNCE"
+
+	// SuppressionReasonSpamReport indicates the recipient reported a message as spam
+	SuppressionReasonSpamReport SuppressionReason = "SPAM_REPORT"
+
+	// SuppressionReasonUnsubscribe indicates the recipient unsubscribed
+	SuppressionReasonUnsubscribe SuppressionReason = "UNSUBSCRIBE"
+
+	// SuppressionReasonOptOut indicates the recipient replied with an opt-out keyword such as STOP
+	SuppressionReasonOptOut SuppressionReason = "OPT_OUT"
+
+	// SuppressionReasonManual indicates the suppression was added through the API
+	SuppressionReasonManual SuppressionReason = "MANUAL"
+)
+
+const (
+	// SuppressionSourceAPI indicates the suppression was created through the API
+	SuppressionSourceAPI SuppressionSource = "API"
+
+	// SuppressionSourceSendGrid indicates the suppression was created from a SendGrid event webhook
+	SuppressionSourceSendGrid SuppressionSource = "SENDGRID"
+
+	// SuppressionSourceInbound indicates the suppression was created from an inbound message
+	SuppressionSourceInbound SuppressionSource = "INBOUND"
+)
+
+// Suppression represents an address that must not be messaged on a channel
+type Suppression struct {
+	ID        uint              `gorm:"primarykey"`
+	UUID      string            `gorm:"type:varchar(36);uniqueIndex;not null"`
+	TenantID  string            `gorm:"column:tenant_id;type:varchar(255);not null;uniqueIndex:idx_suppression_tenant_channel_address,priority:1"`
+	Channel   Channel           `gorm:"type:varchar(10);not null;uniqueIndex:idx_suppression_tenant_channel_address,priority:2;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
+	Address   string            `gorm:"type:varchar(255);not null;uniqueIndex:idx_suppression_tenant_channel_address,priority:3;index"` // E.164 telephone number or lowercase email address
+	Reason    SuppressionReason `gorm:"type:varchar(20);not null;check:reason IN ('BOUNCE', 'SPAM_REPORT', 'UNSUBSCRIBE', 'OPT_OUT', 'MANUAL')"`
+	Source    SuppressionSource `gorm:"type:varchar(20);not null;check:source IN ('API', 'SENDGRID', 'INBOUND')"`
+	Details   string            `gorm:"type:text"` // Free-form details such as the provider bounce reason
+	CreatedAt time.Time         `gorm:"autoCreateTime;not null;index"`
+	UpdatedAt time.Time         `gorm:"autoUpdateTime;not null"`
+}
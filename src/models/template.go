@@ -12,10 +12,13 @@ type Template struct {
 	Name        string    `gorm:"type:varchar(255);not null;index"`
 	Subject     string    `gorm:"type:varchar(255)"` // Subject line for EMAIL templates
 	Content     string    `gorm:"type:text;not null"`
+	TextContent string    `gorm:"type:text"`                              // Plain-text alternative for EMAIL templates, generated from Content when empty
 	Status      int       `gorm:"type:smallint;default:0;not null;index"` // 0 for inactive, 1 for active
 	Channel     Channel   `gorm:"type:varchar(10);not null;index;check:channel IN ('WHATSAPP', 'SMS', 'EMAIL')"`
 	TemplateIds JSON      `gorm:"type:jsonb;column:template_ids"` // JSON field to store provider template IDs
+	AckKeywords JSON      `gorm:"type:jsonb;column:ack_keywords"` // Reply keyword to action, e.g. {"1": "ACK", "2": "ESCALATE"}
 	TenantID    string    `gorm:"column:tenant_id;type:varchar(255);not null;index"`
+	Version     int       `gorm:"not null;default:1"` // Current version in template_versions, increased by every content change
 	CreatedAt   time.Time `gorm:"autoCreateTime;not null;index"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime;not null"`
 
@@ -23,7 +26,37 @@ type Template struct {
 	_ struct{} `gorm:"uniqueIndex:idx_code_tenant_channel;columns:code,tenant_id,channel"`
 }
 
+// Acknowledgement actions that a reply keyword can map to
+const (
+	// AckActionAcknowledge marks the message as acknowledged by the recipient
+	AckActionAcknowledge = "ACK"
+
+	// AckActionEscalate asks for the message to be escalated
+	AckActionEscalate = "ESCALATE"
+)
+
 // TableName defines the table name for the Template model
 func (Template) TableName() string {
 	return "templates"
 }
+
+// TemplateVersion is a snapshot of the content of a template, recorded on every change to its
+// content, subject or provider template IDs
+type TemplateVersion struct {
+	ID           uint      `gorm:"primarykey"`
+	TemplateID   uint      `gorm:"not null;uniqueIndex:idx_template_versions_template_version,priority:1"` // Foreign key to Template.ID
+	TemplateUUID string    `gorm:"column:template_uuid;type:varchar(36);not null;index"`
+	Version      int       `gorm:"not null;uniqueIndex:idx_template_versions_template_version,priority:2"`
+	Subject      string    `gorm:"type:varchar(255)"`
+	Content      string    `gorm:"type:text;not null"`
+	TextContent  string    `gorm:"type:text"`
+	TemplateIds  JSON      `gorm:"type:jsonb;column:template_ids"`
+	Author       string    `gorm:"type:varchar(255)"`
+	Comment      string    `gorm:"type:varchar(255)"` // e.g. "Rolled back to version 3"
+	CreatedAt    time.Time `gorm:"autoCreateTime;not null"`
+}
+
+// TableName defines the table name for the TemplateVersion model
+func (TemplateVersion) TableName() string {
+	return "template_versions"
+}
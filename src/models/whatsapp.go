@@ -1,16 +1,21 @@
 package models
 
-// WhatsAppMessage represents a single WhatsApp message in the internal system
+// WhatsAppMessage represents a single WhatsApp message in the internal system.
+// Text and Media are free-form content that can only be delivered inside an open session,
+// Template is used when the session is closed or when no free-form content is given.
 type WhatsAppMessage struct {
-	Template    string                 `json:"template"`
-	To          []WhatsAppRecipient    `json:"to"`
-	Provider    string                 `json:"provider"`
-	RefNo       string                 `json:"refno"`
-	TenantID    string                 `json:"tenantId"`
-	Categories  []string               `json:"categories"`
-	Identifiers map[string]interface{} `json:"identifiers"`
-	Params      map[string]string      `json:"params"`
-	Attachments *WhatsAppAttachments   `json:"attachments"`
+	Template         string                 `json:"template"`
+	Text             string                 `json:"text,omitempty"`
+	Media            *WhatsAppMedia         `json:"media,omitempty"`
+	To               []WhatsAppRecipient    `json:"to"`
+	Provider         string                 `json:"provider"`
+	RefNo            string                 `json:"refno"`
+	TenantID         string                 `json:"tenantId"`
+	Categories       []string               `json:"categories"`
+	Identifiers      map[string]interface{} `json:"identifiers"`
+	Params           map[string]string      `json:"params"`
+	Attachments      *WhatsAppAttachments   `json:"attachments"`
+	NotificationUUID string                 `json:"notificationUuid,omitempty"`
 }
 
 // WhatsAppRecipient represents a recipient for a WhatsApp message
@@ -19,15 +24,26 @@ type WhatsAppRecipient struct {
 	Telephone string `json:"telephone"`
 }
 
+// WhatsAppMedia represents free-form media sent inside an open session
+type WhatsAppMedia struct {
+	URL     string `json:"url"`
+	Type    string `json:"type"` // MIME type, e.g. image/jpeg
+	Caption string `json:"caption,omitempty"`
+}
+
 // WhatsAppAttachments represents attachments for a WhatsApp message
 type WhatsAppAttachments struct {
 	Inline []WhatsAppInlineAttachment `json:"inline"`
 }
 
-// WhatsAppInlineAttachment represents an inline attachment for a WhatsApp message
+// WhatsAppInlineAttachment represents an inline attachment for a WhatsApp message.
+// Attachments accepted by the API are offloaded to the blob store and only carry a StorageKey.
 type WhatsAppInlineAttachment struct {
-	Filename  string `json:"filename"`
-	Type      string `json:"type"`
-	Content   string `json:"content"`
-	ContentID string `json:"contentId"`
+	Filename   string `json:"filename"`
+	Type       string `json:"type"`
+	Content    string `json:"content,omitempty"` // base64 encoded, empty once offloaded
+	ContentID  string `json:"contentId"`
+	URL        string `json:"url,omitempty"`        // Fetched from an allowlisted host at send time instead of content
+	StorageKey string `json:"storageKey,omitempty"` // Key of the attachment bytes in the blob store
+	Size       int64  `json:"size,omitempty"`
 }
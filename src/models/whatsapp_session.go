@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// WhatsAppSessionWindow is how long a recipient can receive free-form messages after their last inbound message
+const WhatsAppSessionWindow = 24 * time.Hour
+
+// WhatsAppSession tracks the customer-service window of a recipient on a WhatsApp sender of a tenant.
+// Free-form text and media may only be sent while the window is open, otherwise an approved template is required.
+type WhatsAppSession struct {
+	ID            uint      `gorm:"primarykey"`
+	TenantID      string    `gorm:"column:tenant_id;type:varchar(255);not null;uniqueIndex:idx_whatsapp_session_tenant_address,priority:1"`
+	ProviderUUID  string    `gorm:"column:provider_uuid;type:varchar(36);not null;uniqueIndex:idx_whatsapp_session_tenant_address,priority:2"`
+	Address       string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_whatsapp_session_tenant_address,priority:3"` // Normalized recipient number
+	LastInboundAt time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time `gorm:"autoCreateTime;not null"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime;not null"`
+}
+
+// TableName defines the table name for the WhatsAppSession model
+func (WhatsAppSession) TableName() string {
+	return "whatsapp_sessions"
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownProviderMessage is returned when a status refers to a provider message ID that was never recorded
+var ErrUnknownProviderMessage = errors.New("no message found for provider message ID")
+
+// deliveryStatusPrecedence lists the message statuses a reported status may replace,
+// so that a late DELIVERED never overwrites READ or ACKNOWLEDGED
+var deliveryStatusPrecedence = map[models.MessageEventType][]models.Status{
+	models.EventStatusDelivered: {models.StatusSent},
+	models.EventStatusRead:      {models.StatusSent, models.StatusDelivered},
+	models.EventStatusFailed:    {models.StatusSent},
+}
+
+// DeliveryStatusUpdate is a delivery status reported by a provider for a message it sent
+type DeliveryStatusUpdate struct {
+	ProviderUUID      string
+	ProviderMessageID string
+	Status            models.MessageEventType // DELIVERED, READ or FAILED
+	Reason            string
+	Metadata          models.JSON
+	Timestamp         time.Time
+}
+
+// DeliveryStatusService records provider delivery statuses as message events
+type DeliveryStatusService struct {
+	db       *gorm.DB
+	readerDB *gorm.DB
+}
+
+// NewDeliveryStatusService creates a new delivery status service
+func NewDeliveryStatusService(db *gorm.DB, readerDB *gorm.DB) (*DeliveryStatusService, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	if readerDB == nil {
+		readerDB = db
+	}
+	return &DeliveryStatusService{
+		db:       db,
+		readerDB: readerDB,
+	}, nil
+}
+
+// Record adds an event for a status to the message the provider message ID belongs to and moves the
+// message status forward. The recipient is read from the writer because statuses can arrive moments
+// after the send was recorded.
+func (s *DeliveryStatusService) Record(update DeliveryStatusUpdate) (*models.MessageRecipient, error) {
+	if _, ok := deliveryStatusPrecedence[update.Status]; !ok {
+		return nil, fmt.Errorf("unsupported delivery status: %s", update.Status)
+	}
+
+	var recipient models.MessageRecipient
+	if err := s.db.Where("provider_uuid = ? AND provider_message_id = ?", update.ProviderUUID, update.ProviderMessageID).
+		First(&recipient).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownProviderMessage
+		}
+		return nil, fmt.Errorf("failed to fetch message recipient: %v", err)
+	}
+
+	metadata := models.JSON{}
+	for key, value := range update.Metadata {
+		metadata[key] = value
+	}
+	metadata["address"] = recipient.Address
+	metadata["providerMessageId"] = update.ProviderMessageID
+
+	timestamp := update.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+
+	event := models.MessageEvent{
+		MessageID: recipient.MessageID,
+		Status:    update.Status,
+		Reason:    update.Reason,
+		Metadata:  metadata,
+		Timestamp: timestamp,
+	}
+	if err := helper.InsertMessageEvent(s.db, event); err != nil {
+		return nil, fmt.Errorf("failed to create delivery status event: %v", err)
+	}
+
+	if err := s.db.Model(&models.Message{}).
+		Where("id = ? AND status IN ?", recipient.MessageID, deliveryStatusPrecedence[update.Status]).
+		Update("status", models.Status(update.Status)).Error; err != nil {
+		return nil, fmt.Errorf("failed to update message status: %v", err)
+	}
+
+	return &recipient, nil
+}
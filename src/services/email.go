@@ -6,11 +6,11 @@ import (
 
 // EmailService defines operations for sending emails
 type EmailService interface {
-	// Send an email to a list of recipients
-	Send(to []string, subject string, body string, isHTML bool) error
+	// Send an email to a list of recipients, options may be nil
+	Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error
 
 	// Send an email with attachments to a list of recipients
-	SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment) error
+	SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error
 
 	// Get email delivery status by message ID
 	GetStatus(messageID string) (types.DeliveryStatus, error)
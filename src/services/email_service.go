@@ -4,7 +4,7 @@ import (
 	"delivery/api/types"
 	"delivery/helper"
 	"delivery/models"
-	"delivery/services/providers/email"
+	"delivery/services/storage"
 	"errors"
 	"fmt"
 	"time"
@@ -12,36 +12,46 @@ import (
 	"gorm.io/gorm"
 )
 
+// EmailProviderFactory creates the email provider implementation configured on a provider record
+type EmailProviderFactory func(provider *models.Provider) (EmailService, error)
+
 // EmailServiceImpl implements the EmailService interface
 type EmailServiceImpl struct {
-	db *gorm.DB
+	db          *gorm.DB
+	newProvider EmailProviderFactory
+	store       storage.Store
+	fetcher     *storage.Fetcher
 }
 
-// NewEmailService creates a new email service
-func NewEmailService(db *gorm.DB) (*EmailServiceImpl, error) {
+// NewEmailService creates a new email service that sends through providers created by newProvider
+func NewEmailService(db *gorm.DB, newProvider EmailProviderFactory) (*EmailServiceImpl, error) {
 	if db == nil {
 		return nil, errors.New("database connection cannot be nil")
 	}
+	if newProvider == nil {
+		return nil, errors.New("email provider factory cannot be nil")
+	}
+	store, err := storage.NewStoreFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment store: %w", err)
+	}
 	return &EmailServiceImpl{
-		db: db,
+		db:          db,
+		newProvider: newProvider,
+		store:       store,
+		fetcher:     storage.NewFetcherFromEnv(),
 	}, nil
 }
 
-// SendEmail sends an email message based on a template
-func (s *EmailServiceImpl) SendEmail(message *models.EmailMessage) error {
+// SendEmail sends an email message rendered from template. The caller passes the template it recorded
+// on the message, so the content sent matches the recorded template version.
+func (s *EmailServiceImpl) SendEmail(message *models.EmailMessage, template *models.Template) error {
 	logger := helper.Log.WithFields(map[string]interface{}{
 		"template": message.Template,
 		"provider": message.Provider,
 		"refNo":    message.RefNo,
 	})
 
-	// Fetch the template
-	var template models.Template
-	if err := s.db.Where("uuid = ? AND channel = ?", message.Template, models.ChannelEmail).First(&template).Error; err != nil {
-		logger.WithError(err).Error("Failed to find template")
-		return fmt.Errorf("template not found: %w", err)
-	}
-
 	// Fetch the provider
 	var provider models.Provider
 	if err := s.db.Where("uuid = ? AND channel = ?", message.Provider, models.ChannelEmail).First(&provider).Error; err != nil {
@@ -50,7 +60,7 @@ func (s *EmailServiceImpl) SendEmail(message *models.EmailMessage) error {
 	}
 
 	// Create the email provider service
-	emailProvider, err := email.NewSendGridProviderFromDB(&provider)
+	emailProvider, err := s.newProvider(&provider)
 	if err != nil {
 		logger.WithError(err).Error("Failed to create email provider")
 		return fmt.Errorf("failed to initialize provider: %w", err)
@@ -62,39 +72,13 @@ func (s *EmailServiceImpl) SendEmail(message *models.EmailMessage) error {
 		"params":          message.Params,
 	}).Debug("Processing template with params")
 
-	// Process the template content with params
-	processedContent, err := helper.ProcessTemplate(template.Content, message.Params)
+	// Render the subject and bodies the same way a template preview does
+	rendered, err := RenderEmail(template, message, logger)
 	if err != nil {
-		// Use debug template to get more info about the error
-		debugInfo := helper.DebugTemplate(template.Content, message.Params)
-		logger.WithError(err).WithField("debugInfo", debugInfo).Error("Failed to process template")
-		return fmt.Errorf("failed to process template: %w", err)
-	}
-
-	// Process the subject with params if provided
-	subject := message.Subject
-	if subject == "" && message.Params != nil {
-		subject = message.Params["subject"]
-	}
-
-	// Use template subject if still empty
-	if subject == "" && template.Subject != "" {
-		// Process the template subject with params
-		subject, err = helper.ProcessTemplate(template.Subject, message.Params)
-		if err != nil {
-			logger.WithError(err).Warn("Failed to process template subject, using raw template subject")
-			subject = template.Subject
-		}
-	}
-
-	// Hardcode a default subject if still empty to satisfy SendGrid requirements
-	if subject == "" {
-		subject = template.Name
-		if subject == "" {
-			subject = "Notification"
-		}
-		logger.WithField("defaultSubject", subject).Info("Using default subject for email")
+		return err
 	}
+	subject := rendered.Subject
+	processedContent := rendered.HTMLBody
 
 	// Extract recipient emails
 	recipients := make([]string, len(message.To))
@@ -102,47 +86,113 @@ func (s *EmailServiceImpl) SendEmail(message *models.EmailMessage) error {
 		recipients[i] = recipient.Email
 	}
 
+	options := emailOptions(message)
+	options.TextBody = rendered.TextBody
+
 	// Log detailed message information
 	logger.WithFields(map[string]interface{}{
-		"recipients":       recipients,
-		"subject":          subject,
-		"hasAttachments":   len(message.Attachments) > 0,
-		"templateContent":  template.Content,
-		"processedContent": processedContent,
-		"providerConfig":   provider.Config,
+		"recipients":     recipients,
+		"cc":             len(options.Cc),
+		"bcc":            len(options.Bcc),
+		"subject":        subject,
+		"hasAttachments": len(message.Attachments) > 0,
 	}).Info("Preparing to send email")
 
 	// If there are attachments, send with attachments
 	if len(message.Attachments) > 0 {
+		limits := storage.LimitsForChannel(models.ChannelEmail)
 		attachments := make([]types.EmailAttachment, len(message.Attachments))
+		filenames := make([]string, len(message.Attachments))
+		sizes := make([]int64, len(message.Attachments))
 		for i, att := range message.Attachments {
-			decodedContent, err := helper.DecodeBase64(att.Content)
+			object, err := s.attachmentContent(att, limits.MaxFileSize)
 			if err != nil {
-				logger.WithError(err).Error("Failed to decode attachment content")
-				return fmt.Errorf("failed to decode attachment: %w", err)
+				logger.WithError(err).Error("Failed to load attachment content")
+				return err
 			}
 			attachments[i] = types.EmailAttachment{
 				Filename:    att.Filename,
-				ContentType: att.ContentType,
-				Content:     decodedContent,
+				ContentType: object.ContentType,
+				Content:     object.Data,
 			}
+			filenames[i] = att.Filename
+			sizes[i] = int64(len(object.Data))
+		}
+
+		// Attachments fetched by URL are only known in size now
+		if err := limits.Check(models.ChannelEmail, filenames, sizes); err != nil {
+			logger.WithError(err).Error("Email attachments exceed the size limits")
+			return err
 		}
-		return emailProvider.SendWithAttachments(recipients, subject, processedContent, true, attachments)
+		return CallProvider(provider.UUID, func() error {
+			return emailProvider.SendWithAttachments(recipients, subject, processedContent, true, attachments, options)
+		})
 	}
 
 	// Send the email without attachments
-	return emailProvider.Send(recipients, subject, processedContent, true)
+	return CallProvider(provider.UUID, func() error {
+		return emailProvider.Send(recipients, subject, processedContent, true, options)
+	})
+}
+
+// emailOptions collects the optional envelope settings of a message for the provider
+func emailOptions(message *models.EmailMessage) *types.EmailOptions {
+	options := &types.EmailOptions{
+		FromName:   message.FromName,
+		Headers:    message.Headers,
+		Categories: message.Categories,
+		CustomArgs: message.CustomArgs,
+	}
+	for _, recipient := range message.Cc {
+		options.Cc = append(options.Cc, types.EmailAddress{Email: recipient.Email, Name: recipient.Name})
+	}
+	for _, recipient := range message.Bcc {
+		options.Bcc = append(options.Bcc, types.EmailAddress{Email: recipient.Email, Name: recipient.Name})
+	}
+	if message.ReplyTo != nil && message.ReplyTo.Email != "" {
+		options.ReplyTo = &types.EmailAddress{Email: message.ReplyTo.Email, Name: message.ReplyTo.Name}
+	}
+	return options
+}
+
+// attachmentContent returns the bytes of an attachment, loading offloaded attachments from the store
+// and fetching referenced attachments from their URL
+func (s *EmailServiceImpl) attachmentContent(att models.AttachmentMetadata, maxSize int64) (*storage.Object, error) {
+	switch {
+	case att.StorageKey != "":
+		object, err := s.store.Get(att.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load attachment %s: %w", att.Filename, err)
+		}
+		return &storage.Object{Data: object.Data, ContentType: att.ContentType}, nil
+
+	case att.URL != "":
+		object, err := s.fetcher.Fetch(att.URL, att.ContentType, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		if att.ContentType != "" {
+			object.ContentType = att.ContentType
+		}
+		return object, nil
+	}
+
+	content, err := helper.DecodeBase64(att.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attachment: %w", err)
+	}
+	return &storage.Object{Data: content, ContentType: att.ContentType}, nil
 }
 
 // Send implements the EmailService Send method
-func (s *EmailServiceImpl) Send(to []string, subject string, body string, isHTML bool) error {
+func (s *EmailServiceImpl) Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error {
 	// This is a placeholder implementation that would typically use the default provider
 	// For now, we'll return an error suggesting to use SendEmail instead
 	return errors.New("direct Send method not implemented, use SendEmail instead")
 }
 
 // SendWithAttachments implements the EmailService SendWithAttachments method
-func (s *EmailServiceImpl) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment) error {
+func (s *EmailServiceImpl) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error {
 	// This is a placeholder implementation that would typically use the default provider
 	// For now, we'll return an error suggesting to use SendEmail instead
 	return errors.New("direct SendWithAttachments method not implemented, use SendEmail instead")
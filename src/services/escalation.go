@@ -0,0 +1,150 @@
+package services
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EscalationService manages the lifecycle of running escalations
+type EscalationService struct {
+	db       *gorm.DB
+	readerDB *gorm.DB
+}
+
+// NewEscalationService creates a new escalation service
+func NewEscalationService(db *gorm.DB, readerDB *gorm.DB) (*EscalationService, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	if readerDB == nil {
+		readerDB = db
+	}
+	return &EscalationService{
+		db:       db,
+		readerDB: readerDB,
+	}, nil
+}
+
+// AnsweredStatus reports whether any message sent by the escalation was acknowledged or read.
+// It returns an empty status when no recipient has reacted yet.
+func (s *EscalationService) AnsweredStatus(tx *gorm.DB, escalationID uint) (models.EscalationStatus, error) {
+	var statuses []string
+	err := tx.Table("message_events").
+		Joins("JOIN messages ON messages.id = message_events.message_id").
+		Joins("JOIN escalation_steps ON escalation_steps.notification_uuid = messages.notification_uuid").
+		Where("escalation_steps.escalation_id = ?", escalationID).
+		Where("message_events.status IN ?", []models.MessageEventType{models.EventStatusAcknowledged, models.EventStatusRead}).
+		Distinct("message_events.status").
+		Pluck("message_events.status", &statuses).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to check escalation messages: %w", err)
+	}
+
+	// An explicit acknowledgement takes precedence over a read receipt
+	answered := models.EscalationStatus("")
+	for _, status := range statuses {
+		switch models.MessageEventType(status) {
+		case models.EventStatusAcknowledged:
+			return models.EscalationStatusAcknowledged, nil
+		case models.EventStatusRead:
+			answered = models.EscalationStatusRead
+		}
+	}
+	return answered, nil
+}
+
+// Stop ends an escalation and cancels the steps that have not run yet
+func (s *EscalationService) Stop(tx *gorm.DB, escalation *models.Escalation, status models.EscalationStatus, reason string) error {
+	if err := tx.Model(escalation).Updates(map[string]interface{}{
+		"status": status,
+		"reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update escalation status: %w", err)
+	}
+
+	if err := tx.Model(&models.EscalationStep{}).
+		Where("escalation_id = ? AND status = ?", escalation.ID, models.EscalationStepPending).
+		Updates(map[string]interface{}{
+			"status": models.EscalationStepCancelled,
+			"reason": reason,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to cancel escalation steps: %w", err)
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"escalation_uuid": escalation.UUID,
+		"status":          status,
+		"reason":          reason,
+	}).Info("Escalation stopped")
+	return nil
+}
+
+// Cancel stops an active escalation on request of the caller
+func (s *EscalationService) Cancel(uuid string) (*models.Escalation, error) {
+	var escalation models.Escalation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("uuid = ?", uuid).First(&escalation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("escalation not found")
+			}
+			return fmt.Errorf("failed to fetch escalation: %w", err)
+		}
+		if escalation.Status != models.EscalationStatusActive {
+			return errors.New("escalation is not active")
+		}
+		return s.Stop(tx, &escalation, models.EscalationStatusCancelled, "Cancelled through the API")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &escalation, nil
+}
+
+// HandleReply applies an acknowledgement keyword reply to the escalation that sent the notification.
+// An ACK stops the escalation, an ESCALATE makes the next step due immediately.
+func (s *EscalationService) HandleReply(notificationUUID string, action string, from string) error {
+	var step models.EscalationStep
+	if err := s.readerDB.Where("notification_uuid = ?", notificationUUID).First(&step).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The notification was not sent by an escalation
+			return nil
+		}
+		return fmt.Errorf("failed to fetch escalation step: %w", err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var escalation models.Escalation
+		if err := tx.Where("id = ?", step.EscalationID).First(&escalation).Error; err != nil {
+			return fmt.Errorf("failed to fetch escalation: %w", err)
+		}
+		if escalation.Status != models.EscalationStatusActive {
+			return nil
+		}
+
+		switch action {
+		case models.AckActionAcknowledge:
+			return s.Stop(tx, &escalation, models.EscalationStatusAcknowledged, fmt.Sprintf("Acknowledged by %s", from))
+		case models.AckActionEscalate:
+			now := time.Now()
+			result := tx.Model(&models.EscalationStep{}).
+				Where("escalation_id = ? AND status = ? AND position > ?", escalation.ID, models.EscalationStepPending, step.Position).
+				Where("position = (?)", tx.Model(&models.EscalationStep{}).
+					Select("MIN(position)").
+					Where("escalation_id = ? AND status = ? AND position > ?", escalation.ID, models.EscalationStepPending, step.Position)).
+				Update("due_at", now)
+			if result.Error != nil {
+				return fmt.Errorf("failed to advance escalation: %w", result.Error)
+			}
+			helper.Log.WithFields(map[string]interface{}{
+				"escalation_uuid": escalation.UUID,
+				"advanced":        result.RowsAffected,
+			}).Info("Escalation advanced by reply")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// keyRotationBatchSize is the number of providers re-encrypted per batch
+const keyRotationBatchSize = 100
+
+// KeyRotationResult summarizes a re-encryption of provider secure configs
+type KeyRotationResult struct {
+	KeyID   string               `json:"keyId"`
+	Total   int                  `json:"total"`
+	Rotated int                  `json:"rotated"`
+	Skipped int                  `json:"skipped"` // Already encrypted with the current key using AES-GCM, or nothing to encrypt
+	Failed  []KeyRotationFailure `json:"failed"`
+}
+
+// KeyRotationFailure is a provider whose secure config could not be re-encrypted
+type KeyRotationFailure struct {
+	ProviderUUID string `json:"providerUuid"`
+	Error        string `json:"error"`
+}
+
+// KeyRotationService re-encrypts stored secrets with the current encryption key
+type KeyRotationService struct {
+	db       *gorm.DB
+	readerDB *gorm.DB
+}
+
+// NewKeyRotationService creates a new key rotation service
+func NewKeyRotationService(db *gorm.DB, readerDB *gorm.DB) (*KeyRotationService, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	if readerDB == nil {
+		readerDB = db
+	}
+	return &KeyRotationService{
+		db:       db,
+		readerDB: readerDB,
+	}, nil
+}
+
+// RotateProviderSecrets re-encrypts every provider secure config that is not encrypted with the current key
+// or still uses legacy AES-CFB.
+// With force every secure config is re-encrypted, which also replaces its nonce. A provider that fails is
+// reported and left as it was, so the rotation can be repeated once its key is configured.
+func (s *KeyRotationService) RotateProviderSecrets(force bool) (*KeyRotationResult, error) {
+	return s.rotateProviderSecrets(force, false)
+}
+
+// MigrateLegacyProviderSecrets re-encrypts the provider secure configs still stored with legacy AES-CFB
+// using AES-GCM, whether or not ALLOW_LEGACY_CFB is set
+func (s *KeyRotationService) MigrateLegacyProviderSecrets() (*KeyRotationResult, error) {
+	return s.rotateProviderSecrets(false, true)
+}
+
+// rotateProviderSecrets re-encrypts the provider secure configs, legacyCFB allows decrypting AES-CFB ones
+func (s *KeyRotationService) rotateProviderSecrets(force bool, legacyCFB bool) (*KeyRotationResult, error) {
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"component": "KeyRotationService",
+		"force":     force,
+	})
+
+	keyring, err := helper.LoadKeyring()
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key configuration: %w", err)
+	}
+	if legacyCFB {
+		keyring.AllowLegacyCFB()
+	}
+	if keyring.CurrentKeyID() == "" {
+		return nil, errors.New("no encryption key configured, set ENCRYPTION_KEY or ENCRYPTION_KEYS")
+	}
+
+	result := &KeyRotationResult{
+		KeyID:  keyring.CurrentKeyID(),
+		Failed: []KeyRotationFailure{},
+	}
+
+	// The writer is read as well so rows re-encrypted moments ago are not picked up again from a lagging replica
+	var batch []models.Provider
+	err = s.db.Select("id", "uuid", "secure_config").Order("id").
+		FindInBatches(&batch, keyRotationBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, provider := range batch {
+				result.Total++
+
+				if _, encrypted := provider.SecureConfig["encrypted"]; !encrypted ||
+					(!force && helper.SecureConfigKeyID(provider.SecureConfig) == keyring.CurrentKeyID() &&
+						helper.SecureConfigVersion(provider.SecureConfig) == helper.SecureConfigVersionGCM) {
+					result.Skipped++
+					continue
+				}
+
+				if err := s.rotate(keyring, &provider); err != nil {
+					logger.WithError(err).WithField("providerUuid", provider.UUID).Error("Failed to re-encrypt provider secure config")
+					result.Failed = append(result.Failed, KeyRotationFailure{ProviderUUID: provider.UUID, Error: err.Error()})
+					continue
+				}
+				result.Rotated++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return result, fmt.Errorf("failed to fetch providers: %v", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"keyId":   result.KeyID,
+		"total":   result.Total,
+		"rotated": result.Rotated,
+		"skipped": result.Skipped,
+		"failed":  len(result.Failed),
+	}).Info("Provider secure configs re-encrypted")
+	return result, nil
+}
+
+// rotate decrypts a provider secure config and stores it encrypted with the current key
+func (s *KeyRotationService) rotate(keyring *helper.Keyring, provider *models.Provider) error {
+	plaintext, err := keyring.Decrypt(provider.SecureConfig)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := keyring.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+
+	// updated_at is left alone, the configuration itself did not change
+	if err := s.db.Model(&models.Provider{}).Where("id = ?", provider.ID).
+		UpdateColumn("secure_config", models.JSON(encrypted)).Error; err != nil {
+		return fmt.Errorf("failed to update provider: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"database/sql/driver"
+	"delivery/helper"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testKeyOne   = "11111111111111111111111111111111"
+	testKeyOther = "33333333333333333333333333333333"
+)
+
+// newMockDB returns a gorm connection backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// encryptCFB encrypts plaintext the way secure configs were stored before AES-GCM
+func encryptCFB(t *testing.T, plaintext string, key string) string {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+	copy(ciphertext, "0123456789abcdef")
+	cipher.NewCFBEncrypter(block, ciphertext[:aes.BlockSize]).XORKeyStream(ciphertext[aes.BlockSize:], []byte(plaintext))
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// secureConfigJSON marshals a stored secure config column
+func secureConfigJSON(t *testing.T, secureConfig map[string]interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(secureConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// gcmSecureConfig matches an updated secure config column that is AES-GCM encrypted with the
+// current key and decrypts to plaintext
+type gcmSecureConfig struct {
+	keyring   *helper.Keyring
+	plaintext string
+}
+
+func (m gcmSecureConfig) Match(value driver.Value) bool {
+	data, ok := value.([]byte)
+	if !ok {
+		return false
+	}
+	var secureConfig map[string]interface{}
+	if err := json.Unmarshal(data, &secureConfig); err != nil {
+		return false
+	}
+	if helper.SecureConfigVersion(secureConfig) != helper.SecureConfigVersionGCM ||
+		helper.SecureConfigKeyID(secureConfig) != m.keyring.CurrentKeyID() {
+		return false
+	}
+	plaintext, err := m.keyring.Decrypt(secureConfig)
+	return err == nil && string(plaintext) == m.plaintext
+}
+
+func TestMigrateLegacyProviderSecrets(t *testing.T) {
+	const plaintext = `{"authToken":"secret"}`
+
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("ENCRYPTION_KEYS", "one:"+testKeyOne)
+	t.Setenv("ENCRYPTION_KEY_ID", "one")
+	t.Setenv("DEV_MODE", "")
+	t.Setenv("ALLOW_LEGACY_CFB", "")
+
+	keyring, err := helper.LoadKeyring()
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	current, err := keyring.Encrypt([]byte(plaintext))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		secureConfig map[string]interface{}
+		wantRotated  int
+		wantSkipped  int
+		wantFailed   int
+	}{
+		{
+			name:         "legacy CFB is converted to AES-GCM",
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne)},
+			wantRotated:  1,
+		},
+		{
+			name:         "legacy CFB with a key ID is converted to AES-GCM",
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOne), "keyId": "one"},
+			wantRotated:  1,
+		},
+		{
+			name:         "AES-GCM with the current key is skipped",
+			secureConfig: current,
+			wantSkipped:  1,
+		},
+		{
+			name:         "empty secure config is skipped",
+			secureConfig: map[string]interface{}{},
+			wantSkipped:  1,
+		},
+		{
+			name:         "legacy CFB with an unknown key is reported",
+			secureConfig: map[string]interface{}{"encrypted": encryptCFB(t, plaintext, testKeyOther)},
+			wantFailed:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT "id","uuid","secure_config" FROM "providers"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "secure_config"}).
+					AddRow(1, "provider-uuid", secureConfigJSON(t, tt.secureConfig)))
+			if tt.wantRotated > 0 {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "providers" SET "secure_config"=\$1 WHERE id = \$2`).
+					WithArgs(gcmSecureConfig{keyring: keyring, plaintext: plaintext}, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			service, err := NewKeyRotationService(db, db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := service.MigrateLegacyProviderSecrets()
+			if err != nil {
+				t.Fatalf("MigrateLegacyProviderSecrets() error = %v", err)
+			}
+
+			if result.Rotated != tt.wantRotated || result.Skipped != tt.wantSkipped || len(result.Failed) != tt.wantFailed {
+				t.Errorf("MigrateLegacyProviderSecrets() = rotated %d, skipped %d, failed %d, want %d, %d, %d",
+					result.Rotated, result.Skipped, len(result.Failed), tt.wantRotated, tt.wantSkipped, tt.wantFailed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRotateProviderSecretsRejectsLegacyCFB(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("ENCRYPTION_KEYS", "one:"+testKeyOne)
+	t.Setenv("ENCRYPTION_KEY_ID", "one")
+	t.Setenv("DEV_MODE", "")
+	t.Setenv("ALLOW_LEGACY_CFB", "")
+
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT "id","uuid","secure_config" FROM "providers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "secure_config"}).
+			AddRow(1, "provider-uuid", secureConfigJSON(t, map[string]interface{}{"encrypted": encryptCFB(t, `{}`, testKeyOne)})))
+
+	service, err := NewKeyRotationService(db, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.RotateProviderSecrets(false)
+	if err != nil {
+		t.Fatalf("RotateProviderSecrets() error = %v", err)
+	}
+	if len(result.Failed) != 1 || result.Rotated != 0 {
+		t.Errorf("RotateProviderSecrets() = rotated %d, failed %d, want 0 and 1 without ALLOW_LEGACY_CFB", result.Rotated, len(result.Failed))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"delivery/helper"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	helper.InitLogger()
+	helper.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
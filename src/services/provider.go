@@ -0,0 +1,7 @@
+package services
+
+// CredentialChecker is implemented by providers that can verify their configuration without sending a message
+type CredentialChecker interface {
+	// CheckCredentials makes a harmless request that fails when the provider rejects the credentials
+	CheckCredentials() error
+}
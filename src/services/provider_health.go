@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"delivery/helper"
+	"errors"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling a provider while its circuit is open
+var ErrCircuitOpen = errors.New("provider circuit open")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"    // Calls go through
+	CircuitOpen     = "open"      // Calls are refused until the open duration has passed
+	CircuitHalfOpen = "half_open" // One trial call decides whether the circuit closes or opens again
+)
+
+const (
+	// defaultCircuitFailureThreshold is the number of consecutive transient failures that opens a circuit
+	defaultCircuitFailureThreshold = 5
+
+	// defaultCircuitOpenDuration is how long an open circuit refuses calls before a trial call
+	defaultCircuitOpenDuration = 30 * time.Second
+
+	// healthBuckets is the number of one-minute buckets kept for the rolling statistics
+	healthBuckets = 15
+)
+
+// healthWindows are the rolling windows reported, in minutes
+var healthWindows = []int{1, 5, 15}
+
+// statusCodePattern finds the HTTP status code in provider API errors
+var statusCodePattern = regexp.MustCompile(`status code: (\d{3})`)
+
+// ProviderHealth is the circuit state and rolling statistics of a provider on this instance
+type ProviderHealth struct {
+	ProviderUUID        string                 `json:"providerUuid"`
+	State               string                 `json:"state"`
+	ConsecutiveFailures int                    `json:"consecutiveFailures"`
+	OpenedAt            *time.Time             `json:"openedAt,omitempty"`
+	RetryAt             *time.Time             `json:"retryAt,omitempty"` // When an open circuit lets a trial call through
+	LastSuccessAt       *time.Time             `json:"lastSuccessAt,omitempty"`
+	LastFailureAt       *time.Time             `json:"lastFailureAt,omitempty"`
+	LastError           string                 `json:"lastError,omitempty"`
+	Windows             []ProviderHealthWindow `json:"windows"`
+}
+
+// ProviderHealthWindow holds the provider calls of a rolling window
+type ProviderHealthWindow struct {
+	Window            string   `json:"window"`
+	Calls             int      `json:"calls"`
+	Successes         int      `json:"successes"`
+	Failures          int      `json:"failures"`
+	TransientFailures int      `json:"transientFailures"` // Failures that count towards opening the circuit
+	SuccessRate       *float64 `json:"successRate"`       // Null without calls
+	AvgLatencyMs      int64    `json:"avgLatencyMs"`
+}
+
+// healthBucket counts the calls of one minute
+type healthBucket struct {
+	minute    int64
+	calls     int
+	successes int
+	failures  int
+	transient int
+	latency   time.Duration
+}
+
+// providerCircuit is the circuit breaker and statistics of one provider
+type providerCircuit struct {
+	state               string
+	consecutiveFailures int
+	trialInFlight       bool
+	openedAt            time.Time
+	retryAt             time.Time
+	lastSuccessAt       time.Time
+	lastFailureAt       time.Time
+	lastError           string
+	buckets             [healthBuckets]healthBucket
+}
+
+// providerCircuits holds the circuits of the providers used by this instance, keyed by provider UUID
+var providerCircuits = struct {
+	sync.Mutex
+	circuits map[string]*providerCircuit
+}{circuits: map[string]*providerCircuit{}}
+
+// circuitSettings holds the breaker configuration read from the environment
+var circuitSettings struct {
+	sync.Once
+	failureThreshold int
+	openDuration     time.Duration
+}
+
+// loadCircuitSettings reads PROVIDER_CIRCUIT_FAILURE_THRESHOLD and PROVIDER_CIRCUIT_OPEN_DURATION once
+func loadCircuitSettings() {
+	circuitSettings.Do(func() {
+		circuitSettings.failureThreshold = defaultCircuitFailureThreshold
+		if value := helper.GetEnv("PROVIDER_CIRCUIT_FAILURE_THRESHOLD", ""); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				helper.Log.Warnf("Invalid value for PROVIDER_CIRCUIT_FAILURE_THRESHOLD, using default of %d", defaultCircuitFailureThreshold)
+			} else {
+				circuitSettings.failureThreshold = parsed
+			}
+		}
+
+		circuitSettings.openDuration = defaultCircuitOpenDuration
+		if value := helper.GetEnv("PROVIDER_CIRCUIT_OPEN_DURATION", ""); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				helper.Log.Warnf("Invalid value for PROVIDER_CIRCUIT_OPEN_DURATION, using default of %s", defaultCircuitOpenDuration)
+			} else {
+				circuitSettings.openDuration = parsed
+			}
+		}
+	})
+}
+
+// circuitFor returns the circuit of a provider, the caller holds the lock
+func circuitFor(providerUUID string) *providerCircuit {
+	circuit, ok := providerCircuits.circuits[providerUUID]
+	if !ok {
+		circuit = &providerCircuit{state: CircuitClosed}
+		providerCircuits.circuits[providerUUID] = circuit
+	}
+	return circuit
+}
+
+// CallProvider calls a provider through its circuit breaker and records the outcome and latency.
+// While the circuit is open fn is not called and ErrCircuitOpen is returned.
+func CallProvider(providerUUID string, fn func() error) error {
+	if err := acquireProviderCall(providerUUID, time.Now()); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := fn()
+	RecordProviderCall(providerUUID, err, time.Since(start))
+	return err
+}
+
+// ProviderAvailable reports whether the circuit of a provider lets calls through, consumers check it
+// before taking on a message so it is delayed instead of failed while the provider is down
+func ProviderAvailable(providerUUID string) bool {
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+
+	circuit, ok := providerCircuits.circuits[providerUUID]
+	if !ok {
+		return true
+	}
+	switch circuit.state {
+	case CircuitOpen:
+		return !time.Now().Before(circuit.retryAt)
+	case CircuitHalfOpen:
+		return !circuit.trialInFlight
+	}
+	return true
+}
+
+// acquireProviderCall checks the circuit of a provider lets a call through, an open circuit whose open
+// duration has passed lets a single trial call through
+func acquireProviderCall(providerUUID string, now time.Time) error {
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+
+	circuit := circuitFor(providerUUID)
+	switch circuit.state {
+	case CircuitOpen:
+		if now.Before(circuit.retryAt) {
+			return ErrCircuitOpen
+		}
+		circuit.state = CircuitHalfOpen
+		circuit.trialInFlight = true
+	case CircuitHalfOpen:
+		if circuit.trialInFlight {
+			return ErrCircuitOpen
+		}
+		circuit.trialInFlight = true
+	}
+	return nil
+}
+
+// RecordProviderCall records the outcome of a provider call. Transient failures (timeouts, connection
+// errors, 5xx and 429 responses) open the circuit once the threshold is reached, other failures show the
+// provider is reachable and do not.
+func RecordProviderCall(providerUUID string, err error, latency time.Duration) {
+	loadCircuitSettings()
+	now := time.Now()
+	transient := err != nil && IsTransientProviderError(err)
+
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+
+	circuit := circuitFor(providerUUID)
+	bucket := circuit.bucket(now)
+	bucket.calls++
+	bucket.latency += latency
+
+	if err == nil {
+		bucket.successes++
+		circuit.lastSuccessAt = now
+	} else {
+		bucket.failures++
+		circuit.lastFailureAt = now
+		circuit.lastError = err.Error()
+	}
+
+	if !transient {
+		if circuit.state != CircuitClosed {
+			helper.Log.WithField("providerUUID", providerUUID).Info("Provider circuit closed")
+		}
+		circuit.state = CircuitClosed
+		circuit.consecutiveFailures = 0
+		circuit.trialInFlight = false
+		return
+	}
+
+	bucket.transient++
+	circuit.consecutiveFailures++
+	if circuit.state == CircuitHalfOpen || circuit.consecutiveFailures >= circuitSettings.failureThreshold {
+		if circuit.state != CircuitOpen {
+			circuit.openedAt = now
+		}
+		circuit.state = CircuitOpen
+		circuit.retryAt = now.Add(circuitSettings.openDuration)
+		circuit.trialInFlight = false
+		helper.Log.WithFields(map[string]interface{}{
+			"providerUUID":        providerUUID,
+			"consecutiveFailures": circuit.consecutiveFailures,
+			"retryAt":             circuit.retryAt.Format(time.RFC3339),
+		}).WithError(err).Warn("Provider circuit opened")
+	}
+}
+
+// bucket returns the statistics bucket of the minute of now, resetting it when it held an older minute
+func (c *providerCircuit) bucket(now time.Time) *healthBucket {
+	minute := now.Unix() / 60
+	bucket := &c.buckets[minute%healthBuckets]
+	if bucket.minute != minute {
+		*bucket = healthBucket{minute: minute}
+	}
+	return bucket
+}
+
+// GetProviderHealth returns the circuit state and rolling statistics of a provider. Providers this
+// instance has not called yet are reported as closed without calls.
+func GetProviderHealth(providerUUID string) ProviderHealth {
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+
+	now := time.Now()
+	health := ProviderHealth{
+		ProviderUUID: providerUUID,
+		State:        CircuitClosed,
+		Windows:      make([]ProviderHealthWindow, 0, len(healthWindows)),
+	}
+
+	circuit, ok := providerCircuits.circuits[providerUUID]
+	if !ok {
+		circuit = &providerCircuit{state: CircuitClosed}
+	}
+	health.State = circuit.state
+	health.ConsecutiveFailures = circuit.consecutiveFailures
+	health.OpenedAt = timePointer(circuit.openedAt, circuit.state != CircuitClosed)
+	health.RetryAt = timePointer(circuit.retryAt, circuit.state == CircuitOpen)
+	health.LastSuccessAt = timePointer(circuit.lastSuccessAt, !circuit.lastSuccessAt.IsZero())
+	health.LastFailureAt = timePointer(circuit.lastFailureAt, !circuit.lastFailureAt.IsZero())
+	health.LastError = circuit.lastError
+
+	currentMinute := now.Unix() / 60
+	for _, minutes := range healthWindows {
+		window := ProviderHealthWindow{Window: strconv.Itoa(minutes) + "m"}
+		var latency time.Duration
+		for _, bucket := range circuit.buckets {
+			if bucket.calls == 0 || currentMinute-bucket.minute >= int64(minutes) {
+				continue
+			}
+			window.Calls += bucket.calls
+			window.Successes += bucket.successes
+			window.Failures += bucket.failures
+			window.TransientFailures += bucket.transient
+			latency += bucket.latency
+		}
+		if window.Calls > 0 {
+			rate := float64(window.Successes) / float64(window.Calls)
+			window.SuccessRate = &rate
+			window.AvgLatencyMs = (latency / time.Duration(window.Calls)).Milliseconds()
+		}
+		health.Windows = append(health.Windows, window)
+	}
+	return health
+}
+
+// timePointer returns a pointer to t when set is true
+func timePointer(t time.Time, set bool) *time.Time {
+	if !set {
+		return nil
+	}
+	return &t
+}
+
+// IsTransientProviderError reports whether a provider error is likely to go away on its own:
+// timeouts, connection errors, 5xx and 429 responses, SMTP 4xx replies and lost SMPP connections
+func IsTransientProviderError(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+
+	if matches := statusCodePattern.FindAllStringSubmatch(err.Error(), -1); len(matches) > 0 {
+		code, _ := strconv.Atoi(matches[len(matches)-1][1])
+		return code >= 500 || code == 429
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, marker := range []string{"timed out", "timeout", "connection lost", "connection refused", "connection reset", "session is closed"} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func TestIsTransientProviderError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil},
+		{name: "open circuit", err: ErrCircuitOpen},
+		{name: "deadline exceeded", err: fmt.Errorf("send failed: %w", context.DeadlineExceeded), want: true},
+		{name: "server error", err: errors.New("twilio API error: {}, status code: 503"), want: true},
+		{name: "rate limited", err: errors.New("sendgrid API error: {}, status code: 429"), want: true},
+		{name: "rejected request", err: errors.New("sendgrid API error: {}, status code: 400")},
+		{name: "last status code counts", err: errors.New("status code: 503 after retry, status code: 401")},
+		{name: "SMTP 4xx reply", err: &textproto.Error{Code: 421, Msg: "try again later"}, want: true},
+		{name: "SMTP 5xx reply", err: &textproto.Error{Code: 550, Msg: "mailbox unavailable"}},
+		{name: "lost SMPP connection", err: errors.New("SMPP connection lost"), want: true},
+		{name: "connection refused", err: errors.New("dial tcp: connection refused"), want: true},
+		{name: "invalid recipient", err: errors.New("invalid phone number")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientProviderError(tt.err); got != tt.want {
+				t.Errorf("IsTransientProviderError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallProviderCircuitTransitions(t *testing.T) {
+	loadCircuitSettings()
+	threshold := circuitSettings.failureThreshold
+	circuitSettings.failureThreshold = 3
+	defer func() { circuitSettings.failureThreshold = threshold }()
+
+	transient := errors.New("API error, status code: 503")
+	permanent := errors.New("API error, status code: 400")
+
+	// call is one provider call, expire first lets the open duration pass
+	type call struct {
+		err        error
+		expire     bool
+		wantCalled bool
+		wantState  string
+	}
+
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "transient failures below the threshold keep the circuit closed",
+			calls: []call{
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: nil, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "threshold of transient failures opens the circuit",
+			calls: []call{
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitOpen},
+				{err: nil, wantCalled: false, wantState: CircuitOpen},
+			},
+		},
+		{
+			name: "permanent failures do not open the circuit",
+			calls: []call{
+				{err: permanent, wantCalled: true, wantState: CircuitClosed},
+				{err: permanent, wantCalled: true, wantState: CircuitClosed},
+				{err: permanent, wantCalled: true, wantState: CircuitClosed},
+				{err: permanent, wantCalled: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "successful trial call closes the circuit",
+			calls: []call{
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitOpen},
+				{err: nil, expire: true, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "failed trial call opens the circuit again",
+			calls: []call{
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitOpen},
+				{err: transient, expire: true, wantCalled: true, wantState: CircuitOpen},
+				{err: nil, wantCalled: false, wantState: CircuitOpen},
+			},
+		},
+		{
+			name: "permanent failure of the trial call closes the circuit",
+			calls: []call{
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitClosed},
+				{err: transient, wantCalled: true, wantState: CircuitOpen},
+				{err: permanent, expire: true, wantCalled: true, wantState: CircuitClosed},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providerUUID := fmt.Sprintf("circuit-transitions-%d", i)
+			t.Cleanup(func() { removeCircuit(providerUUID) })
+			for j, c := range tt.calls {
+				if c.expire {
+					expireCircuit(providerUUID)
+				}
+
+				called := false
+				err := CallProvider(providerUUID, func() error {
+					called = true
+					return c.err
+				})
+				if called != c.wantCalled {
+					t.Fatalf("call %d: provider called = %v, want %v", j, called, c.wantCalled)
+				}
+				if !called && !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("call %d: CallProvider() error = %v, want ErrCircuitOpen", j, err)
+				}
+				if state := GetProviderHealth(providerUUID).State; state != c.wantState {
+					t.Fatalf("call %d: state = %s, want %s", j, state, c.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestHalfOpenCircuitAllowsOneTrialCall(t *testing.T) {
+	loadCircuitSettings()
+	const providerUUID = "circuit-half-open"
+	t.Cleanup(func() { removeCircuit(providerUUID) })
+
+	for i := 0; i < circuitSettings.failureThreshold; i++ {
+		RecordProviderCall(providerUUID, errors.New("connection refused"), time.Millisecond)
+	}
+	if ProviderAvailable(providerUUID) {
+		t.Fatal("ProviderAvailable() = true for an open circuit, want false")
+	}
+
+	expireCircuit(providerUUID)
+	if !ProviderAvailable(providerUUID) {
+		t.Fatal("ProviderAvailable() = false after the open duration, want true")
+	}
+	if err := acquireProviderCall(providerUUID, time.Now()); err != nil {
+		t.Fatalf("acquireProviderCall() error = %v, want the trial call", err)
+	}
+	if state := GetProviderHealth(providerUUID).State; state != CircuitHalfOpen {
+		t.Fatalf("state = %s, want %s", state, CircuitHalfOpen)
+	}
+	if ProviderAvailable(providerUUID) {
+		t.Error("ProviderAvailable() = true while the trial call is in flight, want false")
+	}
+	if err := acquireProviderCall(providerUUID, time.Now()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("acquireProviderCall() error = %v during the trial call, want ErrCircuitOpen", err)
+	}
+
+	RecordProviderCall(providerUUID, nil, time.Millisecond)
+	health := GetProviderHealth(providerUUID)
+	if health.State != CircuitClosed || health.ConsecutiveFailures != 0 || health.RetryAt != nil {
+		t.Errorf("GetProviderHealth() = state %s, %d failures, retry at %v, want closed without failures",
+			health.State, health.ConsecutiveFailures, health.RetryAt)
+	}
+	if window := health.Windows[1]; window.Calls != circuitSettings.failureThreshold+1 || window.Successes != 1 {
+		t.Errorf("GetProviderHealth() 5m window = %d calls, %d successes, want %d and 1",
+			window.Calls, window.Successes, circuitSettings.failureThreshold+1)
+	}
+}
+
+// expireCircuit lets the open duration of a provider circuit pass
+func expireCircuit(providerUUID string) {
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+	circuitFor(providerUUID).retryAt = time.Now().Add(-time.Second)
+}
+
+// removeCircuit forgets the circuit of a provider, so the test can run again in the same process
+func removeCircuit(providerUUID string) {
+	providerCircuits.Lock()
+	defer providerCircuits.Unlock()
+	delete(providerCircuits.circuits, providerUUID)
+}
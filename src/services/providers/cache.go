@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/sms"
+	"sync"
+	"time"
+)
+
+// defaultClientCacheTTL bounds how long a cached provider is used, so credentials kept in an external
+// secret backend are read again after they change there
+const defaultClientCacheTTL = 5 * time.Minute
+
+// cachedClient is a provider instance created for one version of a provider row
+type cachedClient struct {
+	channel   models.Channel
+	updatedAt time.Time
+	expiresAt time.Time
+	instance  interface{}
+}
+
+// copier is implemented by providers that keep the state of their last send, such as its message ID.
+// Each use of a cached instance gets a copy so concurrent consumers do not share that state.
+type copier interface {
+	Copy() interface{}
+}
+
+// clientCache holds the provider instances, keyed by provider UUID. An entry is only used while
+// the UpdatedAt of the provider row matches, so an update on another instance replaces it too.
+var clientCache = struct {
+	sync.Mutex
+	clients map[string]cachedClient
+}{clients: map[string]cachedClient{}}
+
+var (
+	clientCacheTTLOnce sync.Once
+	clientCacheTTL     time.Duration
+)
+
+// cacheTTL returns how long provider instances are cached, PROVIDER_CLIENT_CACHE_TTL ("0" disables the cache)
+func cacheTTL() time.Duration {
+	clientCacheTTLOnce.Do(func() {
+		clientCacheTTL = defaultClientCacheTTL
+		if value := helper.GetEnv("PROVIDER_CLIENT_CACHE_TTL", ""); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed < 0 {
+				helper.Log.Warnf("Invalid value for PROVIDER_CLIENT_CACHE_TTL, using default of %s", defaultClientCacheTTL)
+			} else {
+				clientCacheTTL = parsed
+			}
+		}
+	})
+	return clientCacheTTL
+}
+
+// cachedProvider returns the cached instance of a provider row, nil when there is none for its current version
+func cachedProvider(channel models.Channel, provider *models.Provider) interface{} {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	cached, ok := clientCache.clients[provider.UUID]
+	if !ok {
+		return nil
+	}
+	if cached.channel != channel || !cached.updatedAt.Equal(provider.UpdatedAt) || time.Now().After(cached.expiresAt) {
+		delete(clientCache.clients, provider.UUID)
+		return nil
+	}
+
+	if c, ok := cached.instance.(copier); ok {
+		return c.Copy()
+	}
+	return cached.instance
+}
+
+// cacheProvider stores the instance created for a provider row and returns the instance to use
+func cacheProvider(channel models.Channel, provider *models.Provider, instance interface{}) interface{} {
+	ttl := cacheTTL()
+	if ttl == 0 || provider.UUID == "" {
+		return instance
+	}
+
+	clientCache.Lock()
+	clientCache.clients[provider.UUID] = cachedClient{
+		channel:   channel,
+		updatedAt: provider.UpdatedAt,
+		expiresAt: time.Now().Add(ttl),
+		instance:  instance,
+	}
+	clientCache.Unlock()
+
+	if c, ok := instance.(copier); ok {
+		return c.Copy()
+	}
+	return instance
+}
+
+// InvalidateProvider drops the cached instance and the SMPP bind of a provider, the next message creates
+// them from the updated row
+func InvalidateProvider(uuid string) {
+	clientCache.Lock()
+	delete(clientCache.clients, uuid)
+	clientCache.Unlock()
+
+	sms.CloseSMPPSession(uuid)
+}
@@ -0,0 +1,270 @@
+package email
+
+import (
+	"bytes"
+	"delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mailgunMaxTags is the number of tags Mailgun accepts per message
+const mailgunMaxTags = 3
+
+// MailgunProvider implements the EmailProvider interface using the Mailgun messages API
+type MailgunProvider struct {
+	APIKey    string
+	Domain    string
+	FromEmail string
+	BaseURL   string
+	Client    *http.Client
+	Provider  *models.Provider
+}
+
+// MailgunConfig holds the Mailgun provider configuration
+type MailgunConfig struct {
+	FromEmail string `json:"from,omitempty"`
+	Domain    string `json:"domain,omitempty"`
+	BaseURL   string `json:"baseUrl,omitempty"` // https://api.mailgun.net or https://api.eu.mailgun.net
+}
+
+// MailgunSecureConfig holds the Mailgun provider secure configuration
+type MailgunSecureConfig struct {
+	APIKey string `json:"apikey,omitempty"`
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "MAILGUN",
+		DisplayName:  "Mailgun",
+		Channel:      models.ChannelEmail,
+		Capabilities: registry.Capabilities{Attachments: true, Bulk: true},
+		ConfigSchema: []registry.Field{
+			{Name: "from", Type: registry.FieldString, Required: true, Description: "Sender address"},
+			{Name: "domain", Type: registry.FieldString, Required: true, Description: "Sending domain"},
+			{Name: "baseUrl", Type: registry.FieldString, Default: "https://api.mailgun.net", Description: "Use https://api.eu.mailgun.net for EU domains"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "apikey", Type: registry.FieldString, Required: true, Description: "Mailgun API key"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewMailgunProviderFromDB(provider)
+		},
+	})
+}
+
+// NewMailgunProviderFromDB creates a new Mailgun email provider using database configuration
+func NewMailgunProviderFromDB(provider *models.Provider) (*MailgunProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config MailgunConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig MailgunSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if secureConfig.APIKey == "" {
+		return nil, errors.New("API key not set in provider configuration")
+	}
+	if config.Domain == "" {
+		return nil, errors.New("domain not set in provider configuration")
+	}
+	if config.FromEmail == "" {
+		return nil, errors.New("from email not set in provider configuration")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.mailgun.net"
+	}
+
+	return &MailgunProvider{
+		APIKey:    strings.TrimSpace(secureConfig.APIKey),
+		Domain:    config.Domain,
+		FromEmail: config.FromEmail,
+		BaseURL:   baseURL,
+		Client:    helper.NewProviderHTTPClient(30 * time.Second),
+		Provider:  provider,
+	}, nil
+}
+
+// Send implements the EmailProvider.Send method
+func (p *MailgunProvider) Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error {
+	return p.SendWithAttachments(to, subject, body, isHTML, nil, options)
+}
+
+// SendWithAttachments implements the EmailProvider.SendWithAttachments method
+func (p *MailgunProvider) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error {
+	if options == nil {
+		options = &types.EmailOptions{}
+	}
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	from := mail.Address{Name: options.FromName, Address: p.FromEmail}
+	fields := [][2]string{
+		{"from", from.String()},
+		{"subject", subject},
+	}
+	for _, recipient := range to {
+		fields = append(fields, [2]string{"to", recipient})
+	}
+	for _, address := range options.Cc {
+		fields = append(fields, [2]string{"cc", (&mail.Address{Name: address.Name, Address: address.Email}).String()})
+	}
+	for _, address := range options.Bcc {
+		fields = append(fields, [2]string{"bcc", (&mail.Address{Name: address.Name, Address: address.Email}).String()})
+	}
+
+	if isHTML {
+		fields = append(fields, [2]string{"html", body})
+		if options.TextBody != "" {
+			fields = append(fields, [2]string{"text", options.TextBody})
+		}
+	} else {
+		fields = append(fields, [2]string{"text", body})
+	}
+
+	if options.ReplyTo != nil {
+		fields = append(fields, [2]string{"h:Reply-To", formatAddresses([]types.EmailAddress{*options.ReplyTo})})
+	}
+	for _, name := range sortedKeys(options.Headers) {
+		fields = append(fields, [2]string{"h:" + name, options.Headers[name]})
+	}
+	for _, name := range sortedKeys(options.CustomArgs) {
+		fields = append(fields, [2]string{"v:" + name, options.CustomArgs[name]})
+	}
+
+	tags := uniqueCategories(options.Categories)
+	if len(tags) > mailgunMaxTags {
+		helper.Log.WithField("categories", len(tags)).Warn("Mailgun accepts at most 3 tags, dropping the rest")
+		tags = tags[:mailgunMaxTags]
+	}
+	for _, tag := range tags {
+		fields = append(fields, [2]string{"o:tag", tag})
+	}
+
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return fmt.Errorf("failed to build Mailgun request: %w", err)
+		}
+	}
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := form.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {fmt.Sprintf("form-data; name=\"attachment\"; filename=%q", attachment.Filename)},
+			"Content-Type":        {contentType},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build Mailgun request: %w", err)
+		}
+		if _, err := part.Write(attachment.Content); err != nil {
+			return fmt.Errorf("failed to build Mailgun request: %w", err)
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to build Mailgun request: %w", err)
+	}
+
+	return p.sendRequest(form.FormDataContentType(), &buf, len(to), subject)
+}
+
+// sendRequest posts a message to the Mailgun messages endpoint
+func (p *MailgunProvider) sendRequest(contentType string, body io.Reader, recipients int, subject string) error {
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", strings.TrimSuffix(p.BaseURL, "/"), p.Domain)
+
+	helper.Log.WithFields(map[string]interface{}{
+		"endpoint":   endpoint,
+		"fromEmail":  p.FromEmail,
+		"recipients": recipients,
+		"subject":    subject,
+	}).Info("Sending Mailgun API request")
+
+	req, err := http.NewRequest("POST", endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.SetBasicAuth("api", p.APIKey)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	responseFields := map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"response":   string(responseBody),
+	}
+
+	if resp.StatusCode >= 400 {
+		helper.Log.WithFields(responseFields).Error("Mailgun API returned an error response")
+		return fmt.Errorf("mailgun API error: %s, status code: %d", string(responseBody), resp.StatusCode)
+	}
+
+	helper.Log.WithFields(responseFields).Info("Mailgun API request successful")
+	return nil
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by fetching the sending domain
+func (p *MailgunProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v3/domains/%s", strings.TrimSuffix(p.BaseURL, "/"), p.Domain), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", p.APIKey)
+	return helper.CheckCredentials(p.Client, req)
+}
+
+// GetStatus gets the status of an email message
+func (p *MailgunProvider) GetStatus(messageID string) (types.DeliveryStatus, error) {
+	// Mailgun reports delivery through events rather than a status lookup
+	return types.DeliveryStatus{
+		MessageID: messageID,
+		Status:    "unknown",
+		Details:   "Mailgun provider does not support status retrieval by message ID",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"delivery/api/types"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// buildMIMEMessage renders a MIME message: multipart/mixed with a multipart/alternative body
+// and the attachments, or just the body when there are no attachments. Bcc recipients are left out.
+func buildMIMEMessage(fromEmail string, to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) ([]byte, error) {
+	var buf bytes.Buffer
+
+	from := mail.Address{Name: options.FromName, Address: fromEmail}
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", strings.Join(to, ", ")},
+	}
+	if len(options.Cc) > 0 {
+		headers = append(headers, [2]string{"Cc", formatAddresses(options.Cc)})
+	}
+	if options.ReplyTo != nil {
+		headers = append(headers, [2]string{"Reply-To", formatAddresses([]types.EmailAddress{*options.ReplyTo})})
+	}
+	headers = append(headers,
+		[2]string{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		[2]string{"Date", time.Now().Format(time.RFC1123Z)},
+		[2]string{"Message-ID", newMessageID(fromEmail)},
+		[2]string{"MIME-Version", "1.0"},
+	)
+
+	// Custom headers are written in a stable order
+	names := make([]string, 0, len(options.Headers))
+	for name := range options.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := types.ValidateHeaderName(name); err != nil {
+			return nil, err
+		}
+		headers = append(headers, [2]string{textproto.CanonicalMIMEHeaderKey(name), mime.QEncoding.Encode("utf-8", options.Headers[name])})
+	}
+
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+
+	if len(attachments) == 0 {
+		if err := writeBody(&buf, body, isHTML, options.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mixed.Boundary())
+
+	var bodyPart bytes.Buffer
+	if err := writeBody(&bodyPart, body, isHTML, options.TextBody); err != nil {
+		return nil, err
+	}
+	partHeaders, content := splitPart(bodyPart.Bytes())
+	part, err := mixed.CreatePart(partHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := mime.QEncoding.Encode("utf-8", attachment.Filename)
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=%q", contentType, filename)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filename)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(wrapBase64(attachment.Content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newMessageID generates a unique Message-ID in the domain of the from address
+func newMessageID(fromEmail string) string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	domain := "localhost"
+	if at := strings.LastIndex(fromEmail, "@"); at >= 0 {
+		domain = fromEmail[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
+
+// writeBody writes the Content-Type header and the body: multipart/alternative when an HTML body
+// has a plain-text version, a single part otherwise
+func writeBody(buf *bytes.Buffer, body string, isHTML bool, textBody string) error {
+	if !isHTML || textBody == "" {
+		contentType := "text/plain"
+		if isHTML {
+			contentType = "text/html"
+		}
+		fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: base64\r\n\r\n", contentType)
+		buf.Write(wrapBase64([]byte(body)))
+		return nil
+	}
+
+	alternative := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", alternative.Boundary())
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain", textBody},
+		{"text/html", body},
+	} {
+		writer, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(wrapBase64([]byte(part.content))); err != nil {
+			return err
+		}
+	}
+	return alternative.Close()
+}
+
+// splitPart splits a rendered part into its headers and content
+func splitPart(rendered []byte) (textproto.MIMEHeader, []byte) {
+	headers := textproto.MIMEHeader{}
+	raw, content, _ := bytes.Cut(rendered, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if name, value, ok := strings.Cut(line, ": "); ok {
+			headers.Add(name, value)
+		}
+	}
+	return headers, content
+}
+
+// wrapBase64 encodes data as base64 in lines of 76 characters
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// formatAddresses formats addresses for a header
+func formatAddresses(addresses []types.EmailAddress) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		formatted[i] = (&mail.Address{Name: address.Name, Address: address.Email}).String()
+	}
+	return strings.Join(formatted, ", ")
+}
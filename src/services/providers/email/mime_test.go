@@ -0,0 +1,58 @@
+package email
+
+import (
+	"delivery/api/types"
+	"strings"
+	"testing"
+)
+
+func TestBuildMIMEMessageHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantErr    bool
+		wantHeader string
+	}{
+		{
+			name:       "custom header name is canonicalized",
+			headers:    map[string]string{"x-campaign-id": "spring"},
+			wantHeader: "X-Campaign-Id: spring\r\n",
+		},
+		{
+			name:       "value with a line break is encoded",
+			headers:    map[string]string{"X-Note": "a\r\nBcc: victim@example.com"},
+			wantHeader: "X-Note: =?utf-8?q?a=0D=0ABcc:_victim@example.com?=\r\n",
+		},
+		{
+			name:       "non-ASCII value is encoded",
+			headers:    map[string]string{"X-Note": "café"},
+			wantHeader: "X-Note: =?utf-8?q?caf=C3=A9?=\r\n",
+		},
+		{name: "reserved header is rejected", headers: map[string]string{"bcc": "victim@example.com"}, wantErr: true},
+		{name: "invalid header name is rejected", headers: map[string]string{"X-Test\r\nBcc": "victim@example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := buildMIMEMessage("sender@example.com", []string{"to@example.com"}, "Subject", "Body", false, nil,
+				&types.EmailOptions{Headers: tt.headers})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("buildMIMEMessage() error = nil, want an error for headers %q", tt.headers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildMIMEMessage() error = %v", err)
+			}
+
+			head, _, _ := strings.Cut(string(message), "\r\n\r\n")
+			if !strings.Contains(head+"\r\n", tt.wantHeader) {
+				t.Errorf("headers = %q, want %q", head, tt.wantHeader)
+			}
+			if strings.Contains(head, "\r\nBcc:") {
+				t.Errorf("headers = %q, contain an injected Bcc header", head)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"delivery/api/types"
 	"delivery/helper"
 	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,6 +37,29 @@ type SecureConfig struct {
 	APIKey string `json:"apikey,omitempty"`
 }
 
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "SENDGRID",
+		Aliases:      []string{"TWILIO"},
+		DisplayName:  "SendGrid",
+		Channel:      models.ChannelEmail,
+		Capabilities: registry.Capabilities{Attachments: true, Bulk: true},
+		ConfigSchema: []registry.Field{
+			{Name: "from", Type: registry.FieldString, Required: true, Description: "Sender address"},
+			{Name: "baseUrl", Type: registry.FieldString, Required: true, Description: "SendGrid API base URL, e.g. https://api.sendgrid.com"},
+			{Name: "accountId", Type: registry.FieldString},
+			{Name: "eventWebhook", Type: registry.FieldBoolean, Description: "Suppress addresses from the SendGrid event webhook"},
+			{Name: "webhookVerificationKey", Type: registry.FieldString, RequiredWith: "eventWebhook", Description: "Public key of the signed event webhook, events are rejected without it"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "apikey", Type: registry.FieldString, Required: true, Description: "SendGrid API key"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewSendGridProviderFromDB(provider)
+		},
+	})
+}
+
 // NewSendGridProviderFromDB creates a new SendGrid email provider using database configuration
 func NewSendGridProviderFromDB(provider *models.Provider) (*SendGridProvider, error) {
 	if provider == nil {
@@ -52,36 +77,10 @@ func NewSendGridProviderFromDB(provider *models.Provider) (*SendGridProvider, er
 		return nil, fmt.Errorf("failed to parse provider config: %w", err)
 	}
 
-	// Parse secure config
-	secureConfigJSON, err := json.Marshal(provider.SecureConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal provider secure config: %w", err)
-	}
-
-	// Get encryption key from environment
-	encryptionKey := []byte(helper.GetEnv("ENCRYPTION_KEY", ""))
-	if len(encryptionKey) != 32 {
-		return nil, errors.New("ENCRYPTION_KEY environment variable not set or invalid (must be exactly 32 bytes)")
-	}
-
-	// Check if the config is encrypted
-	var encryptedData struct {
-		Encrypted string `json:"encrypted"`
-	}
-	if err := json.Unmarshal(secureConfigJSON, &encryptedData); err != nil || encryptedData.Encrypted == "" {
-		return nil, fmt.Errorf("secure config is not in the expected format: %w", err)
-	}
-
-	// Decrypt the secure config
-	decodedBytes, err := helper.DecodeBase64AndDecrypt(encryptedData.Encrypted, encryptionKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt provider secure config: %w", err)
-	}
-
-	// Parse the decrypted secure config
+	// Load secure config from its secret backend
 	var secureConfig SecureConfig
-	if err := json.Unmarshal(decodedBytes, &secureConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse provider secure config: %w", err)
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
 	}
 
 	// Validate required fields
@@ -108,46 +107,18 @@ func NewSendGridProviderFromDB(provider *models.Provider) (*SendGridProvider, er
 		APIKey:    apiKey,
 		FromEmail: fromEmail,
 		BaseURL:   baseURL,
-		Client:    &http.Client{Timeout: 10 * time.Second},
+		Client:    helper.NewProviderHTTPClient(10 * time.Second),
 		Provider:  provider,
 	}, nil
 }
 
 // Send implements the EmailService.Send method
-func (p *SendGridProvider) Send(to []string, subject string, body string, isHTML bool) error {
-	contentType := "text/plain"
-	if isHTML {
-		contentType = "text/html"
-	}
-
-	emailRequest := map[string]interface{}{
-		"personalizations": []map[string]interface{}{
-			{
-				"to": toRecipientFormat(to),
-			},
-		},
-		"from": map[string]string{
-			"email": p.FromEmail,
-		},
-		"subject": subject,
-		"content": []map[string]string{
-			{
-				"type":  contentType,
-				"value": body,
-			},
-		},
-	}
-
-	return p.sendRequest(emailRequest)
+func (p *SendGridProvider) Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error {
+	return p.sendRequest(p.buildRequest(to, subject, body, isHTML, options))
 }
 
 // SendWithAttachments sends an email with attachments
-func (p *SendGridProvider) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment) error {
-	contentType := "text/plain"
-	if isHTML {
-		contentType = "text/html"
-	}
-
+func (p *SendGridProvider) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error {
 	// Format the attachments for SendGrid API
 	sendgridAttachments := []map[string]string{}
 	for _, attachment := range attachments {
@@ -160,26 +131,81 @@ func (p *SendGridProvider) SendWithAttachments(to []string, subject string, body
 		})
 	}
 
+	emailRequest := p.buildRequest(to, subject, body, isHTML, options)
+	emailRequest["attachments"] = sendgridAttachments
+
+	return p.sendRequest(emailRequest)
+}
+
+// sendGridMaxCategories is the number of categories SendGrid accepts per email
+const sendGridMaxCategories = 10
+
+// buildRequest builds the SendGrid mail send request body
+func (p *SendGridProvider) buildRequest(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) map[string]interface{} {
+	contentType := "text/plain"
+	if isHTML {
+		contentType = "text/html"
+	}
+	if options == nil {
+		options = &types.EmailOptions{}
+	}
+
+	personalization := map[string]interface{}{
+		"to": toRecipientFormat(to),
+	}
+	if len(options.Cc) > 0 {
+		personalization["cc"] = toAddressFormat(options.Cc)
+	}
+	if len(options.Bcc) > 0 {
+		personalization["bcc"] = toAddressFormat(options.Bcc)
+	}
+
+	from := map[string]string{
+		"email": p.FromEmail,
+	}
+	if options.FromName != "" {
+		from["name"] = options.FromName
+	}
+
+	// SendGrid requires the plain-text part to come before the HTML part
+	content := []map[string]string{}
+	if isHTML && options.TextBody != "" {
+		content = append(content, map[string]string{
+			"type":  "text/plain",
+			"value": options.TextBody,
+		})
+	}
+	content = append(content, map[string]string{
+		"type":  contentType,
+		"value": body,
+	})
+
 	emailRequest := map[string]interface{}{
-		"personalizations": []map[string]interface{}{
-			{
-				"to": toRecipientFormat(to),
-			},
-		},
-		"from": map[string]string{
-			"email": p.FromEmail,
-		},
-		"subject": subject,
-		"content": []map[string]string{
-			{
-				"type":  contentType,
-				"value": body,
-			},
-		},
-		"attachments": sendgridAttachments,
+		"personalizations": []map[string]interface{}{personalization},
+		"from":             from,
+		"subject":          subject,
+		"content":          content,
 	}
 
-	return p.sendRequest(emailRequest)
+	if options.ReplyTo != nil {
+		emailRequest["reply_to"] = toAddressFormat([]types.EmailAddress{*options.ReplyTo})[0]
+	}
+	if len(options.Headers) > 0 {
+		emailRequest["headers"] = options.Headers
+	}
+	if len(options.CustomArgs) > 0 {
+		emailRequest["custom_args"] = options.CustomArgs
+	}
+	if len(options.Categories) > 0 {
+		categories := uniqueCategories(options.Categories)
+		if len(categories) > sendGridMaxCategories {
+			helper.Log.WithField("categories", len(categories)).Warn("SendGrid accepts at most 10 categories, dropping the rest")
+			categories = categories[:sendGridMaxCategories]
+		}
+		emailRequest["categories"] = categories
+	}
+
+	return emailRequest
 }
 
 // sendRequest sends a request to the SendGrid API
@@ -201,12 +227,12 @@ func (p *SendGridProvider) sendRequest(emailRequest map[string]interface{}) erro
 	}
 
 	helper.Log.WithFields(map[string]interface{}{
-		"endpoint":    endpoint,
-		"fromEmail":   p.FromEmail,
-		"recipients":  emailRequest["personalizations"].([]map[string]interface{})[0]["to"],
-		"subject":     emailRequest["subject"],
-		"requestBody": requestBodyStr,
-		"contentType": emailRequest["content"].([]map[string]string)[0]["type"],
+		"endpoint":     endpoint,
+		"fromEmail":    p.FromEmail,
+		"recipients":   emailRequest["personalizations"].([]map[string]interface{})[0]["to"],
+		"subject":      emailRequest["subject"],
+		"requestBody":  requestBodyStr,
+		"contentParts": len(emailRequest["content"].([]map[string]string)),
 	}).Info("Sending SendGrid Email API request")
 
 	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(requestBody))
@@ -272,6 +298,44 @@ func toRecipientFormat(emails []string) []map[string]string {
 	return recipients
 }
 
+// toAddressFormat converts addresses with optional names to SendGrid recipient format
+func toAddressFormat(addresses []types.EmailAddress) []map[string]string {
+	recipients := make([]map[string]string, len(addresses))
+	for i, address := range addresses {
+		recipients[i] = map[string]string{
+			"email": address.Email,
+		}
+		if address.Name != "" {
+			recipients[i]["name"] = address.Name
+		}
+	}
+	return recipients
+}
+
+// uniqueCategories removes empty and duplicate categories, SendGrid rejects both
+func uniqueCategories(categories []string) []string {
+	seen := make(map[string]bool, len(categories))
+	unique := make([]string, 0, len(categories))
+	for _, category := range categories {
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		unique = append(unique, category)
+	}
+	return unique
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by listing the API key scopes
+func (p *SendGridProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(p.BaseURL, "/")+"/v3/scopes", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	return helper.CheckCredentials(p.Client, req)
+}
+
 // GetStatus gets the status of an email message
 func (p *SendGridProvider) GetStatus(messageID string) (types.DeliveryStatus, error) {
 	// SendGrid doesn't provide a direct way to get message status by ID
@@ -0,0 +1,261 @@
+package email
+
+import (
+	"bytes"
+	"delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sesTagPattern matches the characters SES allows in message tag names and values
+var sesTagPattern = regexp.MustCompile(`[^A-Za-z0-9_\-]`)
+
+// SESProvider implements the EmailProvider interface using the Amazon SES v2 API
+type SESProvider struct {
+	Credentials      helper.AWSCredentials
+	Region           string
+	FromEmail        string
+	ConfigurationSet string
+	BaseURL          string
+	Client           *http.Client
+	Provider         *models.Provider
+}
+
+// SESConfig holds the SES provider configuration
+type SESConfig struct {
+	FromEmail        string `json:"from,omitempty"`
+	Region           string `json:"region,omitempty"`
+	ConfigurationSet string `json:"configurationSet,omitempty"` // Needed to receive delivery events
+	BaseURL          string `json:"baseUrl,omitempty"`          // Defaults to https://email.{region}.amazonaws.com
+}
+
+// SESSecureConfig holds the SES provider secure configuration
+type SESSecureConfig struct {
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "SES",
+		DisplayName:  "Amazon SES",
+		Channel:      models.ChannelEmail,
+		Capabilities: registry.Capabilities{Attachments: true, Bulk: true},
+		ConfigSchema: []registry.Field{
+			{Name: "from", Type: registry.FieldString, Required: true, Description: "Verified sender address"},
+			{Name: "region", Type: registry.FieldString, Required: true, Description: "AWS region, e.g. eu-west-1"},
+			{Name: "configurationSet", Type: registry.FieldString, Description: "Configuration set used to publish delivery events"},
+			{Name: "baseUrl", Type: registry.FieldString, Description: "Defaults to https://email.{region}.amazonaws.com"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "accessKeyId", Type: registry.FieldString, Required: true},
+			{Name: "secretAccessKey", Type: registry.FieldString, Required: true},
+			{Name: "sessionToken", Type: registry.FieldString, Description: "Session token for temporary credentials"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewSESProviderFromDB(provider)
+		},
+	})
+}
+
+// NewSESProviderFromDB creates a new SES email provider using database configuration
+func NewSESProviderFromDB(provider *models.Provider) (*SESProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config SESConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig SESSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if secureConfig.AccessKeyID == "" || secureConfig.SecretAccessKey == "" {
+		return nil, errors.New("access key ID and secret access key not set in provider configuration")
+	}
+	if config.Region == "" {
+		return nil, errors.New("region not set in provider configuration")
+	}
+	if config.FromEmail == "" {
+		return nil, errors.New("from email not set in provider configuration")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://email.%s.amazonaws.com", config.Region)
+	}
+
+	return &SESProvider{
+		Credentials: helper.AWSCredentials{
+			AccessKeyID:     strings.TrimSpace(secureConfig.AccessKeyID),
+			SecretAccessKey: strings.TrimSpace(secureConfig.SecretAccessKey),
+			SessionToken:    strings.TrimSpace(secureConfig.SessionToken),
+		},
+		Region:           config.Region,
+		FromEmail:        config.FromEmail,
+		ConfigurationSet: config.ConfigurationSet,
+		BaseURL:          baseURL,
+		Client:           helper.NewProviderHTTPClient(30 * time.Second),
+		Provider:         provider,
+	}, nil
+}
+
+// Send implements the EmailProvider.Send method
+func (p *SESProvider) Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error {
+	return p.SendWithAttachments(to, subject, body, isHTML, nil, options)
+}
+
+// SendWithAttachments implements the EmailProvider.SendWithAttachments method.
+// The message is sent as raw MIME so attachments and custom headers are supported.
+func (p *SESProvider) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error {
+	if options == nil {
+		options = &types.EmailOptions{}
+	}
+
+	raw, err := buildMIMEMessage(p.FromEmail, to, subject, body, isHTML, attachments, options)
+	if err != nil {
+		return err
+	}
+
+	destination := map[string][]string{
+		"ToAddresses": to,
+	}
+	if len(options.Cc) > 0 {
+		destination["CcAddresses"] = addressList(options.Cc)
+	}
+	if len(options.Bcc) > 0 {
+		destination["BccAddresses"] = addressList(options.Bcc)
+	}
+
+	emailRequest := map[string]interface{}{
+		"FromEmailAddress": p.FromEmail,
+		"Destination":      destination,
+		"Content": map[string]interface{}{
+			"Raw": map[string]string{
+				"Data": helper.Base64Encode(raw),
+			},
+		},
+	}
+	if options.ReplyTo != nil {
+		emailRequest["ReplyToAddresses"] = []string{options.ReplyTo.Email}
+	}
+	if p.ConfigurationSet != "" {
+		emailRequest["ConfigurationSetName"] = p.ConfigurationSet
+	}
+
+	// Custom args and categories become message tags, which SES includes in its events
+	tags := []map[string]string{}
+	for _, name := range sortedKeys(options.CustomArgs) {
+		tags = append(tags, map[string]string{
+			"Name":  sesTagPattern.ReplaceAllString(name, "_"),
+			"Value": sesTagPattern.ReplaceAllString(options.CustomArgs[name], "_"),
+		})
+	}
+	// SES tag names must be unique, only the first category is kept
+	if categories := uniqueCategories(options.Categories); len(categories) > 0 {
+		tags = append(tags, map[string]string{
+			"Name":  "category",
+			"Value": sesTagPattern.ReplaceAllString(categories[0], "_"),
+		})
+	}
+	if len(tags) > 0 {
+		emailRequest["EmailTags"] = tags
+	}
+
+	return p.sendRequest(emailRequest, len(to)+len(options.Cc)+len(options.Bcc), subject)
+}
+
+// sendRequest posts a signed request to the SES v2 outbound emails endpoint
+func (p *SESProvider) sendRequest(emailRequest map[string]interface{}, recipients int, subject string) error {
+	endpoint := strings.TrimSuffix(p.BaseURL, "/") + "/v2/email/outbound-emails"
+
+	requestBody, err := json.Marshal(emailRequest)
+	if err != nil {
+		return err
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"endpoint":   endpoint,
+		"fromEmail":  p.FromEmail,
+		"recipients": recipients,
+		"subject":    subject,
+	}).Info("Sending SES API request")
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	helper.SignAWSRequest(req, requestBody, p.Region, "ses", p.Credentials, time.Now())
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	responseFields := map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"response":   string(responseBody),
+	}
+
+	if resp.StatusCode >= 400 {
+		helper.Log.WithFields(responseFields).Error("SES API returned an error response")
+		return fmt.Errorf("SES API error: %s, status code: %d", string(responseBody), resp.StatusCode)
+	}
+
+	helper.Log.WithFields(responseFields).Info("SES API request successful")
+	return nil
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by fetching the SES account
+func (p *SESProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(p.BaseURL, "/")+"/v2/email/account", nil)
+	if err != nil {
+		return err
+	}
+	helper.SignAWSRequest(req, nil, p.Region, "ses", p.Credentials, time.Now())
+	return helper.CheckCredentials(p.Client, req)
+}
+
+// GetStatus gets the status of an email message
+func (p *SESProvider) GetStatus(messageID string) (types.DeliveryStatus, error) {
+	// SES reports delivery through configuration set event destinations
+	return types.DeliveryStatus{
+		MessageID: messageID,
+		Status:    "unknown",
+		Details:   "SES provider does not support status retrieval by message ID",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// addressList returns the email addresses of a list of addresses
+func addressList(addresses []types.EmailAddress) []string {
+	list := make([]string, len(addresses))
+	for i, address := range addresses {
+		list[i] = address.Email
+	}
+	return list
+}
@@ -0,0 +1,459 @@
+package email
+
+import (
+	"crypto/tls"
+	"delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTP connection security modes
+const (
+	// SMTPSecurityStartTLS upgrades a plain connection with STARTTLS, usually on port 587
+	SMTPSecurityStartTLS = "starttls"
+
+	// SMTPSecurityTLS connects with implicit TLS, usually on port 465
+	SMTPSecurityTLS = "tls"
+
+	// SMTPSecurityNone sends without encryption, only meant for local relays and test sinks
+	SMTPSecurityNone = "none"
+)
+
+const (
+	// smtpIdleTimeout is how long an unused connection is kept open for reuse
+	smtpIdleTimeout = 30 * time.Second
+
+	// smtpMaxIdle is the number of idle connections kept per server and account
+	smtpMaxIdle = 4
+)
+
+// SMTPProvider implements the EmailProvider interface by relaying through an SMTP server
+type SMTPProvider struct {
+	Host          string
+	Port          int
+	Security      string
+	AuthMechanism string
+	Username      string
+	Password      string
+	FromEmail     string
+	HeloName      string
+	Timeout       time.Duration
+	Provider      *models.Provider
+}
+
+// SMTPConfig holds the SMTP provider configuration
+type SMTPConfig struct {
+	Host          string `json:"host,omitempty"`
+	Port          int    `json:"port,omitempty"`
+	Security      string `json:"security,omitempty"`      // starttls (default), tls or none
+	AuthMechanism string `json:"authMechanism,omitempty"` // PLAIN (default) or LOGIN, used when a username is set
+	FromEmail     string `json:"from,omitempty"`
+	HeloName      string `json:"heloName,omitempty"`
+	Timeout       int    `json:"timeoutSeconds,omitempty"`
+}
+
+// SMTPSecureConfig holds the SMTP provider secure configuration
+type SMTPSecureConfig struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "SMTP",
+		DisplayName:  "SMTP",
+		Channel:      models.ChannelEmail,
+		Capabilities: registry.Capabilities{Attachments: true, Bulk: true},
+		ConfigSchema: []registry.Field{
+			{Name: "host", Type: registry.FieldString, Required: true, Description: "SMTP relay host"},
+			{Name: "port", Type: registry.FieldInteger, Description: "Defaults to 587 for starttls and none, 465 for tls"},
+			{Name: "security", Type: registry.FieldString, Default: "starttls", Enum: []string{"starttls", "tls", "none"}},
+			{Name: "authMechanism", Type: registry.FieldString, Default: "PLAIN", Enum: []string{"PLAIN", "LOGIN"}, Description: "Used when a username is set"},
+			{Name: "from", Type: registry.FieldString, Required: true, Description: "Sender address"},
+			{Name: "heloName", Type: registry.FieldString},
+			{Name: "timeoutSeconds", Type: registry.FieldInteger, Default: 30, Description: "Timeout for connecting and for each mail transaction"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "username", Type: registry.FieldString},
+			{Name: "password", Type: registry.FieldString},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewSMTPProviderFromDB(provider)
+		},
+	})
+}
+
+// NewSMTPProviderFromDB creates a new SMTP email provider using database configuration
+func NewSMTPProviderFromDB(provider *models.Provider) (*SMTPProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config SMTPConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	// Credentials are optional, local relays often accept mail without authentication
+	var secureConfig SMTPSecureConfig
+	if len(provider.SecureConfig) > 0 {
+		if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Host == "" {
+		return nil, errors.New("host not set in provider configuration")
+	}
+	if config.FromEmail == "" {
+		return nil, errors.New("from email not set in provider configuration")
+	}
+
+	security := strings.ToLower(config.Security)
+	if security == "" {
+		security = SMTPSecurityStartTLS
+	}
+	if security != SMTPSecurityStartTLS && security != SMTPSecurityTLS && security != SMTPSecurityNone {
+		return nil, fmt.Errorf("unsupported SMTP security mode: %s", config.Security)
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 587
+		if security == SMTPSecurityTLS {
+			port = 465
+		}
+	}
+
+	authMechanism := strings.ToUpper(config.AuthMechanism)
+	if authMechanism == "" {
+		authMechanism = "PLAIN"
+	}
+	if authMechanism != "PLAIN" && authMechanism != "LOGIN" {
+		return nil, fmt.Errorf("unsupported SMTP auth mechanism: %s", config.AuthMechanism)
+	}
+
+	timeout := 30 * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	return &SMTPProvider{
+		Host:          config.Host,
+		Port:          port,
+		Security:      security,
+		AuthMechanism: authMechanism,
+		Username:      secureConfig.Username,
+		Password:      secureConfig.Password,
+		FromEmail:     config.FromEmail,
+		HeloName:      config.HeloName,
+		Timeout:       timeout,
+		Provider:      provider,
+	}, nil
+}
+
+// Send implements the EmailProvider.Send method
+func (p *SMTPProvider) Send(to []string, subject string, body string, isHTML bool, options *types.EmailOptions) error {
+	return p.SendWithAttachments(to, subject, body, isHTML, nil, options)
+}
+
+// SendWithAttachments implements the EmailProvider.SendWithAttachments method
+func (p *SMTPProvider) SendWithAttachments(to []string, subject string, body string, isHTML bool, attachments []types.EmailAttachment, options *types.EmailOptions) error {
+	if options == nil {
+		options = &types.EmailOptions{}
+	}
+
+	message, err := buildMIMEMessage(p.FromEmail, to, subject, body, isHTML, attachments, options)
+	if err != nil {
+		return err
+	}
+
+	// Bcc recipients only appear in the envelope
+	recipients := append([]string{}, to...)
+	for _, address := range options.Cc {
+		recipients = append(recipients, address.Email)
+	}
+	for _, address := range options.Bcc {
+		recipients = append(recipients, address.Email)
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"host":        p.Host,
+		"port":        p.Port,
+		"fromEmail":   p.FromEmail,
+		"recipients":  len(recipients),
+		"subject":     subject,
+		"attachments": len(attachments),
+	}).Info("Sending email through SMTP")
+
+	if err := p.deliver(recipients, message); err != nil {
+		helper.Log.WithError(err).WithField("host", p.Host).Error("SMTP delivery failed")
+		return err
+	}
+
+	helper.Log.WithField("host", p.Host).Info("SMTP delivery successful")
+	return nil
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by connecting and authenticating
+func (p *SMTPProvider) CheckCredentials() error {
+	conn, err := p.dial()
+	if err != nil {
+		return err
+	}
+	conn.setDeadline(p.Timeout)
+	return conn.client.Quit()
+}
+
+// GetStatus gets the status of an email message
+func (p *SMTPProvider) GetStatus(messageID string) (types.DeliveryStatus, error) {
+	// SMTP only reports whether the relay accepted the message
+	return types.DeliveryStatus{
+		MessageID: messageID,
+		Status:    "unknown",
+		Details:   "SMTP provider does not support status retrieval by message ID",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// deliver sends a message over a pooled connection, retrying once on a fresh connection
+// when a reused connection turns out to be closed by the server
+func (p *SMTPProvider) deliver(recipients []string, message []byte) error {
+	conn, reused, err := p.connection()
+	if err != nil {
+		return err
+	}
+
+	err = p.transmit(conn, recipients, message)
+	if err != nil && reused {
+		conn.Close()
+		if conn, err = p.dial(); err != nil {
+			return err
+		}
+		err = p.transmit(conn, recipients, message)
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	smtpConnections.release(p.poolKey(), conn, p.Timeout)
+	return nil
+}
+
+// transmit runs a single mail transaction on an open connection. The whole transaction has to complete
+// within the provider timeout, so a relay that stalls mid-session cannot block the consumer.
+func (p *SMTPProvider) transmit(conn *smtpConn, recipients []string, message []byte) error {
+	conn.setDeadline(p.Timeout)
+	client := conn.client
+	if err := client.Mail(p.FromEmail); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s rejected: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA rejected: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write SMTP message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP message rejected: %w", err)
+	}
+	return nil
+}
+
+// connection returns an idle pooled connection or dials a new one
+func (p *SMTPProvider) connection() (*smtpConn, bool, error) {
+	if conn := smtpConnections.acquire(p.poolKey()); conn != nil {
+		// A reset clears any state left by the previous transaction and checks the connection is alive
+		conn.setDeadline(p.Timeout)
+		if err := conn.client.Reset(); err == nil {
+			return conn, true, nil
+		}
+		conn.Close()
+	}
+
+	conn, err := p.dial()
+	return conn, false, err
+}
+
+// dial opens, secures and authenticates a new SMTP connection. The greeting, EHLO, STARTTLS and
+// authentication have to complete within the provider timeout.
+func (p *SMTPProvider) dial() (*smtpConn, error) {
+	address := net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	tlsConfig := &tls.Config{ServerName: p.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: p.Timeout}
+
+	var conn net.Conn
+	var err error
+	if p.Security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", address, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(p.Timeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set SMTP connection deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, p.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session with %s: %w", address, err)
+	}
+
+	if p.HeloName != "" {
+		if err := client.Hello(p.HeloName); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP EHLO rejected: %w", err)
+		}
+	}
+
+	if p.Security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", address)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+
+	if p.Username != "" {
+		var auth smtp.Auth
+		if p.AuthMechanism == "LOGIN" {
+			auth = &loginAuth{username: p.Username, password: p.Password}
+		} else {
+			auth = smtp.PlainAuth("", p.Username, p.Password, p.Host)
+		}
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return &smtpConn{client: client, conn: conn}, nil
+}
+
+// poolKey identifies connections that can be shared between providers
+func (p *SMTPProvider) poolKey() string {
+	return fmt.Sprintf("%s:%d|%s|%s", p.Host, p.Port, p.Security, p.Username)
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which net/smtp does not provide
+type loginAuth struct {
+	username string
+	password string
+}
+
+// Start implements the smtp.Auth interface
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next implements the smtp.Auth interface
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge: %s", fromServer)
+}
+
+// smtpPool keeps idle SMTP connections for reuse between sends
+type smtpPool struct {
+	mu   sync.Mutex
+	idle map[string][]*pooledSMTPConn
+}
+
+// smtpConn is an SMTP session and the network connection it runs on, which carries the deadlines
+type smtpConn struct {
+	client *smtp.Client
+	conn   net.Conn
+}
+
+// setDeadline limits the next operations on the connection to timeout from now
+func (c *smtpConn) setDeadline(timeout time.Duration) {
+	_ = c.conn.SetDeadline(time.Now().Add(timeout))
+}
+
+// Close closes the connection without ending the session
+func (c *smtpConn) Close() error {
+	return c.client.Close()
+}
+
+// pooledSMTPConn is an idle connection and the time it was returned to the pool
+type pooledSMTPConn struct {
+	conn     *smtpConn
+	returned time.Time
+}
+
+// smtpConnections is shared by all SMTP providers so connections survive provider instances
+var smtpConnections = &smtpPool{idle: map[string][]*pooledSMTPConn{}}
+
+// acquire takes the most recently used idle connection, closing expired ones
+func (p *smtpPool) acquire(key string) *smtpConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conns := p.idle[key]
+	for len(conns) > 0 {
+		conn := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		if time.Since(conn.returned) < smtpIdleTimeout {
+			p.idle[key] = conns
+			return conn.conn
+		}
+		go conn.conn.Close()
+	}
+	delete(p.idle, key)
+	return nil
+}
+
+// release returns a connection to the pool, or closes it when the pool is full
+func (p *smtpPool) release(key string, conn *smtpConn, timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.idle[key]) >= smtpMaxIdle {
+		conn.setDeadline(timeout)
+		go conn.client.Quit()
+		return
+	}
+	p.idle[key] = append(p.idle[key], &pooledSMTPConn{conn: conn, returned: time.Now()})
+}
@@ -0,0 +1,60 @@
+package providers
+
+import (
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	// Provider implementations register themselves with the registry
+	_ "delivery/services/providers/email"
+	_ "delivery/services/providers/sms"
+	_ "delivery/services/providers/whatsapp"
+)
+
+// ProviderTypes returns the registered provider implementations, optionally restricted to one channel
+func ProviderTypes(channel models.Channel) []registry.ProviderType {
+	return registry.List(channel)
+}
+
+// createProvider creates a provider instance with the implementation registered for the provider column
+// (case insensitive) on a channel. Instances are cached per provider UUID and UpdatedAt.
+// kind names the channel in log and error messages.
+func createProvider(channel models.Channel, kind string, provider *models.Provider, logger *logrus.Entry) (interface{}, error) {
+	if provider == nil {
+		logger.Error("Provider cannot be nil")
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	logger = logger.WithFields(map[string]interface{}{
+		"providerUUID": provider.UUID,
+		"providerCode": provider.Code,
+		"providerImpl": provider.Provider,
+	})
+
+	// Reuse the instance created for this version of the provider, its credentials are already
+	// decrypted and its HTTP connections are open
+	if instance := cachedProvider(channel, provider); instance != nil {
+		logger.Debugf("Using cached %s provider instance", kind)
+		return instance, nil
+	}
+
+	logger.Debugf("Creating %s provider instance", kind)
+
+	providerType, ok := registry.Lookup(channel, provider.Provider)
+	if !ok {
+		unsupportedErr := "unsupported " + kind + " provider implementation: " + provider.Provider
+		logger.Error(unsupportedErr)
+		return nil, errors.New(unsupportedErr)
+	}
+
+	logger.Infof("Creating %s %s provider", providerType.DisplayName, kind)
+	instance, err := providerType.Factory(provider)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to create %s %s provider", providerType.DisplayName, kind)
+		return nil, err
+	}
+	logger.Debugf("%s %s provider created successfully", providerType.DisplayName, kind)
+	return cacheProvider(channel, provider, instance), nil
+}
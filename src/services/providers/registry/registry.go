@@ -0,0 +1,127 @@
+// Package registry holds the provider implementations known to the service. Each implementation
+// registers itself from an init function in its own package, so adding a provider does not require
+// editing the channel factories.
+package registry
+
+import (
+	"delivery/models"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field types used in configuration schemas
+const (
+	FieldString  = "string"
+	FieldInteger = "integer"
+	FieldBoolean = "boolean"
+)
+
+// Factory creates a provider instance from its database configuration. The instance must implement
+// the service interface of the provider's channel.
+type Factory func(provider *models.Provider) (interface{}, error)
+
+// Capabilities describes what a provider implementation supports
+type Capabilities struct {
+	Attachments   bool `json:"attachments"`   // Sends files or media with messages
+	Templates     bool `json:"templates"`     // Sends templates stored at the provider, using templateIds
+	StatusPolling bool `json:"statusPolling"` // GetStatus looks up the delivery status at the provider
+	Bulk          bool `json:"bulk"`          // Sends to several recipients in a single request
+}
+
+// Field describes a single config or secure config field of a provider implementation
+type Field struct {
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	Required     bool        `json:"required"`
+	RequiredWith string      `json:"requiredWith,omitempty"` // Required when this other field is set to true or a non-empty value
+	Default      interface{} `json:"default,omitempty"`
+	Enum         []string    `json:"enum,omitempty"` // Allowed values of a string field, compared case insensitively
+	Description  string      `json:"description,omitempty"`
+}
+
+// ProviderType describes a provider implementation, the value of the provider column
+type ProviderType struct {
+	Name               string         `json:"name"`
+	Aliases            []string       `json:"aliases,omitempty"` // Other provider column values accepted for this implementation
+	DisplayName        string         `json:"displayName"`
+	Channel            models.Channel `json:"channel"`
+	Capabilities       Capabilities   `json:"capabilities"`
+	ConfigSchema       []Field        `json:"configSchema"`
+	SecureConfigSchema []Field        `json:"secureConfigSchema"`
+	Factory            Factory        `json:"-"`
+}
+
+var (
+	mu    sync.RWMutex
+	types = map[string]*ProviderType{}
+)
+
+// key returns the registry key of a provider name on a channel
+func key(channel models.Channel, name string) string {
+	return string(channel) + "/" + strings.ToUpper(name)
+}
+
+// Register adds a provider implementation to the registry. It panics when the name or one of the
+// aliases is already registered on the channel, as that is a programming error.
+func Register(providerType ProviderType) {
+	if providerType.Name == "" || providerType.Channel == "" || providerType.Factory == nil {
+		panic("registry: provider type needs a name, channel and factory")
+	}
+	providerType.Name = strings.ToUpper(providerType.Name)
+	if providerType.ConfigSchema == nil {
+		providerType.ConfigSchema = []Field{}
+	}
+	if providerType.SecureConfigSchema == nil {
+		providerType.SecureConfigSchema = []Field{}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, name := range append([]string{providerType.Name}, providerType.Aliases...) {
+		k := key(providerType.Channel, name)
+		if _, exists := types[k]; exists {
+			panic(fmt.Sprintf("registry: provider type %s already registered for channel %s", name, providerType.Channel))
+		}
+		types[k] = &providerType
+	}
+}
+
+// Lookup returns the provider implementation registered under a name or alias on a channel,
+// the name is case insensitive
+func Lookup(channel models.Channel, name string) (*ProviderType, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	providerType, ok := types[key(channel, name)]
+	return providerType, ok
+}
+
+// List returns the registered provider implementations ordered by channel and name, optionally
+// restricted to one channel
+func List(channel models.Channel) []ProviderType {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := []ProviderType{}
+	for k, providerType := range types {
+		// Aliases share the entry of their implementation
+		if k != key(providerType.Channel, providerType.Name) {
+			continue
+		}
+		if channel != "" && providerType.Channel != channel {
+			continue
+		}
+		list = append(list, *providerType)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Channel != list[j].Channel {
+			return list[i].Channel < list[j].Channel
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
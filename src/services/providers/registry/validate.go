@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"delivery/models"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// placeholderMarkers are fragments of the sample values found in documentation and env templates,
+// a secret containing one was never replaced with a real credential
+var placeholderMarkers = []string{"your-", "your_", "-here", "_here"}
+
+// ValidationError lists the problems found in a provider configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Resolve returns the implementation registered for a provider on a channel, or a ValidationError
+// when there is none
+func Resolve(channel models.Channel, name string) (*ProviderType, error) {
+	providerType, ok := Lookup(channel, name)
+	if !ok {
+		return nil, &ValidationError{Problems: []string{
+			fmt.Sprintf("provider %q is not supported on channel %q", name, channel),
+		}}
+	}
+	return providerType, nil
+}
+
+// ValidateConfig checks a config against the config schema of the implementation
+func (t *ProviderType) ValidateConfig(config models.JSON) error {
+	return validationError(validateFields("config", t.ConfigSchema, config, false))
+}
+
+// ValidateSecureConfig checks a plain text secure config against the secure config schema of the
+// implementation. Secrets that still hold placeholder values are rejected.
+func (t *ProviderType) ValidateSecureConfig(secureConfig models.JSON) error {
+	return validationError(validateFields("secureConfig", t.SecureConfigSchema, secureConfig, true))
+}
+
+// validationError wraps problems in a ValidationError, or returns nil when there are none
+func validationError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validateFields checks the values of the fields in a schema. Values that are not in the schema are
+// left alone, so older configurations keep working when a field is dropped.
+func validateFields(prefix string, fields []Field, values models.JSON, secret bool) []string {
+	var problems []string
+	for _, field := range fields {
+		name := prefix + "." + field.Name
+		value, present := values[field.Name]
+		if !present || value == nil || value == "" {
+			if field.Required {
+				problems = append(problems, name+" is required")
+			} else if field.RequiredWith != "" && isSet(values[field.RequiredWith]) {
+				problems = append(problems, fmt.Sprintf("%s is required when %s.%s is set", name, prefix, field.RequiredWith))
+			}
+			continue
+		}
+
+		switch field.Type {
+		case FieldString:
+			text, ok := value.(string)
+			if !ok {
+				problems = append(problems, name+" must be a string")
+				continue
+			}
+			if len(field.Enum) > 0 && !containsFold(field.Enum, text) {
+				problems = append(problems, fmt.Sprintf("%s must be one of %s", name, strings.Join(field.Enum, ", ")))
+			}
+			if secret && isPlaceholder(text) {
+				problems = append(problems, name+" contains a placeholder value")
+			}
+		case FieldInteger:
+			number, ok := value.(float64)
+			if !ok || number != math.Trunc(number) {
+				problems = append(problems, name+" must be an integer")
+			}
+		case FieldBoolean:
+			if _, ok := value.(bool); !ok {
+				problems = append(problems, name+" must be a boolean")
+			}
+		}
+	}
+	return problems
+}
+
+// isSet reports whether a config value is true or a non-empty value
+func isSet(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// containsFold reports whether a list contains a value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlaceholder reports whether a secret looks like an unreplaced sample value
+func isPlaceholder(value string) bool {
+	value = strings.ToLower(value)
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(value, marker) {
+			return true
+		}
+	}
+	return false
+}
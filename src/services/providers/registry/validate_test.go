@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"delivery/models"
+	"reflect"
+	"testing"
+)
+
+func TestValidateFields(t *testing.T) {
+	fields := []Field{
+		{Name: "host", Type: FieldString, Required: true},
+		{Name: "security", Type: FieldString, Enum: []string{"none", "tls", "starttls"}},
+		{Name: "port", Type: FieldInteger},
+		{Name: "sandbox", Type: FieldBoolean},
+		{Name: "username", Type: FieldString, RequiredWith: "auth"},
+		{Name: "auth", Type: FieldBoolean},
+	}
+
+	tests := []struct {
+		name   string
+		values models.JSON
+		want   []string
+	}{
+		{
+			name:   "valid values",
+			values: models.JSON{"host": "smtp.example.com", "security": "TLS", "port": float64(587), "sandbox": true},
+		},
+		{
+			name:   "unknown fields are left alone",
+			values: models.JSON{"host": "smtp.example.com", "legacy": "value"},
+		},
+		{
+			name:   "missing required field",
+			values: models.JSON{"port": float64(25)},
+			want:   []string{"config.host is required"},
+		},
+		{
+			name:   "empty required field",
+			values: models.JSON{"host": ""},
+			want:   []string{"config.host is required"},
+		},
+		{
+			name:   "value outside the enum",
+			values: models.JSON{"host": "smtp.example.com", "security": "ssl"},
+			want:   []string{"config.security must be one of none, tls, starttls"},
+		},
+		{
+			name:   "wrong types",
+			values: models.JSON{"host": float64(1), "port": float64(1.5), "sandbox": "true"},
+			want:   []string{"config.host must be a string", "config.port must be an integer", "config.sandbox must be a boolean"},
+		},
+		{
+			name:   "field required with another field",
+			values: models.JSON{"host": "smtp.example.com", "auth": true},
+			want:   []string{"config.username is required when config.auth is set"},
+		},
+		{
+			name:   "field not required when the other field is false",
+			values: models.JSON{"host": "smtp.example.com", "auth": false},
+		},
+		{
+			name:   "placeholders are allowed outside secrets",
+			values: models.JSON{"host": "your-smtp-host"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateFields("config", fields, tt.values, false)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateFields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSecureConfigPlaceholders(t *testing.T) {
+	providerType := &ProviderType{
+		SecureConfigSchema: []Field{{Name: "apiKey", Type: FieldString, Required: true}},
+	}
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		wantErr bool
+	}{
+		{name: "real key", apiKey: "SG.a1b2c3d4e5"},
+		{name: "your- prefix", apiKey: "your-api-key", wantErr: true},
+		{name: "your_ prefix in upper case", apiKey: "YOUR_API_KEY", wantErr: true},
+		{name: "-here suffix", apiKey: "api-key-here", wantErr: true},
+		{name: "_here suffix", apiKey: "paste_key_here", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := providerType.ValidateSecureConfig(models.JSON{"apiKey": tt.apiKey})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSecureConfig(%q) error = %v, wantErr %v", tt.apiKey, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package sms
+
+import (
+	"bytes"
+	apitypes "delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// InfobipProvider implements the SMSService interface using the Infobip SMS API
+type InfobipProvider struct {
+	APIKey     string
+	FromNumber string
+	BaseURL    string
+	Client     *http.Client
+	Provider   *models.Provider
+}
+
+// InfobipConfig holds the Infobip provider configuration
+type InfobipConfig struct {
+	BaseURL    string `json:"baseUrl,omitempty"`    // Account specific, e.g. https://xxxxx.api.infobip.com
+	FromNumber string `json:"fromNumber,omitempty"` // Number or alphanumeric sender ID
+}
+
+// InfobipSecureConfig holds the Infobip provider secure configuration
+type InfobipSecureConfig struct {
+	APIKey string `json:"apiKey,omitempty"`
+}
+
+// infobipDestination is a recipient of an Infobip message
+type infobipDestination struct {
+	To string `json:"to"`
+}
+
+// infobipStatus is the status object Infobip returns for a message
+type infobipStatus struct {
+	GroupName   string `json:"groupName"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "INFOBIP",
+		DisplayName:  "Infobip",
+		Channel:      models.ChannelSMS,
+		Capabilities: registry.Capabilities{StatusPolling: true, Bulk: true},
+		ConfigSchema: []registry.Field{
+			{Name: "baseUrl", Type: registry.FieldString, Required: true, Description: "Account specific API base URL"},
+			{Name: "fromNumber", Type: registry.FieldString, Required: true, Description: "Sender number or alphanumeric sender ID"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "apiKey", Type: registry.FieldString, Required: true, Description: "Infobip API key"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewInfobipProviderFromDB(provider)
+		},
+	})
+}
+
+// NewInfobipProviderFromDB creates a new Infobip SMS provider using database configuration
+func NewInfobipProviderFromDB(provider *models.Provider) (*InfobipProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config InfobipConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig InfobipSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields, Infobip has no shared base URL
+	if config.BaseURL == "" {
+		return nil, errors.New("base URL not set in provider configuration")
+	}
+
+	if secureConfig.APIKey == "" {
+		return nil, errors.New("API key not set in provider configuration")
+	}
+
+	if config.FromNumber == "" {
+		return nil, errors.New("from number not set in provider configuration")
+	}
+
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+
+	return &InfobipProvider{
+		APIKey:     strings.TrimSpace(secureConfig.APIKey),
+		FromNumber: config.FromNumber,
+		BaseURL:    baseURL,
+		Client:     helper.NewProviderHTTPClient(10 * time.Second),
+		Provider:   provider,
+	}, nil
+}
+
+// Send implements the SMSService.Send method
+func (p *InfobipProvider) Send(to string, message string) error {
+	return p.SendBulk([]string{to}, message)
+}
+
+// SendBulk implements the SMSService.SendBulk method.
+// Infobip accepts all recipients of a message in a single request.
+func (p *InfobipProvider) SendBulk(to []string, message string) error {
+	destinations := make([]infobipDestination, len(to))
+	for i, recipient := range to {
+		destinations[i] = infobipDestination{To: strings.TrimPrefix(recipient, "+")}
+	}
+
+	request := map[string]interface{}{
+		"messages": []map[string]interface{}{
+			{
+				"from":         p.FromNumber,
+				"destinations": destinations,
+				"text":         message,
+			},
+		},
+	}
+
+	return p.sendRequest(request, to)
+}
+
+// SendTemplate implements the SMSService.SendTemplate method
+// The template is rendered on the server side and sent via the normal Send method
+func (p *InfobipProvider) SendTemplate(to string, templateName string, params map[string]string) error {
+	renderedContent, exists := params["rendered_content"]
+	if !exists {
+		return errors.New("rendered_content not found in params")
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"to":       to,
+		"template": templateName,
+		"content":  renderedContent,
+		"provider": "infobip",
+	}).Debug("Sending template SMS via Infobip")
+
+	return p.Send(to, renderedContent)
+}
+
+// sendRequest sends a request to the Infobip advanced text endpoint
+func (p *InfobipProvider) sendRequest(request map[string]interface{}, to []string) error {
+	endpoint := p.BaseURL + "/sms/2/text/advanced"
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"endpoint":   endpoint,
+		"from":       p.FromNumber,
+		"recipients": len(to),
+	}).Debug("Sending Infobip SMS API request")
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	p.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		helper.Log.WithFields(map[string]interface{}{
+			"statusCode": resp.StatusCode,
+			"response":   string(body),
+		}).Error("Infobip API returned an error response")
+		return fmt.Errorf("infobip API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	// Individual destinations can still be rejected in an accepted request
+	var response struct {
+		Messages []struct {
+			To        string        `json:"to"`
+			MessageID string        `json:"messageId"`
+			Status    infobipStatus `json:"status"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse Infobip response: %w", err)
+	}
+
+	var lastErr error
+	for _, message := range response.Messages {
+		if message.Status.GroupName == "REJECTED" {
+			lastErr = fmt.Errorf("infobip API error: %s, status: %s", message.Status.Description, message.Status.Name)
+			helper.Log.WithError(lastErr).WithField("recipient", message.To).Error("Failed to send SMS to recipient")
+		}
+	}
+	return lastErr
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by fetching the account balance
+func (p *InfobipProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", p.BaseURL+"/account/1/balance", nil)
+	if err != nil {
+		return err
+	}
+	p.authorize(req)
+	return helper.CheckCredentials(p.Client, req)
+}
+
+// GetStatus implements the SMSService.GetStatus method using the Infobip message logs
+func (p *InfobipProvider) GetStatus(messageID string) (apitypes.DeliveryStatus, error) {
+	endpoint := p.BaseURL + "/sms/1/logs?messageId=" + url.QueryEscape(messageID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	p.authorize(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return apitypes.DeliveryStatus{}, fmt.Errorf("infobip API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	var logs struct {
+		Results []struct {
+			SentAt string        `json:"sentAt"`
+			DoneAt string        `json:"doneAt"`
+			Status infobipStatus `json:"status"`
+			Error  struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &logs); err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+
+	if len(logs.Results) == 0 {
+		return apitypes.DeliveryStatus{}, fmt.Errorf("no Infobip log found for message %s", messageID)
+	}
+
+	result := logs.Results[0]
+	details := result.Status.Description
+	if result.Error.Name != "" && result.Error.Name != "NO_ERROR" {
+		details = fmt.Sprintf("Error: %s, Error message: %s", result.Error.Name, result.Error.Description)
+	}
+
+	timestamp := result.DoneAt
+	if timestamp == "" {
+		timestamp = result.SentAt
+	}
+
+	return apitypes.DeliveryStatus{
+		MessageID: messageID,
+		Status:    strings.ToLower(result.Status.GroupName),
+		Details:   details,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// authorize adds the Infobip API key to a request
+func (p *InfobipProvider) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "App "+p.APIKey)
+}
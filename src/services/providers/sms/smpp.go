@@ -0,0 +1,628 @@
+package sms
+
+import (
+	apitypes "delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// smppDataCodingDefault is the SMSC default alphabet, usually GSM 03.38
+	smppDataCodingDefault byte = 0x00
+	// smppDataCodingIA5 is IA5 (ASCII), for SMSCs whose default alphabet is not GSM 03.38
+	smppDataCodingIA5 byte = 0x01
+	// smppDataCodingLatin1 is ISO-8859-1, only decoded for mobile originated messages
+	smppDataCodingLatin1 byte = 0x03
+	// smppDataCodingUCS2 is UCS-2 (UTF-16BE) for messages outside the default alphabet
+	smppDataCodingUCS2 byte = 0x08
+
+	// Message sizes in characters for a single message and for each part of a concatenated message
+	smppDefaultSingleLength  = 160
+	smppDefaultSegmentLength = 153
+	smppUCS2SingleLength     = 70
+	smppUCS2SegmentLength    = 67
+)
+
+// Alphabets for messages that need no UCS-2, configured per provider
+const (
+	smppAlphabetGSM = "GSM"
+	smppAlphabetIA5 = "IA5"
+)
+
+// smppReceiptField matches the fields of the delivery receipt text in SMPP 3.4 Appendix B
+var smppReceiptField = regexp.MustCompile(`(?i)\b(id|stat|err|done date):\s*(\S+)`)
+
+// smppStatusNames maps receipt states to the status names reported by GetStatus
+var smppStatusNames = map[string]string{
+	"ENROUTE": "enroute",
+	"DELIVRD": "delivered",
+	"EXPIRED": "expired",
+	"DELETED": "deleted",
+	"UNDELIV": "undeliverable",
+	"ACCEPTD": "accepted",
+	"UNKNOWN": "unknown",
+	"REJECTD": "rejected",
+}
+
+// smppConcatReference numbers concatenated messages so handsets can reassemble them
+var smppConcatReference uint32
+
+// SMPPProvider implements the SMSService interface over an SMPP 3.4 transceiver bind
+type SMPPProvider struct {
+	FromNumber         string
+	SourceTON          byte
+	SourceNPI          byte
+	DestTON            byte
+	DestNPI            byte
+	RegisteredDelivery bool
+	Alphabet           string // GSM or IA5, messages outside it are sent as UCS-2
+	Provider           *models.Provider
+
+	session       *smppSession
+	lastMessageID string
+}
+
+// SMPPConfig holds the SMPP provider configuration
+type SMPPConfig struct {
+	Host               string `json:"host,omitempty"`
+	Port               int    `json:"port,omitempty"`
+	TLS                bool   `json:"tls,omitempty"`
+	SystemID           string `json:"systemId,omitempty"`
+	SystemType         string `json:"systemType,omitempty"`
+	FromNumber         string `json:"fromNumber,omitempty"` // Number or alphanumeric sender ID
+	SourceTON          *int   `json:"sourceTon,omitempty"`
+	SourceNPI          *int   `json:"sourceNpi,omitempty"`
+	DestTON            *int   `json:"destTon,omitempty"`
+	DestNPI            *int   `json:"destNpi,omitempty"`
+	EnquireLinkSeconds int    `json:"enquireLinkSeconds,omitempty"`
+	TimeoutSeconds     int    `json:"timeoutSeconds,omitempty"`
+	DeliveryReceipts   *bool  `json:"deliveryReceipts,omitempty"`
+	Alphabet           string `json:"alphabet,omitempty"`
+}
+
+// SMPPSecureConfig holds the SMPP provider secure configuration
+type SMPPSecureConfig struct {
+	Password string `json:"password,omitempty"`
+}
+
+// DeliveryReceipt is a delivery report received on an SMPP bind
+type DeliveryReceipt struct {
+	ProviderUUID string
+	MessageID    string // Message ID returned by submit_sm_resp
+	State        string // Receipt stat, e.g. DELIVRD, UNDELIV or EXPIRED
+	ErrorCode    string
+	Address      string // Recipient of the original message
+	Timestamp    time.Time
+}
+
+// InboundMessage is a mobile originated message received on an SMPP bind
+type InboundMessage struct {
+	ProviderUUID string
+	From         string
+	To           string
+	Body         string
+	ReceivedAt   time.Time
+}
+
+// deliveryReceiptHandler receives the delivery receipts of all SMPP binds
+var deliveryReceiptHandler struct {
+	sync.RWMutex
+	handle func(DeliveryReceipt)
+}
+
+// SetDeliveryReceiptHandler registers the function that is called for each SMPP delivery receipt
+func SetDeliveryReceiptHandler(handler func(DeliveryReceipt)) {
+	deliveryReceiptHandler.Lock()
+	defer deliveryReceiptHandler.Unlock()
+	deliveryReceiptHandler.handle = handler
+}
+
+// dispatchDeliveryReceipt passes a receipt to the registered handler
+func dispatchDeliveryReceipt(receipt DeliveryReceipt) {
+	deliveryReceiptHandler.RLock()
+	handle := deliveryReceiptHandler.handle
+	deliveryReceiptHandler.RUnlock()
+
+	if handle == nil {
+		helper.Log.WithFields(map[string]interface{}{
+			"providerUUID": receipt.ProviderUUID,
+			"messageId":    receipt.MessageID,
+			"state":        receipt.State,
+		}).Warn("No handler registered for SMPP delivery receipt")
+		return
+	}
+	handle(receipt)
+}
+
+// inboundMessageHandler receives the mobile originated messages of all SMPP binds
+var inboundMessageHandler struct {
+	sync.RWMutex
+	handle func(InboundMessage)
+}
+
+// SetInboundMessageHandler registers the function that is called for each SMPP mobile originated message
+func SetInboundMessageHandler(handler func(InboundMessage)) {
+	inboundMessageHandler.Lock()
+	defer inboundMessageHandler.Unlock()
+	inboundMessageHandler.handle = handler
+}
+
+// dispatchInboundMessage passes a mobile originated message to the registered handler
+func dispatchInboundMessage(message InboundMessage) {
+	inboundMessageHandler.RLock()
+	handle := inboundMessageHandler.handle
+	inboundMessageHandler.RUnlock()
+
+	if handle == nil {
+		helper.Log.WithFields(map[string]interface{}{
+			"providerUUID": message.ProviderUUID,
+			"from":         message.From,
+		}).Warn("No handler registered for SMPP mobile originated message")
+		return
+	}
+	handle(message)
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "SMPP",
+		DisplayName:  "SMPP",
+		Channel:      models.ChannelSMS,
+		Capabilities: registry.Capabilities{StatusPolling: true},
+		ConfigSchema: []registry.Field{
+			{Name: "host", Type: registry.FieldString, Required: true, Description: "SMSC host name"},
+			{Name: "port", Type: registry.FieldInteger, Default: 2775},
+			{Name: "tls", Type: registry.FieldBoolean, Default: false, Description: "Connect over TLS"},
+			{Name: "systemId", Type: registry.FieldString, Required: true, Description: "Bind system_id"},
+			{Name: "systemType", Type: registry.FieldString, Description: "Bind system_type"},
+			{Name: "fromNumber", Type: registry.FieldString, Required: true, Description: "Sender number or alphanumeric sender ID"},
+			{Name: "sourceTon", Type: registry.FieldInteger, Description: "Source address TON, 1 for numbers and 5 for alphanumeric senders by default"},
+			{Name: "sourceNpi", Type: registry.FieldInteger, Description: "Source address NPI, 1 for numbers and 0 for alphanumeric senders by default"},
+			{Name: "destTon", Type: registry.FieldInteger, Default: 1, Description: "Destination address TON"},
+			{Name: "destNpi", Type: registry.FieldInteger, Default: 1, Description: "Destination address NPI"},
+			{Name: "enquireLinkSeconds", Type: registry.FieldInteger, Default: 30, Description: "Keepalive interval"},
+			{Name: "timeoutSeconds", Type: registry.FieldInteger, Default: 10, Description: "Connect and response timeout"},
+			{Name: "deliveryReceipts", Type: registry.FieldBoolean, Default: true, Description: "Request delivery receipts"},
+			{Name: "alphabet", Type: registry.FieldString, Default: smppAlphabetGSM, Enum: []string{smppAlphabetGSM, smppAlphabetIA5}, Description: "GSM sends the GSM 03.38 default alphabet as data_coding 0, IA5 sends ASCII as data_coding 1"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "password", Type: registry.FieldString, Description: "Bind password"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewSMPPProviderFromDB(provider)
+		},
+	})
+}
+
+// NewSMPPProviderFromDB creates a new SMPP SMS provider using database configuration.
+// Providers with the same UUID share one bind, which is opened on first use.
+func NewSMPPProviderFromDB(provider *models.Provider) (*SMPPProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config SMPPConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig SMPPSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if config.Host == "" {
+		return nil, errors.New("host not set in provider configuration")
+	}
+
+	if config.SystemID == "" {
+		return nil, errors.New("system ID not set in provider configuration")
+	}
+
+	if config.FromNumber == "" {
+		return nil, errors.New("from number not set in provider configuration")
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 2775
+	}
+
+	enquireLink := time.Duration(config.EnquireLinkSeconds) * time.Second
+	if enquireLink <= 0 {
+		enquireLink = 30 * time.Second
+	}
+
+	timeout := time.Duration(config.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	// Numeric senders are international numbers, anything else is an alphanumeric sender ID
+	fromNumber := strings.TrimPrefix(config.FromNumber, "+")
+	sourceTON, sourceNPI := 5, 0
+	if isNumeric(fromNumber) {
+		sourceTON, sourceNPI = 1, 1
+	} else {
+		fromNumber = config.FromNumber
+	}
+
+	registeredDelivery := config.DeliveryReceipts == nil || *config.DeliveryReceipts
+
+	alphabet := strings.ToUpper(config.Alphabet)
+	switch alphabet {
+	case "":
+		alphabet = smppAlphabetGSM
+	case smppAlphabetGSM, smppAlphabetIA5:
+	default:
+		return nil, fmt.Errorf("unsupported alphabet: %s", config.Alphabet)
+	}
+
+	session := smppSessionFor(provider.UUID, smppSessionConfig{
+		Host:        config.Host,
+		Port:        port,
+		TLS:         config.TLS,
+		SystemID:    config.SystemID,
+		Password:    secureConfig.Password,
+		SystemType:  config.SystemType,
+		EnquireLink: enquireLink,
+		Timeout:     timeout,
+	})
+
+	return &SMPPProvider{
+		FromNumber:         fromNumber,
+		SourceTON:          configByte(config.SourceTON, sourceTON),
+		SourceNPI:          configByte(config.SourceNPI, sourceNPI),
+		DestTON:            configByte(config.DestTON, 1),
+		DestNPI:            configByte(config.DestNPI, 1),
+		RegisteredDelivery: registeredDelivery,
+		Alphabet:           alphabet,
+		Provider:           provider,
+		session:            session,
+	}, nil
+}
+
+// Copy returns a copy sharing the bind but not the last message ID, cached instances are copied for each use
+func (p *SMPPProvider) Copy() interface{} {
+	c := *p
+	c.lastMessageID = ""
+	return &c
+}
+
+// Send implements the SMSService.Send method.
+// Long messages are split into parts with a concatenation UDH, a receipt is only requested for the last part.
+func (p *SMPPProvider) Send(to string, message string) error {
+	dataCoding, segments, err := segmentSMPPMessage(message, p.Alphabet)
+	if err != nil {
+		return err
+	}
+
+	for i, segment := range segments {
+		shortMessage := &smppShortMessage{
+			SourceTON:    p.SourceTON,
+			SourceNPI:    p.SourceNPI,
+			SourceAddr:   p.FromNumber,
+			DestTON:      p.DestTON,
+			DestNPI:      p.DestNPI,
+			DestAddr:     strings.TrimPrefix(to, "+"),
+			DataCoding:   dataCoding,
+			ShortMessage: segment,
+		}
+		if len(segments) > 1 {
+			shortMessage.ESMClass = smppESMClassUDHI
+		}
+		last := i == len(segments)-1
+		if last && p.RegisteredDelivery {
+			shortMessage.RegisteredDelivery = 1
+		}
+
+		resp, err := p.session.request(smppSubmitSM, shortMessage.encode())
+		if err != nil {
+			return err
+		}
+		if resp.Status != smppStatusOK {
+			return fmt.Errorf("SMPP submit_sm rejected with status 0x%08X", resp.Status)
+		}
+
+		r := &pduReader{data: resp.Body}
+		messageID, _ := r.cstring()
+
+		helper.Log.WithFields(map[string]interface{}{
+			"to":        to,
+			"messageId": messageID,
+			"part":      i + 1,
+			"parts":     len(segments),
+		}).Debug("SMPP submit_sm accepted")
+
+		if last {
+			p.lastMessageID = messageID
+		}
+	}
+
+	return nil
+}
+
+// LastMessageID returns the SMSC message ID of the last message sent, delivery receipts refer to it
+func (p *SMPPProvider) LastMessageID() string {
+	return p.lastMessageID
+}
+
+// SendBulk implements the SMSService.SendBulk method
+func (p *SMPPProvider) SendBulk(to []string, message string) error {
+	var lastErr error
+	for _, recipient := range to {
+		if err := p.Send(recipient, message); err != nil {
+			lastErr = err
+			helper.Log.WithError(err).WithField("recipient", recipient).Error("Failed to send SMS to recipient")
+		}
+	}
+	return lastErr
+}
+
+// SendTemplate implements the SMSService.SendTemplate method
+// The template is rendered on the server side and sent via the normal Send method
+func (p *SMPPProvider) SendTemplate(to string, templateName string, params map[string]string) error {
+	renderedContent, exists := params["rendered_content"]
+	if !exists {
+		return errors.New("rendered_content not found in params")
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"to":       to,
+		"template": templateName,
+		"content":  renderedContent,
+		"provider": "smpp",
+	}).Debug("Sending template SMS via SMPP")
+
+	return p.Send(to, renderedContent)
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by waiting for the bind
+func (p *SMPPProvider) CheckCredentials() error {
+	_, err := p.session.waitBound()
+	return err
+}
+
+// GetStatus implements the SMSService.GetStatus method with query_sm
+func (p *SMPPProvider) GetStatus(messageID string) (apitypes.DeliveryStatus, error) {
+	var w pduWriter
+	w.cstring(messageID)
+	w.WriteByte(p.SourceTON)
+	w.WriteByte(p.SourceNPI)
+	w.cstring(p.FromNumber)
+
+	resp, err := p.session.request(smppQuerySM, w.Bytes())
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	if resp.Status != smppStatusOK {
+		return apitypes.DeliveryStatus{}, fmt.Errorf("SMPP query_sm rejected with status 0x%08X", resp.Status)
+	}
+
+	r := &pduReader{data: resp.Body}
+	if _, err := r.cstring(); err != nil { // message_id
+		return apitypes.DeliveryStatus{}, err
+	}
+	finalDate, err := r.cstring()
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	state, err := r.byte()
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	errorCode, err := r.byte()
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+
+	details := ""
+	if errorCode != 0 {
+		details = fmt.Sprintf("Network error code: %d", errorCode)
+	}
+
+	status, ok := smppStatusNames[smppMessageStates[state]]
+	if !ok {
+		status = "unknown"
+	}
+
+	return apitypes.DeliveryStatus{
+		MessageID: messageID,
+		Status:    status,
+		Details:   details,
+		Timestamp: finalDate,
+	}, nil
+}
+
+// segmentSMPPMessage encodes a message and splits it into parts that fit a short message.
+// Messages outside the configured alphabet are sent as UCS-2.
+func segmentSMPPMessage(message string, alphabet string) (byte, [][]byte, error) {
+	if alphabet == smppAlphabetIA5 {
+		if chars, ok := encodeIA5(message); ok {
+			segments, err := splitSMPPMessage(chars, smppDefaultSingleLength, smppDefaultSegmentLength)
+			return smppDataCodingIA5, segments, err
+		}
+	} else if chars, ok := encodeGSM(message); ok {
+		segments, err := splitSMPPMessage(chars, smppDefaultSingleLength, smppDefaultSegmentLength)
+		return smppDataCodingDefault, segments, err
+	}
+
+	segments, err := splitSMPPMessage(encodeUCS2(message), smppUCS2SingleLength, smppUCS2SegmentLength)
+	return smppDataCodingUCS2, segments, err
+}
+
+// splitSMPPMessage returns the message as one short message when it fits, or as parts with a
+// concatenation UDH. A character, such as an escape sequence or surrogate pair, is never split.
+func splitSMPPMessage(chars []smppChar, singleLength int, segmentLength int) ([][]byte, error) {
+	length := 0
+	for _, char := range chars {
+		length += char.size
+	}
+	if length <= singleLength {
+		var data []byte
+		for _, char := range chars {
+			data = append(data, char.data...)
+		}
+		return [][]byte{data}, nil
+	}
+
+	var parts [][]byte
+	var part []byte
+	length = 0
+	for _, char := range chars {
+		if length+char.size > segmentLength {
+			parts = append(parts, part)
+			part, length = nil, 0
+		}
+		part = append(part, char.data...)
+		length += char.size
+	}
+	parts = append(parts, part)
+	return addConcatHeaders(parts)
+}
+
+// addConcatHeaders prefixes each part with the 8-bit reference concatenation UDH
+func addConcatHeaders(parts [][]byte) ([][]byte, error) {
+	if len(parts) > 255 {
+		return nil, fmt.Errorf("message is too long, it needs %d parts and at most 255 are allowed", len(parts))
+	}
+
+	reference := byte(atomic.AddUint32(&smppConcatReference, 1))
+	segments := make([][]byte, len(parts))
+	for i, part := range parts {
+		segments[i] = append([]byte{0x05, 0x00, 0x03, reference, byte(len(parts)), byte(i + 1)}, part...)
+	}
+	return segments, nil
+}
+
+// parseSMPPReceipt reads a delivery receipt from a deliver_sm, preferring the optional parameters
+// over the receipt text
+func parseSMPPReceipt(message *smppShortMessage) DeliveryReceipt {
+	receipt := DeliveryReceipt{
+		Address:   message.SourceAddr,
+		Timestamp: time.Now().UTC(),
+	}
+
+	for _, field := range smppReceiptField.FindAllStringSubmatch(string(message.ShortMessage), -1) {
+		switch strings.ToLower(field[1]) {
+		case "id":
+			receipt.MessageID = field[2]
+		case "stat":
+			receipt.State = strings.ToUpper(field[2])
+		case "err":
+			receipt.ErrorCode = field[2]
+		case "done date":
+			for _, layout := range []string{"0601021504", "060102150405"} {
+				if doneDate, err := time.Parse(layout, field[2]); err == nil {
+					receipt.Timestamp = doneDate
+					break
+				}
+			}
+		}
+	}
+
+	if id, ok := message.Params[smppTagReceiptedMsgID]; ok {
+		receipt.MessageID = strings.TrimRight(string(id), "\x00")
+	}
+	if state, ok := message.Params[smppTagMessageState]; ok && len(state) == 1 {
+		if name, ok := smppMessageStates[state[0]]; ok {
+			receipt.State = name
+		}
+	}
+
+	return receipt
+}
+
+// parseSMPPInbound reads a mobile originated message from a deliver_sm. The text is taken from
+// message_payload when short_message is empty, and a UDH is skipped.
+func parseSMPPInbound(message *smppShortMessage) InboundMessage {
+	data := message.ShortMessage
+	if payload, ok := message.Params[smppTagMessagePayload]; ok && len(data) == 0 {
+		data = payload
+	}
+	if message.ESMClass&smppESMClassUDHI != 0 && len(data) > 0 {
+		headerLength := int(data[0]) + 1
+		if headerLength > len(data) {
+			headerLength = len(data)
+		}
+		data = data[headerLength:]
+	}
+
+	var body string
+	switch message.DataCoding {
+	case smppDataCodingDefault:
+		body = decodeGSM(data)
+	case smppDataCodingLatin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		body = string(runes)
+	case smppDataCodingUCS2:
+		body = decodeUCS2(data)
+	default:
+		body = string(data)
+	}
+
+	return InboundMessage{
+		From:       smppAddress(message.SourceTON, message.SourceAddr),
+		To:         smppAddress(message.DestTON, message.DestAddr),
+		Body:       body,
+		ReceivedAt: time.Now().UTC(),
+	}
+}
+
+// smppAddress returns an international number in E.164 format, other addresses unchanged
+func smppAddress(ton byte, address string) string {
+	if ton == 1 && isNumeric(address) {
+		return "+" + address
+	}
+	return address
+}
+
+// ucs2Bytes encodes UTF-16 code units big endian
+func ucs2Bytes(units []uint16) []byte {
+	data := make([]byte, 0, len(units)*2)
+	for _, unit := range units {
+		data = append(data, byte(unit>>8), byte(unit))
+	}
+	return data
+}
+
+// isNumeric reports whether a sender is a phone number
+func isNumeric(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// configByte returns an optional TON or NPI setting, or the default when it is not set
+func configByte(value *int, def int) byte {
+	if value == nil {
+		return byte(def)
+	}
+	return byte(*value)
+}
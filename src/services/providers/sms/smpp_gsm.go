@@ -0,0 +1,125 @@
+package sms
+
+import (
+	"unicode/utf16"
+)
+
+// gsmEscape introduces a character of the GSM 03.38 extension table
+const gsmEscape byte = 0x1B
+
+// gsmBasicTable is the GSM 03.38 default alphabet in septet order. Position 0x1B is the escape
+// to the extension table and never encodes a character itself.
+var gsmBasicTable = []rune("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞ\x1bÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+
+// gsmExtensionTable holds the characters sent as the escape followed by their septet
+var gsmExtensionTable = map[rune]byte{
+	'\f': 0x0A,
+	'^':  0x14,
+	'{':  0x28,
+	'}':  0x29,
+	'\\': 0x2F,
+	'[':  0x3C,
+	'~':  0x3D,
+	']':  0x3E,
+	'|':  0x40,
+	'€':  0x65,
+}
+
+// gsmBasic and gsmExtensionChars are the reverse lookups of the tables above
+var (
+	gsmBasic          = map[rune]byte{}
+	gsmExtensionChars = map[byte]rune{}
+)
+
+func init() {
+	for i, r := range gsmBasicTable {
+		if byte(i) != gsmEscape {
+			gsmBasic[r] = byte(i)
+		}
+	}
+	for r, septet := range gsmExtensionTable {
+		gsmExtensionChars[septet] = r
+	}
+}
+
+// smppChar is one encoded character and the number of characters it takes of a short message
+type smppChar struct {
+	data []byte
+	size int
+}
+
+// encodeGSM encodes a message in the GSM 03.38 default alphabet, one septet per octet as SMPP
+// sends it. Extension characters take two septets. It reports false when a character is not in
+// the alphabet.
+func encodeGSM(message string) ([]smppChar, bool) {
+	chars := make([]smppChar, 0, len(message))
+	for _, r := range message {
+		if septet, ok := gsmBasic[r]; ok {
+			chars = append(chars, smppChar{data: []byte{septet}, size: 1})
+			continue
+		}
+		if septet, ok := gsmExtensionTable[r]; ok {
+			chars = append(chars, smppChar{data: []byte{gsmEscape, septet}, size: 2})
+			continue
+		}
+		return nil, false
+	}
+	return chars, true
+}
+
+// encodeIA5 encodes a printable ASCII message as IA5. The SMSC converts it to the GSM default
+// alphabet on the air interface, so extension characters still take two characters there.
+func encodeIA5(message string) ([]smppChar, bool) {
+	chars := make([]smppChar, 0, len(message))
+	for _, r := range message {
+		if r > 0x7E || (r < 0x20 && r != '\n' && r != '\r') {
+			return nil, false
+		}
+		size := 1
+		if _, ok := gsmExtensionTable[r]; ok {
+			size = 2
+		}
+		chars = append(chars, smppChar{data: []byte{byte(r)}, size: size})
+	}
+	return chars, true
+}
+
+// encodeUCS2 encodes a message as UTF-16BE. A surrogate pair stays one character of two units,
+// so it is never split across parts.
+func encodeUCS2(message string) []smppChar {
+	chars := make([]smppChar, 0, len(message))
+	for _, r := range message {
+		units := utf16.Encode([]rune{r})
+		chars = append(chars, smppChar{data: ucs2Bytes(units), size: len(units)})
+	}
+	return chars
+}
+
+// decodeGSM decodes GSM 03.38 septets sent one per octet. Unknown extension septets are dropped.
+func decodeGSM(data []byte) string {
+	runes := make([]rune, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		septet := data[i] & 0x7F
+		if septet == gsmEscape {
+			if i+1 < len(data) {
+				i++
+				if r, ok := gsmExtensionChars[data[i]&0x7F]; ok {
+					runes = append(runes, r)
+				}
+			}
+			continue
+		}
+		runes = append(runes, gsmBasicTable[septet])
+	}
+	return string(runes)
+}
+
+// decodeUCS2 decodes UTF-16BE text, a trailing odd byte is ignored
+func decodeUCS2(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
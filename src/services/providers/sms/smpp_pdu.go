@@ -0,0 +1,273 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SMPP 3.4 command IDs
+const (
+	smppGenericNack         uint32 = 0x80000000
+	smppBindTransceiver     uint32 = 0x00000009
+	smppBindTransceiverResp uint32 = 0x80000009
+	smppQuerySM             uint32 = 0x00000003
+	smppQuerySMResp         uint32 = 0x80000003
+	smppSubmitSM            uint32 = 0x00000004
+	smppSubmitSMResp        uint32 = 0x80000004
+	smppDeliverSM           uint32 = 0x00000005
+	smppDeliverSMResp       uint32 = 0x80000005
+	smppUnbind              uint32 = 0x00000006
+	smppUnbindResp          uint32 = 0x80000006
+	smppEnquireLink         uint32 = 0x00000015
+	smppEnquireLinkResp     uint32 = 0x80000015
+)
+
+// SMPP 3.4 command status and optional parameter tags used by the client
+const (
+	smppStatusOK            uint32 = 0x00000000
+	smppStatusInvalidCmdID  uint32 = 0x00000003
+	smppTagReceiptedMsgID   uint16 = 0x001E
+	smppTagMessageState     uint16 = 0x0427
+	smppTagMessagePayload   uint16 = 0x0424
+	smppInterfaceVersion34  byte   = 0x34
+	smppHeaderLength               = 16
+	smppMaxPDULength               = 64 * 1024
+	smppESMClassUDHI        byte   = 0x40
+	smppESMClassReceiptMask byte   = 0x3C
+	smppESMClassReceipt     byte   = 0x04
+)
+
+// smppMessageStates maps the message_state values of query_sm_resp and receipts to receipt stat names
+var smppMessageStates = map[byte]string{
+	1: "ENROUTE",
+	2: "DELIVRD",
+	3: "EXPIRED",
+	4: "DELETED",
+	5: "UNDELIV",
+	6: "ACCEPTD",
+	7: "UNKNOWN",
+	8: "REJECTD",
+}
+
+// smppPDU is a single SMPP protocol data unit
+type smppPDU struct {
+	CommandID uint32
+	Status    uint32
+	Sequence  uint32
+	Body      []byte
+}
+
+// encode returns the PDU in wire format
+func (p *smppPDU) encode() []byte {
+	data := make([]byte, smppHeaderLength+len(p.Body))
+	binary.BigEndian.PutUint32(data[0:], uint32(len(data)))
+	binary.BigEndian.PutUint32(data[4:], p.CommandID)
+	binary.BigEndian.PutUint32(data[8:], p.Status)
+	binary.BigEndian.PutUint32(data[12:], p.Sequence)
+	copy(data[smppHeaderLength:], p.Body)
+	return data
+}
+
+// readPDU reads one PDU from a connection
+func readPDU(r io.Reader) (*smppPDU, error) {
+	header := make([]byte, smppHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:])
+	if length < smppHeaderLength || length > smppMaxPDULength {
+		return nil, fmt.Errorf("invalid SMPP PDU length %d", length)
+	}
+
+	pdu := &smppPDU{
+		CommandID: binary.BigEndian.Uint32(header[4:]),
+		Status:    binary.BigEndian.Uint32(header[8:]),
+		Sequence:  binary.BigEndian.Uint32(header[12:]),
+		Body:      make([]byte, length-smppHeaderLength),
+	}
+	if _, err := io.ReadFull(r, pdu.Body); err != nil {
+		return nil, err
+	}
+	return pdu, nil
+}
+
+// pduWriter builds the body of a PDU
+type pduWriter struct {
+	bytes.Buffer
+}
+
+// cstring writes a NULL terminated string
+func (w *pduWriter) cstring(value string) {
+	w.WriteString(value)
+	w.WriteByte(0)
+}
+
+// octets writes a length prefixed octet string as used by short_message
+func (w *pduWriter) octets(value []byte) {
+	w.WriteByte(byte(len(value)))
+	w.Write(value)
+}
+
+// pduReader reads the body of a PDU
+type pduReader struct {
+	data []byte
+	pos  int
+}
+
+// errShortPDU is returned when a PDU body ends before all mandatory fields were read
+var errShortPDU = errors.New("SMPP PDU body is too short")
+
+// cstring reads a NULL terminated string
+func (r *pduReader) cstring() (string, error) {
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		return "", errShortPDU
+	}
+	value := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return value, nil
+}
+
+// byte reads a single octet
+func (r *pduReader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errShortPDU
+	}
+	value := r.data[r.pos]
+	r.pos++
+	return value, nil
+}
+
+// octets reads a length prefixed octet string
+func (r *pduReader) octets() ([]byte, error) {
+	length, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos+int(length) > len(r.data) {
+		return nil, errShortPDU
+	}
+	value := r.data[r.pos : r.pos+int(length)]
+	r.pos += int(length)
+	return value, nil
+}
+
+// tlvs reads the optional parameters that follow the mandatory fields
+func (r *pduReader) tlvs() map[uint16][]byte {
+	params := map[uint16][]byte{}
+	for r.pos+4 <= len(r.data) {
+		tag := binary.BigEndian.Uint16(r.data[r.pos:])
+		length := int(binary.BigEndian.Uint16(r.data[r.pos+2:]))
+		r.pos += 4
+		if r.pos+length > len(r.data) {
+			break
+		}
+		params[tag] = r.data[r.pos : r.pos+length]
+		r.pos += length
+	}
+	return params
+}
+
+// smppShortMessage holds the fields of a submit_sm or deliver_sm
+type smppShortMessage struct {
+	ServiceType        string
+	SourceTON          byte
+	SourceNPI          byte
+	SourceAddr         string
+	DestTON            byte
+	DestNPI            byte
+	DestAddr           string
+	ESMClass           byte
+	RegisteredDelivery byte
+	DataCoding         byte
+	ShortMessage       []byte
+	Params             map[uint16][]byte
+}
+
+// encode returns the submit_sm body for the message
+func (m *smppShortMessage) encode() []byte {
+	var w pduWriter
+	w.cstring(m.ServiceType)
+	w.WriteByte(m.SourceTON)
+	w.WriteByte(m.SourceNPI)
+	w.cstring(m.SourceAddr)
+	w.WriteByte(m.DestTON)
+	w.WriteByte(m.DestNPI)
+	w.cstring(m.DestAddr)
+	w.WriteByte(m.ESMClass)
+	w.WriteByte(0) // protocol_id
+	w.WriteByte(0) // priority_flag
+	w.cstring("")  // schedule_delivery_time
+	w.cstring("")  // validity_period
+	w.WriteByte(m.RegisteredDelivery)
+	w.WriteByte(0) // replace_if_present_flag
+	w.WriteByte(m.DataCoding)
+	w.WriteByte(0) // sm_default_msg_id
+	w.octets(m.ShortMessage)
+	return w.Bytes()
+}
+
+// decodeShortMessage parses a deliver_sm body
+func decodeShortMessage(body []byte) (*smppShortMessage, error) {
+	r := &pduReader{data: body}
+	m := &smppShortMessage{}
+	var err error
+
+	if m.ServiceType, err = r.cstring(); err != nil {
+		return nil, err
+	}
+	if m.SourceTON, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if m.SourceNPI, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if m.SourceAddr, err = r.cstring(); err != nil {
+		return nil, err
+	}
+	if m.DestTON, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if m.DestNPI, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if m.DestAddr, err = r.cstring(); err != nil {
+		return nil, err
+	}
+	if m.ESMClass, err = r.byte(); err != nil {
+		return nil, err
+	}
+	// protocol_id and priority_flag
+	for i := 0; i < 2; i++ {
+		if _, err = r.byte(); err != nil {
+			return nil, err
+		}
+	}
+	// schedule_delivery_time and validity_period
+	for i := 0; i < 2; i++ {
+		if _, err = r.cstring(); err != nil {
+			return nil, err
+		}
+	}
+	if m.RegisteredDelivery, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if _, err = r.byte(); err != nil { // replace_if_present_flag
+		return nil, err
+	}
+	if m.DataCoding, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if _, err = r.byte(); err != nil { // sm_default_msg_id
+		return nil, err
+	}
+	if m.ShortMessage, err = r.octets(); err != nil {
+		return nil, err
+	}
+	m.Params = r.tlvs()
+	return m, nil
+}
@@ -0,0 +1,416 @@
+package sms
+
+import (
+	"crypto/tls"
+	"delivery/helper"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// smppMinReconnectDelay is the wait before the first reconnect attempt
+	smppMinReconnectDelay = time.Second
+	// smppMaxReconnectDelay caps the exponential reconnect backoff
+	smppMaxReconnectDelay = 30 * time.Second
+)
+
+// errSMPPConnectionLost is returned to requests that were waiting for a response when the bind dropped
+var errSMPPConnectionLost = errors.New("SMPP connection lost before a response was received")
+
+// smppSessionConfig identifies a bind, a session is replaced when any of these change
+type smppSessionConfig struct {
+	Host        string
+	Port        int
+	TLS         bool
+	SystemID    string
+	Password    string
+	SystemType  string
+	EnquireLink time.Duration
+	Timeout     time.Duration
+}
+
+// smppSession is a persistent transceiver bind that reconnects automatically
+type smppSession struct {
+	providerUUID string
+	config       smppSessionConfig
+	sequence     uint32
+
+	mu      sync.Mutex
+	conn    net.Conn
+	ready   chan struct{} // Closed while the session is bound
+	pending map[uint32]chan *smppPDU
+	bindErr error // Why the last bind attempt failed, reported when waiting for the bind times out
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// smppSessions holds the open binds, one per provider
+var smppSessions = struct {
+	sync.Mutex
+	sessions map[string]*smppSession
+}{sessions: map[string]*smppSession{}}
+
+// smppSessionFor returns the bind of a provider, starting it when needed.
+// A bind whose configuration changed is closed and replaced.
+func smppSessionFor(providerUUID string, config smppSessionConfig) *smppSession {
+	smppSessions.Lock()
+	defer smppSessions.Unlock()
+
+	if session, ok := smppSessions.sessions[providerUUID]; ok {
+		if session.config == config {
+			return session
+		}
+		go session.close()
+	}
+
+	session := &smppSession{
+		providerUUID: providerUUID,
+		config:       config,
+		ready:        make(chan struct{}),
+		pending:      map[uint32]chan *smppPDU{},
+		closed:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	smppSessions.sessions[providerUUID] = session
+	go session.run()
+	return session
+}
+
+// CloseSMPPSession unbinds the session of a provider. The next message opens a new bind with the
+// current configuration, a deactivated provider gets none.
+func CloseSMPPSession(providerUUID string) {
+	smppSessions.Lock()
+	session, ok := smppSessions.sessions[providerUUID]
+	delete(smppSessions.sessions, providerUUID)
+	smppSessions.Unlock()
+
+	if ok {
+		go session.close()
+	}
+}
+
+// CloseSMPPSessions unbinds all open SMPP sessions
+func CloseSMPPSessions() {
+	smppSessions.Lock()
+	sessions := smppSessions.sessions
+	smppSessions.sessions = map[string]*smppSession{}
+	smppSessions.Unlock()
+
+	for _, session := range sessions {
+		session.close()
+	}
+}
+
+// run keeps the session bound until it is closed
+func (s *smppSession) run() {
+	defer close(s.done)
+
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"providerUUID": s.providerUUID,
+		"host":         s.config.Host,
+		"systemId":     s.config.SystemID,
+	})
+
+	delay := smppMinReconnectDelay
+	for {
+		conn, err := s.bind()
+		s.mu.Lock()
+		s.bindErr = err
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).WithField("retryIn", delay.String()).Error("Failed to bind SMPP session")
+		} else {
+			logger.Info("SMPP session bound")
+			delay = smppMinReconnectDelay
+			err = s.serve(conn)
+			if s.isClosed() {
+				return
+			}
+			logger.WithError(err).Warn("SMPP session lost, reconnecting")
+		}
+
+		select {
+		case <-s.closed:
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > smppMaxReconnectDelay {
+			delay = smppMaxReconnectDelay
+		}
+	}
+}
+
+// bind connects to the SMSC and binds as a transceiver
+func (s *smppSession) bind() (net.Conn, error) {
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	if s.config.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: s.config.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var w pduWriter
+	w.cstring(s.config.SystemID)
+	w.cstring(s.config.Password)
+	w.cstring(s.config.SystemType)
+	w.WriteByte(smppInterfaceVersion34)
+	w.WriteByte(0) // addr_ton
+	w.WriteByte(0) // addr_npi
+	w.cstring("")  // address_range
+
+	bindPDU := &smppPDU{CommandID: smppBindTransceiver, Sequence: s.nextSequence(), Body: w.Bytes()}
+	conn.SetDeadline(time.Now().Add(s.config.Timeout))
+	if _, err := conn.Write(bindPDU.encode()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := readPDU(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.CommandID != smppBindTransceiverResp {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected SMPP command 0x%08X in response to bind", resp.CommandID)
+	}
+	if resp.Status != smppStatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("SMPP bind rejected with status 0x%08X", resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// serve marks the session bound and handles PDUs until the connection fails
+func (s *smppSession) serve(conn net.Conn) error {
+	s.mu.Lock()
+	s.conn = conn
+	close(s.ready)
+	s.mu.Unlock()
+
+	stopKeepalive := make(chan struct{})
+	go s.keepalive(conn, stopKeepalive)
+
+	err := s.readLoop(conn)
+
+	close(stopKeepalive)
+	conn.Close()
+
+	// Fail the requests that were waiting on this connection
+	s.mu.Lock()
+	s.conn = nil
+	s.ready = make(chan struct{})
+	for sequence, response := range s.pending {
+		close(response)
+		delete(s.pending, sequence)
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+// readLoop dispatches responses to waiting requests and answers requests from the SMSC
+func (s *smppSession) readLoop(conn net.Conn) error {
+	for {
+		pdu, err := readPDU(conn)
+		if err != nil {
+			return err
+		}
+
+		if pdu.CommandID&smppGenericNack != 0 {
+			s.mu.Lock()
+			response, ok := s.pending[pdu.Sequence]
+			delete(s.pending, pdu.Sequence)
+			s.mu.Unlock()
+			if ok {
+				response <- pdu
+			}
+			continue
+		}
+
+		switch pdu.CommandID {
+		case smppEnquireLink:
+			err = s.write(conn, &smppPDU{CommandID: smppEnquireLinkResp, Sequence: pdu.Sequence})
+		case smppDeliverSM:
+			// deliver_sm_resp carries an empty message_id
+			err = s.write(conn, &smppPDU{CommandID: smppDeliverSMResp, Sequence: pdu.Sequence, Body: []byte{0}})
+			go s.handleDeliverSM(pdu)
+		case smppUnbind:
+			s.write(conn, &smppPDU{CommandID: smppUnbindResp, Sequence: pdu.Sequence})
+			return errors.New("SMSC requested unbind")
+		default:
+			err = s.write(conn, &smppPDU{CommandID: smppGenericNack, Status: smppStatusInvalidCmdID, Sequence: pdu.Sequence})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// keepalive sends enquire_link at the configured interval and drops the connection when it goes unanswered
+func (s *smppSession) keepalive(conn net.Conn, stop chan struct{}) {
+	ticker := time.NewTicker(s.config.EnquireLink)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.request(smppEnquireLink, nil); err != nil {
+				helper.Log.WithError(err).WithField("providerUUID", s.providerUUID).Warn("SMPP enquire_link failed, dropping connection")
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// handleDeliverSM passes delivery receipts and mobile originated messages to their registered handlers
+func (s *smppSession) handleDeliverSM(pdu *smppPDU) {
+	message, err := decodeShortMessage(pdu.Body)
+	if err != nil {
+		helper.Log.WithError(err).WithField("providerUUID", s.providerUUID).Warn("Failed to parse SMPP deliver_sm")
+		return
+	}
+
+	if message.ESMClass&smppESMClassReceiptMask != smppESMClassReceipt {
+		inbound := parseSMPPInbound(message)
+		inbound.ProviderUUID = s.providerUUID
+		dispatchInboundMessage(inbound)
+		return
+	}
+
+	receipt := parseSMPPReceipt(message)
+	receipt.ProviderUUID = s.providerUUID
+	dispatchDeliveryReceipt(receipt)
+}
+
+// request sends a PDU and waits for its response
+func (s *smppSession) request(commandID uint32, body []byte) (*smppPDU, error) {
+	conn, err := s.waitBound()
+	if err != nil {
+		return nil, err
+	}
+
+	sequence := s.nextSequence()
+	response := make(chan *smppPDU, 1)
+	s.mu.Lock()
+	s.pending[sequence] = response
+	s.mu.Unlock()
+
+	if err := s.write(conn, &smppPDU{CommandID: commandID, Sequence: sequence, Body: body}); err != nil {
+		s.mu.Lock()
+		delete(s.pending, sequence)
+		s.mu.Unlock()
+		conn.Close()
+		return nil, err
+	}
+
+	select {
+	case pdu, ok := <-response:
+		if !ok {
+			return nil, errSMPPConnectionLost
+		}
+		if pdu.CommandID == smppGenericNack {
+			return nil, fmt.Errorf("SMSC rejected the request with generic_nack status 0x%08X", pdu.Status)
+		}
+		return pdu, nil
+	case <-time.After(s.config.Timeout):
+		s.mu.Lock()
+		delete(s.pending, sequence)
+		s.mu.Unlock()
+		return nil, fmt.Errorf("timed out waiting for SMPP response to command 0x%08X", commandID)
+	}
+}
+
+// waitBound returns the connection once the session is bound
+func (s *smppSession) waitBound() (net.Conn, error) {
+	s.mu.Lock()
+	ready := s.ready
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-s.closed:
+		return nil, errors.New("SMPP session is closed")
+	case <-time.After(s.config.Timeout):
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.bindErr != nil {
+			return nil, fmt.Errorf("timed out waiting for SMPP bind: %w", s.bindErr)
+		}
+		return nil, errors.New("timed out waiting for SMPP bind")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil, errSMPPConnectionLost
+	}
+	return s.conn, nil
+}
+
+// write sends a PDU, writes are serialized so PDUs are never interleaved
+func (s *smppSession) write(conn net.Conn, pdu *smppPDU) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(s.config.Timeout))
+	_, err := conn.Write(pdu.encode())
+	return err
+}
+
+// nextSequence returns the next sequence number, SMPP allows 0x00000001 to 0x7FFFFFFF
+func (s *smppSession) nextSequence() uint32 {
+	for {
+		if sequence := atomic.AddUint32(&s.sequence, 1) & 0x7FFFFFFF; sequence != 0 {
+			return sequence
+		}
+	}
+}
+
+// isClosed reports whether close was called
+func (s *smppSession) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// close unbinds the session and stops reconnecting
+func (s *smppSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+
+		s.mu.Lock()
+		conn := s.conn
+		s.mu.Unlock()
+		if conn != nil {
+			// Give the SMSC a moment to answer the unbind before the connection is closed
+			s.write(conn, &smppPDU{CommandID: smppUnbind, Sequence: s.nextSequence()})
+			time.AfterFunc(time.Second, func() { conn.Close() })
+		}
+	})
+	<-s.done
+}
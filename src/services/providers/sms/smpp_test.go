@@ -0,0 +1,307 @@
+package sms
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGSMBasicTable(t *testing.T) {
+	if len(gsmBasicTable) != 128 {
+		t.Fatalf("gsmBasicTable has %d characters, want 128", len(gsmBasicTable))
+	}
+	for r, septet := range map[rune]byte{'@': 0x00, '$': 0x02, '\n': 0x0A, '_': 0x11, ' ': 0x20, 'A': 0x41, 'a': 0x61, 'à': 0x7F} {
+		if gsmBasic[r] != septet {
+			t.Errorf("gsmBasic[%q] = 0x%02X, want 0x%02X", r, gsmBasic[r], septet)
+		}
+	}
+}
+
+// stripConcatHeader checks the concatenation UDH of a part and returns its payload
+func stripConcatHeader(t *testing.T, segment []byte, total int, index int) []byte {
+	t.Helper()
+	if len(segment) < 6 || !bytes.Equal(segment[:3], []byte{0x05, 0x00, 0x03}) {
+		t.Fatalf("segment %d has no concatenation UDH: % X", index, segment)
+	}
+	if int(segment[4]) != total || int(segment[5]) != index {
+		t.Fatalf("segment UDH says part %d of %d, want %d of %d", segment[5], segment[4], index, total)
+	}
+	return segment[6:]
+}
+
+func TestSegmentSMPPMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        string
+		alphabet       string
+		wantDataCoding byte
+		wantParts      [][]byte // Payloads without the concatenation UDH
+	}{
+		{
+			name:           "GSM text",
+			message:        "Hello @ 5$",
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts:      [][]byte{{'H', 'e', 'l', 'l', 'o', ' ', 0x00, ' ', '5', 0x02}},
+		},
+		{
+			name:           "GSM accented characters",
+			message:        "é£ü",
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts:      [][]byte{{0x05, 0x01, 0x7E}},
+		},
+		{
+			name:           "GSM extension characters are escaped",
+			message:        "{€}",
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts:      [][]byte{{0x1B, 0x28, 0x1B, 0x65, 0x1B, 0x29}},
+		},
+		{
+			name:           "GSM single message limit",
+			message:        strings.Repeat("a", 160),
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts:      [][]byte{bytes.Repeat([]byte{'a'}, 160)},
+		},
+		{
+			name:           "GSM extension characters count twice",
+			message:        strings.Repeat("a", 159) + "[",
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts: [][]byte{
+				bytes.Repeat([]byte{'a'}, 153),
+				append(bytes.Repeat([]byte{'a'}, 6), 0x1B, 0x3C),
+			},
+		},
+		{
+			name:           "GSM escape sequence is not split across parts",
+			message:        strings.Repeat("a", 152) + "|" + strings.Repeat("b", 10),
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingDefault,
+			wantParts: [][]byte{
+				bytes.Repeat([]byte{'a'}, 152),
+				append([]byte{0x1B, 0x40}, bytes.Repeat([]byte{'b'}, 10)...),
+			},
+		},
+		{
+			name:           "characters outside GSM fall back to UCS-2",
+			message:        "Hi `",
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingUCS2,
+			wantParts:      [][]byte{{0x00, 'H', 0x00, 'i', 0x00, ' ', 0x00, '`'}},
+		},
+		{
+			name:           "IA5 text",
+			message:        "Hi {x}`",
+			alphabet:       smppAlphabetIA5,
+			wantDataCoding: smppDataCodingIA5,
+			wantParts:      [][]byte{[]byte("Hi {x}`")},
+		},
+		{
+			name:           "IA5 extension characters count twice",
+			message:        strings.Repeat("a", 159) + "~",
+			alphabet:       smppAlphabetIA5,
+			wantDataCoding: smppDataCodingIA5,
+			wantParts: [][]byte{
+				bytes.Repeat([]byte{'a'}, 153),
+				append(bytes.Repeat([]byte{'a'}, 6), '~'),
+			},
+		},
+		{
+			name:           "non-ASCII falls back to UCS-2 with IA5",
+			message:        "é",
+			alphabet:       smppAlphabetIA5,
+			wantDataCoding: smppDataCodingUCS2,
+			wantParts:      [][]byte{{0x00, 0xE9}},
+		},
+		{
+			name:           "UCS-2 single message limit",
+			message:        strings.Repeat("ж", 70),
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingUCS2,
+			wantParts:      [][]byte{bytes.Repeat([]byte{0x04, 0x36}, 70)},
+		},
+		{
+			name:           "UCS-2 surrogate pair is not split across parts",
+			message:        strings.Repeat("ж", 66) + "😀" + strings.Repeat("ж", 3),
+			alphabet:       smppAlphabetGSM,
+			wantDataCoding: smppDataCodingUCS2,
+			wantParts: [][]byte{
+				bytes.Repeat([]byte{0x04, 0x36}, 66),
+				append([]byte{0xD8, 0x3D, 0xDE, 0x00}, bytes.Repeat([]byte{0x04, 0x36}, 3)...),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataCoding, segments, err := segmentSMPPMessage(tt.message, tt.alphabet)
+			if err != nil {
+				t.Fatalf("segmentSMPPMessage() error = %v", err)
+			}
+			if dataCoding != tt.wantDataCoding {
+				t.Errorf("data coding = 0x%02X, want 0x%02X", dataCoding, tt.wantDataCoding)
+			}
+			if len(segments) != len(tt.wantParts) {
+				t.Fatalf("got %d segments, want %d", len(segments), len(tt.wantParts))
+			}
+
+			if len(segments) == 1 {
+				if !bytes.Equal(segments[0], tt.wantParts[0]) {
+					t.Errorf("segment = % X, want % X", segments[0], tt.wantParts[0])
+				}
+				return
+			}
+
+			reference := segments[0][3]
+			for i, segment := range segments {
+				if segment[3] != reference {
+					t.Errorf("segment %d has reference %d, want %d", i+1, segment[3], reference)
+				}
+				if payload := stripConcatHeader(t, segment, len(segments), i+1); !bytes.Equal(payload, tt.wantParts[i]) {
+					t.Errorf("segment %d = % X, want % X", i+1, payload, tt.wantParts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentSMPPMessageTooLong(t *testing.T) {
+	if _, _, err := segmentSMPPMessage(strings.Repeat("a", 153*255+1), smppAlphabetGSM); err == nil {
+		t.Fatal("segmentSMPPMessage() error = nil, want an error for more than 255 parts")
+	}
+}
+
+func TestParseSMPPReceipt(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *smppShortMessage
+		want    DeliveryReceipt
+	}{
+		{
+			name: "receipt text",
+			message: &smppShortMessage{
+				SourceAddr:   "447700900123",
+				ShortMessage: []byte("id:0123456789 sub:001 dlvrd:001 submit date:2506011200 done date:2506011201 stat:DELIVRD err:000 text:Hello"),
+			},
+			want: DeliveryReceipt{
+				MessageID: "0123456789",
+				State:     "DELIVRD",
+				ErrorCode: "000",
+				Address:   "447700900123",
+				Timestamp: time.Date(2025, 6, 1, 12, 1, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "done date with seconds and lower case state",
+			message: &smppShortMessage{
+				SourceAddr:   "447700900123",
+				ShortMessage: []byte("id:abc stat:undeliv err:001 done date:250601120130"),
+			},
+			want: DeliveryReceipt{
+				MessageID: "abc",
+				State:     "UNDELIV",
+				ErrorCode: "001",
+				Address:   "447700900123",
+				Timestamp: time.Date(2025, 6, 1, 12, 1, 30, 0, time.UTC),
+			},
+		},
+		{
+			name: "optional parameters win over the text",
+			message: &smppShortMessage{
+				SourceAddr:   "447700900123",
+				ShortMessage: []byte("id:text-id stat:ENROUTE done date:2506011201"),
+				Params: map[uint16][]byte{
+					smppTagReceiptedMsgID: []byte("tlv-id\x00"),
+					smppTagMessageState:   {5},
+				},
+			},
+			want: DeliveryReceipt{
+				MessageID: "tlv-id",
+				State:     "UNDELIV",
+				Address:   "447700900123",
+				Timestamp: time.Date(2025, 6, 1, 12, 1, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSMPPReceipt(tt.message)
+			if got != tt.want {
+				t.Errorf("parseSMPPReceipt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSMPPInbound(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  *smppShortMessage
+		wantFrom string
+		wantTo   string
+		wantBody string
+	}{
+		{
+			name: "GSM opt-out keyword from an international number",
+			message: &smppShortMessage{
+				SourceTON:    1,
+				SourceAddr:   "447700900123",
+				DestTON:      5,
+				DestAddr:     "ACME",
+				ShortMessage: []byte("STOP"),
+			},
+			wantFrom: "+447700900123",
+			wantTo:   "ACME",
+			wantBody: "STOP",
+		},
+		{
+			name: "GSM extension characters",
+			message: &smppShortMessage{
+				SourceAddr:   "07700900123",
+				ShortMessage: []byte{'a', 0x1B, 0x65, 0x00, 0x05},
+			},
+			wantFrom: "07700900123",
+			wantBody: "a€@é",
+		},
+		{
+			name: "UCS-2 with a UDH",
+			message: &smppShortMessage{
+				ESMClass:     smppESMClassUDHI,
+				DataCoding:   smppDataCodingUCS2,
+				ShortMessage: []byte{0x05, 0x00, 0x03, 0x01, 0x02, 0x01, 0x04, 0x36, 0xD8, 0x3D, 0xDE, 0x00},
+			},
+			wantBody: "ж😀",
+		},
+		{
+			name: "message payload",
+			message: &smppShortMessage{
+				DataCoding: smppDataCodingIA5,
+				Params:     map[uint16][]byte{smppTagMessagePayload: []byte("start")},
+			},
+			wantBody: "start",
+		},
+		{
+			name: "Latin-1",
+			message: &smppShortMessage{
+				DataCoding:   smppDataCodingLatin1,
+				ShortMessage: []byte{'c', 'a', 'f', 0xE9},
+			},
+			wantBody: "café",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSMPPInbound(tt.message)
+			if got.From != tt.wantFrom || got.To != tt.wantTo || got.Body != tt.wantBody {
+				t.Errorf("parseSMPPInbound() = from %q, to %q, body %q, want from %q, to %q, body %q",
+					got.From, got.To, got.Body, tt.wantFrom, tt.wantTo, tt.wantBody)
+			}
+		})
+	}
+}
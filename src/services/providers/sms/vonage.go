@@ -0,0 +1,312 @@
+package sms
+
+import (
+	apitypes "delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VonageProvider implements the SMSService interface using the Vonage (Nexmo) SMS API
+type VonageProvider struct {
+	APIKey         string
+	APISecret      string
+	FromNumber     string
+	BaseURL        string
+	ReportsBaseURL string
+	Client         *http.Client
+	Provider       *models.Provider
+}
+
+// VonageConfig holds the Vonage provider configuration
+type VonageConfig struct {
+	BaseURL        string `json:"baseUrl,omitempty"`        // Defaults to https://rest.nexmo.com
+	ReportsBaseURL string `json:"reportsBaseUrl,omitempty"` // Defaults to baseUrl when set, otherwise https://api.nexmo.com
+	FromNumber     string `json:"fromNumber,omitempty"`     // Number or alphanumeric sender ID
+	APIKey         string `json:"apiKey,omitempty"`
+}
+
+// VonageSecureConfig holds the Vonage provider secure configuration
+type VonageSecureConfig struct {
+	APISecret string `json:"apiSecret,omitempty"`
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "VONAGE",
+		Aliases:      []string{"NEXMO"},
+		DisplayName:  "Vonage",
+		Channel:      models.ChannelSMS,
+		Capabilities: registry.Capabilities{StatusPolling: true},
+		ConfigSchema: []registry.Field{
+			{Name: "apiKey", Type: registry.FieldString, Required: true, Description: "Vonage API key"},
+			{Name: "fromNumber", Type: registry.FieldString, Required: true, Description: "Sender number or alphanumeric sender ID"},
+			{Name: "baseUrl", Type: registry.FieldString, Default: "https://rest.nexmo.com"},
+			{Name: "reportsBaseUrl", Type: registry.FieldString, Description: "Reports API base URL, defaults to baseUrl when set, otherwise https://api.nexmo.com"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "apiSecret", Type: registry.FieldString, Required: true, Description: "Vonage API secret"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewVonageProviderFromDB(provider)
+		},
+	})
+}
+
+// NewVonageProviderFromDB creates a new Vonage SMS provider using database configuration
+func NewVonageProviderFromDB(provider *models.Provider) (*VonageProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config VonageConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig VonageSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if config.APIKey == "" {
+		return nil, errors.New("API key not set in provider configuration")
+	}
+
+	if secureConfig.APISecret == "" {
+		return nil, errors.New("API secret not set in provider configuration")
+	}
+
+	if config.FromNumber == "" {
+		return nil, errors.New("from number not set in provider configuration")
+	}
+
+	baseURL := config.BaseURL
+	reportsBaseURL := config.ReportsBaseURL
+	if reportsBaseURL == "" {
+		// A stand-in server usually serves both APIs
+		reportsBaseURL = baseURL
+	}
+	if baseURL == "" {
+		baseURL = "https://rest.nexmo.com"
+	}
+	if reportsBaseURL == "" {
+		reportsBaseURL = "https://api.nexmo.com"
+	}
+
+	return &VonageProvider{
+		APIKey:         config.APIKey,
+		APISecret:      strings.TrimSpace(secureConfig.APISecret),
+		FromNumber:     config.FromNumber,
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		ReportsBaseURL: strings.TrimSuffix(reportsBaseURL, "/"),
+		Client:         helper.NewProviderHTTPClient(10 * time.Second),
+		Provider:       provider,
+	}, nil
+}
+
+// Send implements the SMSService.Send method
+func (p *VonageProvider) Send(to string, message string) error {
+	formData := url.Values{}
+	formData.Set("api_key", p.APIKey)
+	formData.Set("api_secret", p.APISecret)
+	formData.Set("from", p.FromNumber)
+	// Vonage expects the number in international format without the leading +
+	formData.Set("to", strings.TrimPrefix(to, "+"))
+	formData.Set("text", message)
+	if !isASCII(message) {
+		formData.Set("type", "unicode")
+	}
+
+	return p.sendRequest(formData)
+}
+
+// SendBulk implements the SMSService.SendBulk method
+func (p *VonageProvider) SendBulk(to []string, message string) error {
+	// The SMS API takes a single recipient per request
+	var lastErr error
+	for _, recipient := range to {
+		if err := p.Send(recipient, message); err != nil {
+			lastErr = err
+			helper.Log.WithError(err).WithField("recipient", recipient).Error("Failed to send SMS to recipient")
+		}
+	}
+	return lastErr
+}
+
+// SendTemplate implements the SMSService.SendTemplate method
+// The template is rendered on the server side and sent via the normal Send method
+func (p *VonageProvider) SendTemplate(to string, templateName string, params map[string]string) error {
+	renderedContent, exists := params["rendered_content"]
+	if !exists {
+		return errors.New("rendered_content not found in params")
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"to":       to,
+		"template": templateName,
+		"content":  renderedContent,
+		"provider": "vonage",
+	}).Debug("Sending template SMS via Vonage")
+
+	return p.Send(to, renderedContent)
+}
+
+// sendRequest sends a request to the Vonage SMS API
+func (p *VonageProvider) sendRequest(formData url.Values) error {
+	endpoint := p.BaseURL + "/sms/json"
+
+	helper.Log.WithFields(map[string]interface{}{
+		"endpoint": endpoint,
+		"from":     formData.Get("from"),
+		"to":       formData.Get("to"),
+	}).Debug("Sending Vonage SMS API request")
+
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		helper.Log.WithFields(map[string]interface{}{
+			"statusCode": resp.StatusCode,
+			"response":   string(body),
+		}).Error("Vonage API returned an error response")
+		return fmt.Errorf("vonage API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	// Vonage answers 200 and reports failures per message part
+	var response struct {
+		Messages []struct {
+			Status    string `json:"status"`
+			MessageID string `json:"message-id"`
+			ErrorText string `json:"error-text"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse Vonage response: %w", err)
+	}
+
+	for _, part := range response.Messages {
+		if part.Status != "0" {
+			helper.Log.WithFields(map[string]interface{}{
+				"status":    part.Status,
+				"errorText": part.ErrorText,
+				"to":        formData.Get("to"),
+			}).Error("Vonage rejected the message")
+			return fmt.Errorf("vonage API error: %s, status: %s", part.ErrorText, part.Status)
+		}
+	}
+
+	return nil
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by fetching the account balance
+func (p *VonageProvider) CheckCredentials() error {
+	query := url.Values{}
+	query.Set("api_key", p.APIKey)
+	query.Set("api_secret", p.APISecret)
+
+	req, err := http.NewRequest("GET", p.BaseURL+"/account/get-balance?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	return helper.CheckCredentials(p.Client, req)
+}
+
+// GetStatus implements the SMSService.GetStatus method using the Vonage reports API
+func (p *VonageProvider) GetStatus(messageID string) (apitypes.DeliveryStatus, error) {
+	query := url.Values{}
+	query.Set("account_id", p.APIKey)
+	query.Set("product", "SMS")
+	query.Set("direction", "outbound")
+	query.Set("id", messageID)
+	endpoint := p.ReportsBaseURL + "/v2/reports/records?" + query.Encode()
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+
+	req.SetBasicAuth(p.APIKey, p.APISecret)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return apitypes.DeliveryStatus{}, fmt.Errorf("vonage API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	var report struct {
+		Records []struct {
+			Status               string `json:"status"`
+			ErrorCode            string `json:"error_code"`
+			ErrorCodeDescription string `json:"error_code_description"`
+			DateFinalized        string `json:"date_finalized"`
+			DateReceived         string `json:"date_received"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(body, &report); err != nil {
+		return apitypes.DeliveryStatus{}, err
+	}
+
+	if len(report.Records) == 0 {
+		return apitypes.DeliveryStatus{}, fmt.Errorf("no Vonage record found for message %s", messageID)
+	}
+
+	record := report.Records[0]
+	details := ""
+	if record.ErrorCode != "" && record.ErrorCode != "0" {
+		details = fmt.Sprintf("Error code: %s, Error message: %s", record.ErrorCode, record.ErrorCodeDescription)
+	}
+
+	timestamp := record.DateFinalized
+	if timestamp == "" {
+		timestamp = record.DateReceived
+	}
+
+	return apitypes.DeliveryStatus{
+		MessageID: messageID,
+		Status:    strings.ToLower(record.Status),
+		Details:   details,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// isASCII reports whether a message can be sent with the default text encoding
+func isASCII(message string) bool {
+	for _, r := range message {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
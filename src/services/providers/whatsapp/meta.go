@@ -0,0 +1,531 @@
+package whatsapp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	apitypes "delivery/api/types"
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
+	"delivery/services/storage"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metaMaxMediaSize is the largest file the Cloud API accepts, documents may be up to 100 MB
+const metaMaxMediaSize = 100 * 1024 * 1024
+
+// metaReservedParams are template params that are not sent as body parameters
+var metaReservedParams = map[string]bool{
+	"rendered_content": true,
+	"media_url":        true,
+	"header":           true,
+}
+
+// MetaProvider implements the WhatsAppService interface using the WhatsApp Cloud API
+type MetaProvider struct {
+	AccessToken   string
+	AppSecret     string
+	VerifyToken   string
+	PhoneNumberID string
+	BaseURL       string
+	Language      string
+	UploadMedia   bool
+	Client        *http.Client
+	Fetcher       *storage.Fetcher // Downloads signed media URLs before they are uploaded
+	Provider      *models.Provider
+
+	lastMessageID string
+}
+
+// MetaConfig holds the Meta provider configuration
+type MetaConfig struct {
+	PhoneNumberID string `json:"phoneNumberId,omitempty"`
+	BaseURL       string `json:"baseUrl,omitempty"`     // Defaults to https://graph.facebook.com
+	APIVersion    string `json:"apiVersion,omitempty"`  // Defaults to v21.0
+	Language      string `json:"language,omitempty"`    // Template language when the template ID has none, defaults to en_US
+	UploadMedia   *bool  `json:"uploadMedia,omitempty"` // Upload stored media and send it by ID, defaults to true
+}
+
+// MetaSecureConfig holds the Meta provider secure configuration
+type MetaSecureConfig struct {
+	AccessToken        string `json:"accessToken,omitempty"`
+	AppSecret          string `json:"appSecret,omitempty"`          // Used to verify webhook signatures
+	WebhookVerifyToken string `json:"webhookVerifyToken,omitempty"` // Echoed by Meta when the webhook is registered
+}
+
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "META",
+		DisplayName:  "Meta WhatsApp Cloud API",
+		Channel:      models.ChannelWhatsApp,
+		Capabilities: registry.Capabilities{Attachments: true, Templates: true},
+		ConfigSchema: []registry.Field{
+			{Name: "phoneNumberId", Type: registry.FieldString, Required: true, Description: "Phone number ID of the WhatsApp Business number"},
+			{Name: "apiVersion", Type: registry.FieldString, Default: "v21.0", Description: "Graph API version"},
+			{Name: "baseUrl", Type: registry.FieldString, Default: "https://graph.facebook.com"},
+			{Name: "language", Type: registry.FieldString, Default: "en_US", Description: "Template language used when the template ID has none"},
+			{Name: "uploadMedia", Type: registry.FieldBoolean, Default: true, Description: "Upload stored attachments and send them by media ID instead of by link"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "accessToken", Type: registry.FieldString, Required: true, Description: "System user access token"},
+			{Name: "appSecret", Type: registry.FieldString, Description: "Used to verify webhook signatures"},
+			{Name: "webhookVerifyToken", Type: registry.FieldString, Description: "Token entered when registering the webhook"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewMetaProviderFromDB(provider)
+		},
+	})
+}
+
+// NewMetaProviderFromDB creates a new Meta WhatsApp provider using database configuration
+func NewMetaProviderFromDB(provider *models.Provider) (*MetaProvider, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+
+	// Parse config
+	var config MetaConfig
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	var secureConfig MetaSecureConfig
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if config.PhoneNumberID == "" {
+		return nil, errors.New("phone number ID not set in provider configuration")
+	}
+
+	if secureConfig.AccessToken == "" {
+		return nil, errors.New("access token not set in provider configuration")
+	}
+
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "https://graph.facebook.com"
+	}
+
+	apiVersion := config.APIVersion
+	if apiVersion == "" {
+		apiVersion = "v21.0"
+	}
+
+	language := config.Language
+	if language == "" {
+		language = "en_US"
+	}
+
+	return &MetaProvider{
+		AccessToken:   strings.TrimSpace(secureConfig.AccessToken),
+		AppSecret:     secureConfig.AppSecret,
+		VerifyToken:   secureConfig.WebhookVerifyToken,
+		PhoneNumberID: config.PhoneNumberID,
+		BaseURL:       baseURL + "/" + apiVersion,
+		Language:      language,
+		UploadMedia:   config.UploadMedia == nil || *config.UploadMedia,
+		Client:        helper.NewProviderHTTPClient(30 * time.Second),
+		Fetcher:       storage.NewMediaFetcherFromEnv(),
+		Provider:      provider,
+	}, nil
+}
+
+// Copy returns a copy sharing the credentials and HTTP client but not the last message ID,
+// cached instances are copied for each use
+func (p *MetaProvider) Copy() interface{} {
+	c := *p
+	c.lastMessageID = ""
+	return &c
+}
+
+// SendText implements the WhatsAppService.SendText method
+func (p *MetaProvider) SendText(to string, message string) error {
+	return p.sendMessage(to, "text", map[string]interface{}{
+		"body":        message,
+		"preview_url": false,
+	})
+}
+
+// SendMedia implements the WhatsAppService.SendMedia method.
+// mediaURL is either a URL or an existing Meta media ID. Signed media store URLs are uploaded unless
+// uploadMedia is off, other URLs are sent as a link for Meta to fetch.
+func (p *MetaProvider) SendMedia(to string, caption string, mediaType string, mediaURL string) error {
+	kind, media, err := p.mediaObject(mediaURL, mediaType)
+	if err != nil {
+		return err
+	}
+
+	// Audio and stickers cannot carry a caption
+	if caption != "" && kind != "audio" {
+		media["caption"] = caption
+	}
+	if kind == "document" && strings.Contains(mediaURL, "://") {
+		if parsed, err := url.Parse(mediaURL); err == nil && path.Base(parsed.Path) != "/" {
+			media["filename"] = path.Base(parsed.Path)
+		}
+	}
+
+	return p.sendMessage(to, kind, media)
+}
+
+// SendTemplate implements the WhatsAppService.SendTemplate method.
+// templateName is the approved template name, optionally followed by ":<language>".
+// Numeric params are sent as positional body parameters in order, otherwise params are sent as named
+// body parameters. "header" fills a text header, "media_url" a media header and "button_<n>" the URL
+// suffix of button n.
+func (p *MetaProvider) SendTemplate(to string, templateName string, params map[string]string) error {
+	name, language := templateName, p.Language
+	if i := strings.LastIndex(templateName, ":"); i > 0 {
+		name, language = templateName[:i], templateName[i+1:]
+	}
+
+	components, err := p.templateComponents(params)
+	if err != nil {
+		return err
+	}
+
+	template := map[string]interface{}{
+		"name":     name,
+		"language": map[string]string{"code": language},
+	}
+	if len(components) > 0 {
+		template["components"] = components
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"template":   name,
+		"language":   language,
+		"components": len(components),
+	}).Debug("Using template for Meta message")
+
+	return p.sendMessage(to, "template", template)
+}
+
+// templateComponents builds the header, body and button components of a template message
+func (p *MetaProvider) templateComponents(params map[string]string) ([]map[string]interface{}, error) {
+	var components []map[string]interface{}
+
+	if header := params["header"]; header != "" {
+		components = append(components, map[string]interface{}{
+			"type":       "header",
+			"parameters": []map[string]interface{}{{"type": "text", "text": header}},
+		})
+	} else if mediaURL := params["media_url"]; mediaURL != "" {
+		kind, media, err := p.mediaObject(mediaURL, "")
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, map[string]interface{}{
+			"type":       "header",
+			"parameters": []map[string]interface{}{{"type": kind, kind: media}},
+		})
+	}
+
+	var positional []int
+	var named []string
+	buttons := map[int]string{}
+	for key, value := range params {
+		switch {
+		case metaReservedParams[key]:
+		case strings.HasPrefix(key, "button_"):
+			index, err := strconv.Atoi(strings.TrimPrefix(key, "button_"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid button parameter %q", key)
+			}
+			buttons[index] = value
+		default:
+			if index, err := strconv.Atoi(key); err == nil {
+				positional = append(positional, index)
+			} else {
+				named = append(named, key)
+			}
+		}
+	}
+
+	var body []map[string]interface{}
+	if len(positional) > 0 {
+		// Positional templates take {{1}}, {{2}}, ... in order, other params only feed the rendered content
+		sort.Ints(positional)
+		for _, index := range positional {
+			body = append(body, map[string]interface{}{"type": "text", "text": params[strconv.Itoa(index)]})
+		}
+	} else {
+		sort.Strings(named)
+		for _, key := range named {
+			body = append(body, map[string]interface{}{"type": "text", "parameter_name": key, "text": params[key]})
+		}
+	}
+	if len(body) > 0 {
+		components = append(components, map[string]interface{}{
+			"type":       "body",
+			"parameters": body,
+		})
+	}
+
+	indexes := make([]int, 0, len(buttons))
+	for index := range buttons {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		components = append(components, map[string]interface{}{
+			"type":       "button",
+			"sub_type":   "url",
+			"index":      strconv.Itoa(index),
+			"parameters": []map[string]interface{}{{"type": "text", "text": buttons[index]}},
+		})
+	}
+
+	return components, nil
+}
+
+// mediaObject returns the message type and media object for a URL or Meta media ID. Only signed
+// URLs of the media store are downloaded and uploaded, the service never fetches caller supplied URLs.
+func (p *MetaProvider) mediaObject(mediaURL string, mediaType string) (string, map[string]interface{}, error) {
+	if !strings.Contains(mediaURL, "://") {
+		return metaMediaKind(mediaType, mediaURL), map[string]interface{}{"id": mediaURL}, nil
+	}
+
+	if !p.UploadMedia || !storage.IsSignedMediaURL(mediaURL) {
+		return metaMediaKind(mediaType, mediaURL), map[string]interface{}{"link": mediaURL}, nil
+	}
+
+	mediaID, contentType, err := p.uploadMedia(mediaURL, mediaType)
+	if err != nil {
+		return "", nil, err
+	}
+	return metaMediaKind(contentType, mediaURL), map[string]interface{}{"id": mediaID}, nil
+}
+
+// uploadMedia downloads a signed media URL through the fetcher and uploads it to the Cloud API,
+// returning the media ID and content type
+func (p *MetaProvider) uploadMedia(mediaURL string, mediaType string) (string, string, error) {
+	object, err := p.Fetcher.Fetch(mediaURL, mediaType, metaMaxMediaSize)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download media: %w", err)
+	}
+	data := object.Data
+
+	contentType := mediaType
+	if contentType == "" {
+		contentType = object.ContentType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	filename := "media"
+	if parsed, err := url.Parse(mediaURL); err == nil && path.Base(parsed.Path) != "/" {
+		filename = path.Base(parsed.Path)
+	}
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	form.WriteField("messaging_product", "whatsapp")
+	form.WriteField("type", contentType)
+	part, err := form.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {fmt.Sprintf("form-data; name=\"file\"; filename=%q", filename)},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	part.Write(data)
+	form.Close()
+
+	endpoint := fmt.Sprintf("%s/%s/media", p.BaseURL, p.PhoneNumberID)
+	req, err := http.NewRequest("POST", endpoint, &buf)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := p.do(req, &uploaded); err != nil {
+		return "", "", err
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"mediaId":     uploaded.ID,
+		"contentType": contentType,
+		"size":        len(data),
+	}).Debug("Uploaded media to Meta")
+
+	return uploaded.ID, contentType, nil
+}
+
+// sendMessage sends a message of the given type to the Cloud API messages endpoint
+func (p *MetaProvider) sendMessage(to string, messageType string, content map[string]interface{}) error {
+	to = strings.TrimPrefix(strings.TrimPrefix(to, "whatsapp:"), "+")
+
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              messageType,
+		messageType:         content,
+	}
+	requestBody, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/messages", p.BaseURL, p.PhoneNumberID)
+
+	helper.Log.WithFields(map[string]interface{}{
+		"endpoint": endpoint,
+		"to":       to,
+		"type":     messageType,
+	}).Debug("Sending Meta API request")
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var response struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := p.do(req, &response); err != nil {
+		return err
+	}
+
+	if len(response.Messages) > 0 {
+		p.lastMessageID = response.Messages[0].ID
+	}
+	return nil
+}
+
+// do sends an authorized request and decodes the JSON response
+func (p *MetaProvider) do(req *http.Request, target interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+p.AccessToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		errFields := map[string]interface{}{
+			"statusCode": resp.StatusCode,
+			"response":   string(body),
+		}
+
+		var errorResponse struct {
+			Error struct {
+				Message   string `json:"message"`
+				Code      int    `json:"code"`
+				ErrorData struct {
+					Details string `json:"details"`
+				} `json:"error_data"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+			errFields["errorCode"] = errorResponse.Error.Code
+			errFields["errorMessage"] = errorResponse.Error.Message
+			errFields["errorDetails"] = errorResponse.Error.ErrorData.Details
+		}
+		helper.Log.WithFields(errFields).Error("Meta API returned an error response")
+
+		return fmt.Errorf("meta API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to parse Meta response: %w", err)
+	}
+	return nil
+}
+
+// LastMessageID returns the WhatsApp message ID (wamid) of the last message sent, statuses refer to it
+func (p *MetaProvider) LastMessageID() string {
+	return p.lastMessageID
+}
+
+// CheckCredentials implements the services.CredentialChecker interface by fetching the phone number
+func (p *MetaProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s?fields=display_phone_number", p.BaseURL, p.PhoneNumberID), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.AccessToken)
+	return helper.CheckCredentials(p.Client, req)
+}
+
+// GetStatus implements the WhatsAppService.GetStatus method
+func (p *MetaProvider) GetStatus(messageID string) (apitypes.DeliveryStatus, error) {
+	// The Cloud API reports statuses through the webhook only
+	return apitypes.DeliveryStatus{
+		MessageID: messageID,
+		Status:    "unknown",
+		Details:   "Meta provider reports statuses through the webhook only",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header of a webhook request against the app secret
+func (p *MetaProvider) VerifySignature(body []byte, signature string) bool {
+	if p.AppSecret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.AppSecret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(signature, "sha256=")))
+}
+
+// MediaURL returns the Graph API URL of a media object, it has to be fetched with the access token
+func (p *MetaProvider) MediaURL(mediaID string) string {
+	return fmt.Sprintf("%s/%s", p.BaseURL, mediaID)
+}
+
+// metaMediaKind returns the Cloud API message type for a MIME type, falling back to the file extension
+func metaMediaKind(mediaType string, mediaURL string) string {
+	if mediaType == "" {
+		if parsed, err := url.Parse(mediaURL); err == nil {
+			mediaType = mime.TypeByExtension(path.Ext(parsed.Path))
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return "image"
+	case strings.HasPrefix(mediaType, "video/"):
+		return "video"
+	case strings.HasPrefix(mediaType, "audio/"):
+		return "audio"
+	default:
+		return "document"
+	}
+}
@@ -5,6 +5,8 @@ import (
 	apitypes "delivery/api/types"
 	"delivery/helper"
 	"delivery/models"
+	"delivery/services/providers/registry"
+	"delivery/services/secrets"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +39,26 @@ type SecureConfig struct {
 	AuthToken string `json:"authToken,omitempty"`
 }
 
+func init() {
+	registry.Register(registry.ProviderType{
+		Name:         "TWILIO",
+		DisplayName:  "Twilio",
+		Channel:      models.ChannelWhatsApp,
+		Capabilities: registry.Capabilities{Attachments: true, Templates: true, StatusPolling: true},
+		ConfigSchema: []registry.Field{
+			{Name: "accountSid", Type: registry.FieldString, Required: true, Description: "Twilio account SID"},
+			{Name: "fromNumber", Type: registry.FieldString, Required: true, Description: "WhatsApp sender number"},
+			{Name: "baseUrl", Type: registry.FieldString, Default: "https://api.twilio.com/2010-04-01"},
+		},
+		SecureConfigSchema: []registry.Field{
+			{Name: "authToken", Type: registry.FieldString, Required: true, Description: "Twilio auth token"},
+		},
+		Factory: func(provider *models.Provider) (interface{}, error) {
+			return NewTwilioProviderFromDB(provider)
+		},
+	})
+}
+
 // NewTwilioProviderFromDB creates a new Twilio WhatsApp provider using database configuration
 func NewTwilioProviderFromDB(provider *models.Provider) (*TwilioProvider, error) {
 	if provider == nil {
@@ -54,36 +76,10 @@ func NewTwilioProviderFromDB(provider *models.Provider) (*TwilioProvider, error)
 		return nil, fmt.Errorf("failed to parse provider config: %w", err)
 	}
 
-	// Parse secure config
-	secureConfigJSON, err := json.Marshal(provider.SecureConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal provider secure config: %w", err)
-	}
-
-	// Get encryption key from environment
-	encryptionKey := []byte(helper.GetEnv("ENCRYPTION_KEY", ""))
-	if len(encryptionKey) != 32 {
-		return nil, errors.New("ENCRYPTION_KEY environment variable not set or invalid (must be exactly 32 bytes)")
-	}
-
-	// Check if the config is encrypted
-	var encryptedData struct {
-		Encrypted string `json:"encrypted"`
-	}
-	if err := json.Unmarshal(secureConfigJSON, &encryptedData); err != nil || encryptedData.Encrypted == "" {
-		return nil, fmt.Errorf("secure config is not in the expected format: %w", err)
-	}
-
-	// Decrypt the secure config
-	decodedBytes, err := helper.DecodeBase64AndDecrypt(encryptedData.Encrypted, encryptionKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt provider secure config: %w", err)
-	}
-
-	// Parse the decrypted secure config
+	// Load secure config from its secret backend
 	var secureConfig SecureConfig
-	if err := json.Unmarshal(decodedBytes, &secureConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse provider secure config: %w", err)
+	if err := secrets.LoadSecureConfig(provider.SecureConfig, &secureConfig); err != nil {
+		return nil, err
 	}
 
 	// Validate required fields
@@ -128,7 +124,7 @@ func NewTwilioProviderFromDB(provider *models.Provider) (*TwilioProvider, error)
 		AuthToken:  authToken,
 		FromNumber: fromNumber,
 		BaseURL:    baseURL,
-		Client:     &http.Client{Timeout: 10 * time.Second},
+		Client:     helper.NewProviderHTTPClient(10 * time.Second),
 		Provider:   provider,
 	}, nil
 }
@@ -276,6 +272,16 @@ func (p *TwilioProvider) sendRequest(formData url.Values) error {
 	return nil
 }
 
+// CheckCredentials implements the services.CredentialChecker interface by fetching the Twilio account
+func (p *TwilioProvider) CheckCredentials() error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/Accounts/%s.json", p.BaseURL, p.AccountSID), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+	return helper.CheckCredentials(p.Client, req)
+}
+
 // GetStatus implements the WhatsAppService.GetStatus method
 func (p *TwilioProvider) GetStatus(messageID string) (apitypes.DeliveryStatus, error) {
 	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages/%s.json", p.BaseURL, p.AccountSID, messageID)
@@ -4,7 +4,9 @@ import (
 	"delivery/helper"
 	"delivery/models"
 	"delivery/services"
+	"delivery/services/providers"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,6 +18,7 @@ type EmailConsumer struct {
 	pulsarClient *PulsarClient
 	db           *gorm.DB
 	readerDB     *gorm.DB
+	emailService *services.EmailServiceImpl
 }
 
 // NewEmailConsumer creates a new email consumer
@@ -24,10 +27,17 @@ func NewEmailConsumer(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB
 		return nil, fmt.Errorf("database connection cannot be nil")
 	}
 
+	// The service holds the attachment store and fetcher, whose HTTP clients are shared by every message
+	emailService, err := services.NewEmailService(db, providers.CreateEmailProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email service: %w", err)
+	}
+
 	return &EmailConsumer{
 		pulsarClient: pulsarClient,
 		db:           db,
 		readerDB:     readerDB,
+		emailService: emailService,
 	}, nil
 }
 
@@ -82,25 +92,52 @@ func (c *EmailConsumer) handleMessage(data []byte) error {
 		return fmt.Errorf("provider not found: %w", err)
 	}
 
-	// Create the email service to actually send the email
-	emailService, err := services.NewEmailService(c.db)
+	// Never contact recipients who are on the suppression list
+	var dbMessage models.Message
+	if err := c.db.Where("uuid = ?", message.UUID).First(&dbMessage).Error; err != nil {
+		logger.WithError(err).Error("Failed to fetch message")
+		return fmt.Errorf("failed to fetch message: %w", err)
+	}
+	recordTemplateVersion(c.db, &dbMessage, &template)
+	recipients, err := c.unsuppressed(&dbMessage, message.Message.To)
 	if err != nil {
-		logger.WithError(err).Error("Failed to create email service")
-		if err := c.updateMessageStatus(message.UUID, models.StatusRejected); err != nil {
-			logger.WithError(err).Error("Failed to update message status to FAILED")
-		}
-		return fmt.Errorf("failed to create email service: %w", err)
+		logger.WithError(err).Error("Failed to check suppression list")
+		return err
+	}
+	if len(recipients) == 0 {
+		logger.Info("All email recipients are suppressed, not sending")
+		return c.db.Model(&dbMessage).Update("status", models.StatusSuppressed).Error
+	}
+	message.Message.To = recipients
+
+	// Copies are never sent to suppressed addresses either
+	if message.Message.Cc, err = c.unsuppressed(&dbMessage, message.Message.Cc); err != nil {
+		logger.WithError(err).Error("Failed to check suppression list")
+		return err
+	}
+	if message.Message.Bcc, err = c.unsuppressed(&dbMessage, message.Message.Bcc); err != nil {
+		logger.WithError(err).Error("Failed to check suppression list")
+		return err
+	}
+
+	// Leave the message in the queue while the provider's circuit is open, it is redelivered later
+	if !services.ProviderAvailable(provider.UUID) {
+		logger.Warn("Email provider circuit is open, delaying message")
+		return fmt.Errorf("%w: %s", services.ErrCircuitOpen, provider.UUID)
 	}
 
 	logger.WithFields(map[string]interface{}{
-		"provider":    provider.Name,
-		"recipients":  len(message.Message.To),
-		"template":    template.Name,
-		"messageData": message.Message,
+		"provider":   provider.Name,
+		"recipients": len(message.Message.To),
+		"template":   template.Name,
 	}).Info("Sending email with provider")
 
 	// Send the actual email
-	if err := emailService.SendEmail(&message.Message); err != nil {
+	if err := c.emailService.SendEmail(&message.Message, &template); err != nil {
+		if errors.Is(err, services.ErrCircuitOpen) {
+			logger.Warn("Email provider circuit opened, delaying message")
+			return err
+		}
 		logger.WithError(err).Error("Failed to send email")
 		if err := c.updateMessageStatus(message.UUID, models.StatusRejected); err != nil {
 			logger.WithError(err).Error("Failed to update message status to FAILED")
@@ -110,6 +147,11 @@ func (c *EmailConsumer) handleMessage(data []byte) error {
 
 	logger.Info("Email sent successfully")
 
+	// Remember the recipients so replies can be matched to this message
+	for _, recipient := range message.Message.To {
+		recordRecipient(c.db, &dbMessage, provider.UUID, models.ChannelEmail, recipient.Email, "")
+	}
+
 	// Update status to SENT
 	if err := c.updateMessageStatus(message.UUID, models.StatusSent); err != nil {
 		logger.WithError(err).Error("Failed to update message status to SENT")
@@ -120,6 +162,21 @@ func (c *EmailConsumer) handleMessage(data []byte) error {
 	return nil
 }
 
+// unsuppressed returns the recipients that are not on the suppression list
+func (c *EmailConsumer) unsuppressed(dbMessage *models.Message, recipients []models.EmailRecipient) ([]models.EmailRecipient, error) {
+	var result []models.EmailRecipient
+	for _, recipient := range recipients {
+		suppressed, err := checkSuppressed(c.db, c.readerDB, dbMessage, models.ChannelEmail, recipient.Email)
+		if err != nil {
+			return nil, err
+		}
+		if !suppressed {
+			result = append(result, recipient)
+		}
+	}
+	return result, nil
+}
+
 // ensureMessageExists checks if a message exists in the database and creates it if not
 func (c *EmailConsumer) ensureMessageExists(message EmailMessage) error {
 	// Check if the message exists in the database
@@ -153,13 +210,15 @@ func (c *EmailConsumer) ensureMessageExists(message EmailMessage) error {
 			}
 		}
 		newMessage := models.Message{
-			UUID:        uuid,
-			Channel:     models.ChannelEmail,
-			Status:      models.StatusAccepted,
-			RefNo:       message.Message.RefNo,
-			Identifiers: identifiersJSON,
-			Categories:  categoriesJSON,
-			TenantID:    message.Message.TenantID,
+			UUID:             uuid,
+			Channel:          models.ChannelEmail,
+			Status:           models.StatusAccepted,
+			RefNo:            message.Message.RefNo,
+			Identifiers:      identifiersJSON,
+			Categories:       categoriesJSON,
+			TenantID:         message.Message.TenantID,
+			NotificationUUID: message.Message.NotificationUUID,
+			TemplateUUID:     message.Message.Template,
 		}
 
 		if err := c.db.Create(&newMessage).Error; err != nil {
@@ -0,0 +1,318 @@
+package queue
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// escalationPollInterval is how often the scheduler looks for due escalation steps
+	escalationPollInterval = 5 * time.Second
+
+	// escalationBatchSize limits how many due steps a single transaction claims
+	escalationBatchSize = 20
+
+	// escalationClaimTimeout is how long a step may stay IN_PROGRESS before it is considered interrupted
+	escalationClaimTimeout = 5 * time.Minute
+)
+
+// EscalationScheduler executes escalation steps once they become due
+type EscalationScheduler struct {
+	db                   *gorm.DB
+	readerDB             *gorm.DB
+	notificationProducer *NotificationProducer
+	escalationService    *services.EscalationService
+	stop                 chan struct{}
+}
+
+// NewEscalationScheduler creates a new escalation scheduler
+func NewEscalationScheduler(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB) (*EscalationScheduler, error) {
+	escalationService, err := services.NewEscalationService(db, readerDB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EscalationScheduler{
+		db:                   db,
+		readerDB:             readerDB,
+		notificationProducer: NewNotificationProducer(pulsarClient, db, readerDB),
+		escalationService:    escalationService,
+		stop:                 make(chan struct{}),
+	}, nil
+}
+
+// Start polls for due escalation steps at the given interval until Stop is called
+func (s *EscalationScheduler) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				// Keep going until the backlog of due steps is drained
+				for {
+					processed, err := s.runDueSteps()
+					if err != nil {
+						helper.Log.WithError(err).Error("Failed to run due escalation steps")
+						break
+					}
+					if processed < escalationBatchSize {
+						break
+					}
+				}
+			}
+		}
+	}()
+
+	helper.Log.Infof("Escalation scheduler started with interval %s", interval)
+}
+
+// Stop stops the scheduler loop
+func (s *EscalationScheduler) Stop() {
+	close(s.stop)
+}
+
+// runDueSteps executes a batch of due steps. Steps are claimed as IN_PROGRESS in a transaction that
+// locks them with SKIP LOCKED, so several service instances can run the scheduler without sending a
+// step twice. Notifications are queued after the claim is committed and each outcome is recorded in its
+// own transaction, so a failure later in the batch cannot put queued steps back to PENDING.
+func (s *EscalationScheduler) runDueSteps() (int, error) {
+	if err := s.failInterruptedSteps(); err != nil {
+		return 0, err
+	}
+
+	steps, err := s.claimDueSteps()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range steps {
+		if err := s.runStep(&steps[i]); err != nil {
+			helper.Log.WithError(err).WithField("step_id", steps[i].ID).Error("Failed to run escalation step")
+		}
+	}
+	return len(steps), nil
+}
+
+// claimDueSteps marks a batch of due steps IN_PROGRESS. Steps of escalations that were stopped or
+// answered are cancelled instead of claimed.
+func (s *EscalationScheduler) claimDueSteps() ([]models.EscalationStep, error) {
+	var claimed []models.EscalationStep
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var steps []models.EscalationStep
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND due_at <= ?", models.EscalationStepPending, time.Now()).
+			Order("due_at").
+			Limit(escalationBatchSize).
+			Find(&steps).Error; err != nil {
+			return fmt.Errorf("failed to fetch due escalation steps: %w", err)
+		}
+
+		for _, step := range steps {
+			run, err := s.claimStep(tx, &step)
+			if err != nil {
+				return err
+			}
+			if run {
+				claimed = append(claimed, step)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// claimStep marks a step IN_PROGRESS, or cancels it when the escalation has already been answered or stopped
+func (s *EscalationScheduler) claimStep(tx *gorm.DB, step *models.EscalationStep) (bool, error) {
+	var escalation models.Escalation
+	if err := tx.Where("id = ?", step.EscalationID).First(&escalation).Error; err != nil {
+		return false, fmt.Errorf("failed to fetch escalation: %w", err)
+	}
+
+	if escalation.Status != models.EscalationStatusActive {
+		return false, tx.Model(step).Updates(map[string]interface{}{
+			"status": models.EscalationStepCancelled,
+			"reason": fmt.Sprintf("Escalation is %s", escalation.Status),
+		}).Error
+	}
+
+	answered, err := s.escalationService.AnsweredStatus(tx, escalation.ID)
+	if err != nil {
+		return false, err
+	}
+	if answered != "" {
+		helper.Log.WithFields(map[string]interface{}{
+			"escalation_uuid": escalation.UUID,
+			"position":        step.Position,
+			"status":          answered,
+		}).Info("Escalation answered before the next step")
+		return false, s.escalationService.Stop(tx, &escalation, answered, "A recipient reacted before the next step")
+	}
+
+	if err := tx.Model(step).Update("status", models.EscalationStepInProgress).Error; err != nil {
+		return false, fmt.Errorf("failed to claim escalation step: %w", err)
+	}
+	return true, nil
+}
+
+// runStep queues the notification of a claimed step, then records the outcome and schedules the next step
+func (s *EscalationScheduler) runStep(step *models.EscalationStep) error {
+	var escalation models.Escalation
+	if err := s.db.Where("id = ?", step.EscalationID).First(&escalation).Error; err != nil {
+		return fmt.Errorf("failed to fetch escalation: %w", err)
+	}
+
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"escalation_uuid": escalation.UUID,
+		"position":        step.Position,
+		"channel":         step.Channel,
+	})
+
+	notificationUUID, err := helper.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("failed to generate notification UUID: %w", err)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            models.EscalationStepSent,
+		"notification_uuid": notificationUUID,
+		"sent_at":           now,
+	}
+	if _, err := s.notificationProducer.ProduceNotification(escalationNotification(&escalation, step), notificationUUID); err != nil {
+		// A failing step must not stall the chain, the next step is still scheduled
+		logger.WithError(err).Warn("Failed to send escalation step")
+		updates = map[string]interface{}{
+			"status": models.EscalationStepFailed,
+			"reason": err.Error(),
+		}
+	} else {
+		logger.WithField("notification_uuid", notificationUUID).Info("Escalation step sent")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(step).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update escalation step: %w", err)
+		}
+
+		// The escalation may have been answered or cancelled while the notification was queued
+		if err := tx.Where("id = ?", escalation.ID).First(&escalation).Error; err != nil {
+			return fmt.Errorf("failed to fetch escalation: %w", err)
+		}
+		if escalation.Status != models.EscalationStatusActive {
+			return nil
+		}
+		return scheduleNextStep(tx, &escalation, step, now)
+	})
+}
+
+// failInterruptedSteps fails steps left IN_PROGRESS by an instance that stopped before recording the
+// outcome. Their notification may have been queued, so they are not sent again, the chain moves on.
+func (s *EscalationScheduler) failInterruptedSteps() error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var steps []models.EscalationStep
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND updated_at <= ?", models.EscalationStepInProgress, time.Now().Add(-escalationClaimTimeout)).
+			Limit(escalationBatchSize).
+			Find(&steps).Error; err != nil {
+			return fmt.Errorf("failed to fetch interrupted escalation steps: %w", err)
+		}
+
+		now := time.Now()
+		for i := range steps {
+			step := &steps[i]
+			if err := tx.Model(step).Updates(map[string]interface{}{
+				"status": models.EscalationStepFailed,
+				"reason": "Interrupted before the outcome was recorded, the notification may have been queued",
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update escalation step: %w", err)
+			}
+
+			var escalation models.Escalation
+			if err := tx.Where("id = ?", step.EscalationID).First(&escalation).Error; err != nil {
+				return fmt.Errorf("failed to fetch escalation: %w", err)
+			}
+			if escalation.Status != models.EscalationStatusActive {
+				continue
+			}
+			if err := scheduleNextStep(tx, &escalation, step, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scheduleNextStep makes the next pending step of an active escalation due after its wait, or completes
+// the escalation after the last step
+func scheduleNextStep(tx *gorm.DB, escalation *models.Escalation, step *models.EscalationStep, now time.Time) error {
+	var next models.EscalationStep
+	err := tx.Where("escalation_id = ? AND status = ? AND position > ?", escalation.ID, models.EscalationStepPending, step.Position).
+		Order("position").
+		First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Model(escalation).Updates(map[string]interface{}{
+			"status": models.EscalationStatusCompleted,
+			"reason": "All steps were executed",
+		}).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch next escalation step: %w", err)
+	}
+
+	return tx.Model(&next).Update("due_at", now.Add(time.Duration(next.WaitSeconds)*time.Second)).Error
+}
+
+// escalationNotification builds the single-channel notification sent by an escalation step
+func escalationNotification(escalation *models.Escalation, step *models.EscalationStep) *models.NotificationMessage {
+	message := &models.NotificationMessage{
+		Template:    step.TemplateCode,
+		To:          models.RecipientsFromJSON(step.Recipients),
+		Channels:    []models.Channel{step.Channel},
+		Strategy:    models.NotificationStrategyAll,
+		RefNo:       escalation.RefNo,
+		TenantID:    escalation.TenantID,
+		Identifiers: escalation.Identifiers,
+		Subject:     escalation.Subject,
+		Params:      make(map[string]string, len(escalation.Params)),
+	}
+
+	if step.ProviderUUID != "" {
+		message.Providers = map[models.Channel]string{step.Channel: step.ProviderUUID}
+	}
+
+	for key, value := range escalation.Params {
+		message.Params[key] = fmt.Sprintf("%v", value)
+	}
+
+	// Categories are stored as an index-keyed object
+	keys := make([]int, 0, len(escalation.Categories))
+	for key := range escalation.Categories {
+		if idx, err := strconv.Atoi(key); err == nil {
+			keys = append(keys, idx)
+		}
+	}
+	sort.Ints(keys)
+	for _, idx := range keys {
+		if category, ok := escalation.Categories[strconv.Itoa(idx)].(string); ok {
+			message.Categories = append(message.Categories, category)
+		}
+	}
+
+	return message
+}
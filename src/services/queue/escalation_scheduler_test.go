@@ -0,0 +1,180 @@
+package queue
+
+import (
+	"delivery/models"
+	"delivery/services"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newTestScheduler returns a scheduler without a Pulsar producer, for the paths that only touch the database
+func newTestScheduler(t *testing.T) (*EscalationScheduler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := newMockDB(t)
+	escalationService, err := services.NewEscalationService(db, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &EscalationScheduler{db: db, readerDB: db, escalationService: escalationService}, mock
+}
+
+func TestClaimDueSteps(t *testing.T) {
+	tests := []struct {
+		name             string
+		escalationStatus models.EscalationStatus
+		answered         []models.MessageEventType
+		wantClaimed      int
+		wantStopped      models.EscalationStatus
+	}{
+		{
+			name:             "step of an active escalation is claimed",
+			escalationStatus: models.EscalationStatusActive,
+			wantClaimed:      1,
+		},
+		{
+			name:             "step of a cancelled escalation is cancelled",
+			escalationStatus: models.EscalationStatusCancelled,
+		},
+		{
+			name:             "acknowledged escalation is stopped",
+			escalationStatus: models.EscalationStatusActive,
+			answered:         []models.MessageEventType{models.EventStatusRead, models.EventStatusAcknowledged},
+			wantStopped:      models.EscalationStatusAcknowledged,
+		},
+		{
+			name:             "read escalation is stopped",
+			escalationStatus: models.EscalationStatusActive,
+			answered:         []models.MessageEventType{models.EventStatusRead},
+			wantStopped:      models.EscalationStatusRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, mock := newTestScheduler(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT \* FROM "escalation_steps" WHERE status = \$1 AND due_at <= \$2 ORDER BY due_at LIMIT \$3 FOR UPDATE SKIP LOCKED`).
+				WithArgs(models.EscalationStepPending, sqlmock.AnyArg(), escalationBatchSize).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "escalation_id", "position", "status"}).
+					AddRow(1, 7, 2, models.EscalationStepPending))
+			mock.ExpectQuery(`SELECT \* FROM "escalations" WHERE id = \$1`).
+				WithArgs(7, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "status"}).
+					AddRow(7, "escalation-uuid", tt.escalationStatus))
+
+			switch {
+			case tt.escalationStatus != models.EscalationStatusActive:
+				mock.ExpectExec(`UPDATE "escalation_steps" SET "reason"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+					WithArgs("Escalation is "+string(tt.escalationStatus), models.EscalationStepCancelled, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			default:
+				rows := sqlmock.NewRows([]string{"status"})
+				for _, status := range tt.answered {
+					rows.AddRow(status)
+				}
+				mock.ExpectQuery(`SELECT DISTINCT message_events.status FROM "message_events"`).
+					WillReturnRows(rows)
+
+				if tt.wantStopped != "" {
+					mock.ExpectExec(`UPDATE "escalations" SET "reason"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+						WithArgs(sqlmock.AnyArg(), tt.wantStopped, sqlmock.AnyArg(), 7).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`UPDATE "escalation_steps" SET "reason"=\$1,"status"=\$2,"updated_at"=\$3 WHERE escalation_id = \$4 AND status = \$5`).
+						WithArgs(sqlmock.AnyArg(), models.EscalationStepCancelled, sqlmock.AnyArg(), 7, models.EscalationStepPending).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				} else {
+					mock.ExpectExec(`UPDATE "escalation_steps" SET "status"=\$1,"updated_at"=\$2 WHERE "id" = \$3`).
+						WithArgs(models.EscalationStepInProgress, sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+			mock.ExpectCommit()
+
+			claimed, err := scheduler.claimDueSteps()
+			if err != nil {
+				t.Fatalf("claimDueSteps() error = %v", err)
+			}
+			if len(claimed) != tt.wantClaimed {
+				t.Errorf("claimDueSteps() claimed %d steps, want %d", len(claimed), tt.wantClaimed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFailInterruptedSteps(t *testing.T) {
+	tests := []struct {
+		name             string
+		escalationStatus models.EscalationStatus
+		hasNextStep      bool
+		wantNextDue      bool
+		wantCompleted    bool
+	}{
+		{
+			name:             "next step is scheduled",
+			escalationStatus: models.EscalationStatusActive,
+			hasNextStep:      true,
+			wantNextDue:      true,
+		},
+		{
+			name:             "last step completes the escalation",
+			escalationStatus: models.EscalationStatusActive,
+			wantCompleted:    true,
+		},
+		{
+			name:             "stopped escalation is left alone",
+			escalationStatus: models.EscalationStatusAcknowledged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, mock := newTestScheduler(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT \* FROM "escalation_steps" WHERE status = \$1 AND updated_at <= \$2 LIMIT \$3 FOR UPDATE SKIP LOCKED`).
+				WithArgs(models.EscalationStepInProgress, sqlmock.AnyArg(), escalationBatchSize).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "escalation_id", "position", "status"}).
+					AddRow(1, 7, 1, models.EscalationStepInProgress))
+			mock.ExpectExec(`UPDATE "escalation_steps" SET "reason"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+				WithArgs(sqlmock.AnyArg(), models.EscalationStepFailed, sqlmock.AnyArg(), 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`SELECT \* FROM "escalations" WHERE id = \$1`).
+				WithArgs(7, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "status"}).
+					AddRow(7, "escalation-uuid", tt.escalationStatus))
+
+			if tt.escalationStatus == models.EscalationStatusActive {
+				next := sqlmock.NewRows([]string{"id", "escalation_id", "position", "wait_seconds", "status"})
+				if tt.hasNextStep {
+					next.AddRow(2, 7, 2, 60, models.EscalationStepPending)
+				}
+				mock.ExpectQuery(`SELECT \* FROM "escalation_steps" WHERE escalation_id = \$1 AND status = \$2 AND position > \$3 ORDER BY position`).
+					WithArgs(7, models.EscalationStepPending, 1, 1).
+					WillReturnRows(next)
+			}
+			if tt.wantNextDue {
+				mock.ExpectExec(`UPDATE "escalation_steps" SET "due_at"=\$1,"updated_at"=\$2 WHERE "id" = \$3`).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tt.wantCompleted {
+				mock.ExpectExec(`UPDATE "escalations" SET "reason"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+					WithArgs(sqlmock.AnyArg(), models.EscalationStatusCompleted, sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			if err := scheduler.failInterruptedSteps(); err != nil {
+				t.Fatalf("failInterruptedSteps() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package queue
+
+import (
+	"delivery/helper"
+	"delivery/models"
+)
+
+// InboundProducer forwards inbound messages to downstream consumers
+type InboundProducer struct {
+	PulsarClient *PulsarClient
+}
+
+// NewInboundProducer creates a new inbound message producer
+func NewInboundProducer(pulsarClient *PulsarClient) *InboundProducer {
+	producer := &InboundProducer{
+		PulsarClient: pulsarClient,
+	}
+
+	helper.Log.Info("Inbound producer created successfully")
+	return producer
+}
+
+// ProduceInboundMessage sends an inbound message to the inbound topic
+func (p *InboundProducer) ProduceInboundMessage(message *models.InboundMessagePayload) error {
+	return p.PulsarClient.ProduceMessage(InboundTopic, message)
+}
@@ -0,0 +1,394 @@
+package queue
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Notification child statuses reported back to the caller
+const (
+	NotificationChildAccepted = "ACCEPTED"
+	NotificationChildSkipped  = "SKIPPED"
+	NotificationChildFailed   = "FAILED"
+)
+
+// DefaultNotificationChannels is the channel order used when a notification does not specify one
+var DefaultNotificationChannels = []models.Channel{models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail}
+
+// NotificationChild describes the outcome of a notification on a single channel
+type NotificationChild struct {
+	Channel models.Channel `json:"channel"`
+	UUID    string         `json:"uuid,omitempty"`
+	Status  string         `json:"status"`
+	Reason  string         `json:"reason,omitempty"`
+}
+
+// NotificationProducer fans a notification out to the channel producers
+type NotificationProducer struct {
+	PulsarClient     *PulsarClient
+	db               *gorm.DB
+	readerDB         *gorm.DB
+	whatsAppProducer *WhatsAppProducer
+	smsProducer      *SMSProducer
+	emailProducer    *EmailProducer
+}
+
+// channelTarget holds everything needed to send a notification on one channel
+type channelTarget struct {
+	channel  models.Channel
+	template *models.Template
+	provider *models.Provider
+}
+
+// NewNotificationProducer creates a new notification producer
+func NewNotificationProducer(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB) *NotificationProducer {
+	producer := &NotificationProducer{
+		PulsarClient:     pulsarClient,
+		db:               db,
+		readerDB:         readerDB,
+		whatsAppProducer: NewWhatsAppProducer(pulsarClient, db),
+		smsProducer:      NewSMSProducer(pulsarClient, db),
+		emailProducer:    NewEmailProducer(pulsarClient, db),
+	}
+
+	helper.Log.Info("Notification producer created successfully")
+	return producer
+}
+
+// NotificationPlan is a notification whose channels are resolved and that is ready to be produced.
+// A batch is resolved completely before any of it is produced, so an invalid item queues nothing.
+type NotificationPlan struct {
+	message  *models.NotificationMessage
+	strategy models.NotificationStrategy
+	channels []models.Channel
+	children []NotificationChild
+	targets  []channelTarget
+}
+
+// Children returns the outcome of the notification on each channel
+func (n *NotificationPlan) Children() []NotificationChild {
+	return n.children
+}
+
+// ResolveNotification resolves the template and provider of every channel of a notification.
+// Channels that cannot be resolved are SKIPPED, and with FIRST_AVAILABLE every channel after the
+// first resolved one is SKIPPED too. It fails when no channel can be resolved.
+func (p *NotificationProducer) ResolveNotification(message *models.NotificationMessage) (*NotificationPlan, error) {
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"template": message.Template,
+		"refNo":    message.RefNo,
+		"tenantId": message.TenantID,
+		"strategy": message.Strategy,
+	})
+
+	plan := &NotificationPlan{
+		message:  message,
+		strategy: message.Strategy,
+		channels: message.Channels,
+	}
+	if len(plan.channels) == 0 {
+		plan.channels = DefaultNotificationChannels
+	}
+	if plan.strategy == "" {
+		plan.strategy = models.NotificationStrategyAll
+	}
+
+	plan.children = make([]NotificationChild, 0, len(plan.channels))
+	plan.targets = make([]channelTarget, 0, len(plan.channels))
+	for _, channel := range plan.channels {
+		if plan.strategy == models.NotificationStrategyFirstAvailable && len(plan.targets) > 0 {
+			plan.children = append(plan.children, NotificationChild{
+				Channel: channel,
+				Status:  NotificationChildSkipped,
+				Reason:  "an earlier channel was selected",
+			})
+			continue
+		}
+
+		target, err := p.resolveChannel(message, channel)
+		if err != nil {
+			logger.WithError(err).WithField("channel", channel).Info("Skipping notification channel")
+			plan.children = append(plan.children, NotificationChild{
+				Channel: channel,
+				Status:  NotificationChildSkipped,
+				Reason:  err.Error(),
+			})
+			continue
+		}
+
+		plan.targets = append(plan.targets, *target)
+		plan.children = append(plan.children, NotificationChild{
+			Channel: channel,
+			Status:  NotificationChildAccepted,
+		})
+	}
+
+	if len(plan.targets) == 0 {
+		logger.Warn("No channel could be resolved for notification")
+		return plan, errors.New("no channel could be resolved for notification")
+	}
+	return plan, nil
+}
+
+// ProduceNotification resolves a notification and produces it, see ResolveNotification and ProducePlan
+func (p *NotificationProducer) ProduceNotification(message *models.NotificationMessage, uuid string) ([]NotificationChild, error) {
+	plan, err := p.ResolveNotification(message)
+	if err != nil {
+		return plan.Children(), err
+	}
+	return p.ProducePlan(plan, uuid)
+}
+
+// ProducePlan records the notification and produces one child message per resolved channel.
+// A child that cannot be queued is marked FAILED and the remaining channels are still produced, so
+// the caller gets the notification UUID back once any child is queued and a retry never sends twice.
+func (p *NotificationProducer) ProducePlan(plan *NotificationPlan, uuid string) ([]NotificationChild, error) {
+	message := plan.message
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"notification_uuid": uuid,
+		"template":          message.Template,
+		"refNo":             message.RefNo,
+		"tenantId":          message.TenantID,
+		"strategy":          plan.strategy,
+	})
+
+	// Convert channels and categories arrays to JSON
+	channelsJSON := models.JSON{}
+	for i, channel := range plan.channels {
+		channelsJSON[fmt.Sprintf("%d", i)] = string(channel)
+	}
+	categoriesJSON := models.JSON{}
+	for i, category := range message.Categories {
+		categoriesJSON[fmt.Sprintf("%d", i)] = category
+	}
+
+	notification := models.Notification{
+		UUID:         uuid,
+		TenantID:     message.TenantID,
+		RefNo:        message.RefNo,
+		TemplateCode: message.Template,
+		Strategy:     plan.strategy,
+		Channels:     channelsJSON,
+		Identifiers:  message.Identifiers,
+		Categories:   categoriesJSON,
+	}
+	if err := p.db.Create(&notification).Error; err != nil {
+		logger.WithError(err).Error("Failed to create notification record")
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	children := append([]NotificationChild(nil), plan.children...)
+	queued := 0
+	targetIdx := 0
+	for i := range children {
+		if children[i].Status != NotificationChildAccepted {
+			continue
+		}
+		target := plan.targets[targetIdx]
+		targetIdx++
+
+		childUUID, err := helper.GenerateUUID()
+		if err != nil {
+			logger.WithError(err).WithField("channel", target.channel).Error("Failed to generate UUID for notification child message")
+			children[i].Status = NotificationChildFailed
+			children[i].Reason = "failed to generate message ID"
+			continue
+		}
+		children[i].UUID = childUUID
+
+		if err := p.produceChild(message, uuid, target, childUUID); err != nil {
+			logger.WithError(err).WithField("channel", target.channel).Error("Failed to produce notification child message")
+			children[i].Status = NotificationChildFailed
+			children[i].Reason = fmt.Sprintf("failed to queue %s message", target.channel)
+			p.markChildFailed(childUUID)
+			continue
+		}
+		queued++
+
+		logger.WithFields(map[string]interface{}{
+			"channel":      target.channel,
+			"message_uuid": childUUID,
+		}).Debug("Notification child message queued")
+	}
+
+	if queued == 0 {
+		logger.Error("No notification child message could be queued")
+		return children, errors.New("no notification child message could be queued")
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"channels": queued,
+		"failed":   len(plan.targets) - queued,
+	}).Info("Notification fanned out")
+	return children, nil
+}
+
+// markChildFailed marks the record of a child message that was stored but could not be queued as FAILED
+func (p *NotificationProducer) markChildFailed(uuid string) {
+	if err := p.db.Model(&models.Message{}).
+		Where("uuid = ? AND status = ?", uuid, models.StatusAccepted).
+		Update("status", models.StatusFailed).Error; err != nil {
+		helper.Log.WithError(err).WithField("message_uuid", uuid).Error("Failed to mark notification child message as failed")
+	}
+}
+
+// resolveChannel finds the template, provider and recipients for a notification on one channel
+func (p *NotificationProducer) resolveChannel(message *models.NotificationMessage, channel models.Channel) (*channelTarget, error) {
+	recipients := notificationRecipients(message, channel)
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no %s contact details for any recipient", channel)
+	}
+
+	// Fall through to the next channel when every recipient has opted out of this one
+	suppressionService, err := services.NewSuppressionService(p.db, p.readerDB)
+	if err != nil {
+		return nil, err
+	}
+	suppressedCount := 0
+	for _, recipient := range recipients {
+		address := recipient.Email
+		switch channel {
+		case models.ChannelWhatsApp:
+			address = whatsAppNumber(recipient)
+		case models.ChannelSMS:
+			address = recipient.Telephone
+		}
+		suppression, err := suppressionService.IsSuppressed(message.TenantID, channel, address)
+		if err != nil {
+			return nil, err
+		}
+		if suppression != nil {
+			suppressedCount++
+		}
+	}
+	if suppressedCount == len(recipients) {
+		return nil, fmt.Errorf("all %s recipients are suppressed", channel)
+	}
+
+	var template models.Template
+	if err := p.readerDB.Where("code = ? AND tenant_id = ? AND channel = ?", message.Template, message.TenantID, channel).
+		Where("status = 1").
+		First(&template).Error; err != nil {
+		return nil, fmt.Errorf("template %s not found or inactive for channel %s", message.Template, channel)
+	}
+
+	query := p.readerDB.Where("tenant_id = ? AND channel = ?", message.TenantID, channel).Where("status = 1")
+	if providerUUID, ok := message.Providers[channel]; ok && providerUUID != "" {
+		query = query.Where("uuid = ?", providerUUID)
+	}
+
+	var provider models.Provider
+	if err := query.Order("id").First(&provider).Error; err != nil {
+		return nil, fmt.Errorf("no active provider for channel %s", channel)
+	}
+
+	return &channelTarget{
+		channel:  channel,
+		template: &template,
+		provider: &provider,
+	}, nil
+}
+
+// produceChild builds the channel-specific message and hands it to the channel producer
+func (p *NotificationProducer) produceChild(message *models.NotificationMessage, notificationUUID string, target channelTarget, uuid string) error {
+	recipients := notificationRecipients(message, target.channel)
+
+	switch target.channel {
+	case models.ChannelWhatsApp:
+		whatsAppMessage := &models.WhatsAppMessage{
+			Template:         target.template.UUID,
+			Provider:         target.provider.UUID,
+			RefNo:            message.RefNo,
+			TenantID:         message.TenantID,
+			Categories:       message.Categories,
+			Identifiers:      message.Identifiers,
+			Params:           message.Params,
+			NotificationUUID: notificationUUID,
+		}
+		for _, recipient := range recipients {
+			whatsAppMessage.To = append(whatsAppMessage.To, models.WhatsAppRecipient{
+				Name:      recipient.Name,
+				Telephone: whatsAppNumber(recipient),
+			})
+		}
+		return p.whatsAppProducer.ProduceWhatsAppMessage(whatsAppMessage, uuid)
+
+	case models.ChannelSMS:
+		// The sender number comes from the provider configuration
+		from, _ := target.provider.Config["fromNumber"].(string)
+		smsMessage := &models.SMSMessage{
+			From:             from,
+			Template:         target.template.UUID,
+			Provider:         target.provider.UUID,
+			RefNo:            message.RefNo,
+			TenantID:         message.TenantID,
+			Categories:       message.Categories,
+			Identifiers:      message.Identifiers,
+			Params:           message.Params,
+			NotificationUUID: notificationUUID,
+		}
+		for _, recipient := range recipients {
+			smsMessage.To = append(smsMessage.To, models.SMSRecipient{
+				Telephone: recipient.Telephone,
+			})
+		}
+		return p.smsProducer.ProduceSMSMessage(smsMessage, uuid)
+
+	case models.ChannelEmail:
+		emailMessage := &models.EmailMessage{
+			Template:         target.template.UUID,
+			Provider:         target.provider.UUID,
+			RefNo:            message.RefNo,
+			TenantID:         message.TenantID,
+			Categories:       message.Categories,
+			Identifiers:      message.Identifiers,
+			Params:           message.Params,
+			Subject:          message.Subject,
+			NotificationUUID: notificationUUID,
+		}
+		for _, recipient := range recipients {
+			emailMessage.To = append(emailMessage.To, models.EmailRecipient{
+				Name:  recipient.Name,
+				Email: recipient.Email,
+			})
+		}
+		return p.emailProducer.ProduceEmailMessage(emailMessage, uuid)
+	}
+
+	return fmt.Errorf("unsupported channel: %s", target.channel)
+}
+
+// notificationRecipients returns the recipients that have contact details for the channel
+func notificationRecipients(message *models.NotificationMessage, channel models.Channel) []models.NotificationRecipient {
+	recipients := []models.NotificationRecipient{}
+	for _, recipient := range message.To {
+		switch channel {
+		case models.ChannelWhatsApp:
+			if whatsAppNumber(recipient) != "" {
+				recipients = append(recipients, recipient)
+			}
+		case models.ChannelSMS:
+			if recipient.Telephone != "" {
+				recipients = append(recipients, recipient)
+			}
+		case models.ChannelEmail:
+			if recipient.Email != "" {
+				recipients = append(recipients, recipient)
+			}
+		}
+	}
+	return recipients
+}
+
+// whatsAppNumber returns the WhatsApp number of a recipient, falling back to the telephone number
+func whatsAppNumber(recipient models.NotificationRecipient) string {
+	if recipient.WhatsApp != "" {
+		return recipient.WhatsApp
+	}
+	return recipient.Telephone
+}
@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"delivery/models"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectResolved expects the suppression, template and provider lookups of a channel that resolves
+func expectResolved(mock sqlmock.Sqlmock, recipients int) {
+	for i := 0; i < recipients; i++ {
+		mock.ExpectQuery(`FROM "suppressions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	mock.ExpectQuery(`FROM "templates"`).WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(1, "template-uuid"))
+	mock.ExpectQuery(`FROM "providers"`).WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(1, "provider-uuid"))
+}
+
+func TestResolveNotification(t *testing.T) {
+	telephoneAndEmail := []models.NotificationRecipient{{Telephone: "+31612345678", Email: "jane@example.com"}}
+	telephoneOnly := []models.NotificationRecipient{{Telephone: "+31612345678"}}
+
+	tests := []struct {
+		name       string
+		message    models.NotificationMessage
+		expect     func(mock sqlmock.Sqlmock)
+		wantStatus map[models.Channel]string
+		wantOrder  []models.Channel
+		wantErr    bool
+	}{
+		{
+			name:    "ALL sends on every default channel",
+			message: models.NotificationMessage{To: telephoneAndEmail},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectResolved(mock, 1)
+				expectResolved(mock, 1)
+				expectResolved(mock, 1)
+			},
+			wantOrder: []models.Channel{models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail},
+			wantStatus: map[models.Channel]string{
+				models.ChannelWhatsApp: NotificationChildAccepted,
+				models.ChannelSMS:      NotificationChildAccepted,
+				models.ChannelEmail:    NotificationChildAccepted,
+			},
+		},
+		{
+			name:    "FIRST_AVAILABLE skips the channels after the first resolved one",
+			message: models.NotificationMessage{To: telephoneAndEmail, Strategy: models.NotificationStrategyFirstAvailable},
+			expect: func(mock sqlmock.Sqlmock) {
+				// WhatsApp has no template
+				mock.ExpectQuery(`FROM "suppressions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery(`FROM "templates"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				expectResolved(mock, 1)
+			},
+			wantOrder: []models.Channel{models.ChannelWhatsApp, models.ChannelSMS, models.ChannelEmail},
+			wantStatus: map[models.Channel]string{
+				models.ChannelWhatsApp: NotificationChildSkipped,
+				models.ChannelSMS:      NotificationChildAccepted,
+				models.ChannelEmail:    NotificationChildSkipped,
+			},
+		},
+		{
+			name: "channels are resolved in the requested order",
+			message: models.NotificationMessage{
+				To:       telephoneOnly,
+				Channels: []models.Channel{models.ChannelEmail, models.ChannelSMS},
+				Strategy: models.NotificationStrategyFirstAvailable,
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				// No recipient has an email address, so email is skipped without a lookup
+				expectResolved(mock, 1)
+			},
+			wantOrder: []models.Channel{models.ChannelEmail, models.ChannelSMS},
+			wantStatus: map[models.Channel]string{
+				models.ChannelEmail: NotificationChildSkipped,
+				models.ChannelSMS:   NotificationChildAccepted,
+			},
+		},
+		{
+			name:    "channel is skipped when every recipient is suppressed",
+			message: models.NotificationMessage{To: telephoneOnly, Channels: []models.Channel{models.ChannelSMS, models.ChannelWhatsApp}},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "suppressions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectResolved(mock, 1)
+			},
+			wantOrder: []models.Channel{models.ChannelSMS, models.ChannelWhatsApp},
+			wantStatus: map[models.Channel]string{
+				models.ChannelSMS:      NotificationChildSkipped,
+				models.ChannelWhatsApp: NotificationChildAccepted,
+			},
+		},
+		{
+			name:    "fails when no channel has an active provider",
+			message: models.NotificationMessage{To: telephoneOnly, Channels: []models.Channel{models.ChannelSMS}},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "suppressions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery(`FROM "templates"`).WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(1, "template-uuid"))
+				mock.ExpectQuery(`FROM "providers"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantOrder:  []models.Channel{models.ChannelSMS},
+			wantStatus: map[models.Channel]string{models.ChannelSMS: NotificationChildSkipped},
+			wantErr:    true,
+		},
+		{
+			name: "requested provider is looked up by UUID",
+			message: models.NotificationMessage{
+				To:        telephoneOnly,
+				TenantID:  "tenant",
+				Channels:  []models.Channel{models.ChannelSMS},
+				Providers: map[models.Channel]string{models.ChannelSMS: "requested-uuid"},
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "suppressions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery(`FROM "templates"`).WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(1, "template-uuid"))
+				mock.ExpectQuery(`FROM "providers" WHERE \(tenant_id = \$1 AND channel = \$2\) AND status = 1 AND uuid = \$3`).
+					WithArgs("tenant", models.ChannelSMS, "requested-uuid", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(2, "requested-uuid"))
+			},
+			wantOrder:  []models.Channel{models.ChannelSMS},
+			wantStatus: map[models.Channel]string{models.ChannelSMS: NotificationChildAccepted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			producer := &NotificationProducer{db: db, readerDB: db}
+			plan, err := producer.ResolveNotification(&tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var order []models.Channel
+			status := map[models.Channel]string{}
+			for _, child := range plan.Children() {
+				order = append(order, child.Channel)
+				status[child.Channel] = child.Status
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("channels = %v, want %v", order, tt.wantOrder)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("statuses = %v, want %v", status, tt.wantStatus)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
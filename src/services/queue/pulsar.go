@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"delivery/helper"
+	"delivery/services/providers/sms"
 
 	"github.com/apache/pulsar-client-go/pulsar"
 	"gorm.io/gorm"
@@ -19,6 +20,7 @@ const (
 	WhatsAppTopic = "delivery-whatsapp"
 	SMSTopic      = "delivery-sms"
 	EmailTopic    = "delivery-email"
+	InboundTopic  = "delivery-inbound"
 )
 
 // PulsarClient wraps the Pulsar client with common operations
@@ -28,9 +30,10 @@ type PulsarClient struct {
 
 // ConsumerManager handles initializing and managing message consumers
 type ConsumerManager struct {
-	pulsarClient *PulsarClient
-	db           *gorm.DB
-	readerDB     *gorm.DB
+	pulsarClient        *PulsarClient
+	db                  *gorm.DB
+	readerDB            *gorm.DB
+	escalationScheduler *EscalationScheduler
 }
 
 // NewPulsarClient creates a new Pulsar client
@@ -77,7 +80,7 @@ func (p *PulsarClient) CreateTopic(topic string) error {
 
 // EnsureTopicsExist creates all required topics if they don't exist
 func (p *PulsarClient) EnsureTopicsExist() error {
-	topics := []string{EmailTopic, SMSTopic, WhatsAppTopic}
+	topics := []string{EmailTopic, SMSTopic, WhatsAppTopic, InboundTopic}
 
 	for _, topic := range topics {
 		helper.Log.Infof("Ensuring topic '%s' exists...", topic)
@@ -202,8 +205,13 @@ func NewConsumerManager(db *gorm.DB, readerDB *gorm.DB) (*ConsumerManager, error
 // StartConsumers starts all message consumers
 func (cm *ConsumerManager) StartConsumers() error {
 	// Start WhatsApp consumer
-	whatsAppConsumer := NewWhatsAppConsumer(cm.pulsarClient, cm.db, cm.readerDB)
-	err := whatsAppConsumer.Start(1) // Start with a single consumer worker
+	whatsAppConsumer, err := NewWhatsAppConsumer(cm.pulsarClient, cm.db, cm.readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create WhatsApp consumer: %v", err)
+		return err
+	}
+
+	err = whatsAppConsumer.Start(1) // Start with a single consumer worker
 	if err != nil {
 		helper.Log.Errorf("Failed to start WhatsApp consumer: %v", err)
 		return err
@@ -235,11 +243,25 @@ func (cm *ConsumerManager) StartConsumers() error {
 		return err
 	}
 
+	// Start escalation scheduler
+	escalationScheduler, err := NewEscalationScheduler(cm.pulsarClient, cm.db, cm.readerDB)
+	if err != nil {
+		helper.Log.Errorf("Failed to create escalation scheduler: %v", err)
+		return err
+	}
+
+	escalationScheduler.Start(escalationPollInterval)
+	cm.escalationScheduler = escalationScheduler
+
 	return nil
 }
 
 // Close closes the Pulsar client connection
 func (cm *ConsumerManager) Close() {
+	if cm.escalationScheduler != nil {
+		cm.escalationScheduler.Stop()
+	}
+	sms.CloseSMPPSessions()
 	if cm.pulsarClient != nil {
 		cm.pulsarClient.Close()
 	}
@@ -344,13 +366,15 @@ func (m *DirectPushEmailMessage) Push() error {
 
 	// Create a message record in the database
 	dbMessage := models.Message{
-		UUID:        m.UUID,
-		Channel:     models.ChannelEmail,
-		Status:      models.StatusAccepted,
-		Identifiers: identifiersJSON,
-		RefNo:       m.Message.RefNo,
-		Categories:  categoriesJSON,
-		TenantID:    m.Message.TenantID,
+		UUID:             m.UUID,
+		Channel:          models.ChannelEmail,
+		Status:           models.StatusAccepted,
+		Identifiers:      identifiersJSON,
+		RefNo:            m.Message.RefNo,
+		Categories:       categoriesJSON,
+		TenantID:         m.Message.TenantID,
+		NotificationUUID: m.Message.NotificationUUID,
+		TemplateUUID:     m.Message.Template,
 	}
 
 	// Save to database
@@ -436,13 +460,15 @@ func (m *DirectPushSMSMessage) Push() error {
 
 	// Create a message record in the database
 	dbMessage := models.Message{
-		UUID:        m.UUID,
-		Channel:     models.ChannelSMS,
-		Status:      models.StatusAccepted,
-		Identifiers: identifiersJSON,
-		RefNo:       m.Message.RefNo,
-		Categories:  categoriesJSON,
-		TenantID:    m.Message.TenantID,
+		UUID:             m.UUID,
+		Channel:          models.ChannelSMS,
+		Status:           models.StatusAccepted,
+		Identifiers:      identifiersJSON,
+		RefNo:            m.Message.RefNo,
+		Categories:       categoriesJSON,
+		TenantID:         m.Message.TenantID,
+		NotificationUUID: m.Message.NotificationUUID,
+		TemplateUUID:     m.Message.Template,
 	}
 
 	// Save to database
@@ -487,8 +513,8 @@ func (m *DirectPushWhatsAppMessage) GetCategories() []string {
 
 // Validate validates the WhatsApp message
 func (m *DirectPushWhatsAppMessage) Validate() error {
-	if m.Message.Template == "" {
-		return errors.New("template is required")
+	if m.Message.Template == "" && m.Message.Text == "" && m.Message.Media == nil && !hasInlineAttachments(m.Message.Attachments) {
+		return errors.New("template, text or media is required")
 	}
 	if len(m.Message.To) == 0 {
 		return errors.New("at least one recipient is required")
@@ -525,12 +551,14 @@ func (m *DirectPushWhatsAppMessage) Push() error {
 
 	// Create a message record in the database
 	dbMessage := models.Message{
-		UUID:        m.UUID,
-		Channel:     models.ChannelWhatsApp,
-		Status:      models.StatusAccepted,
-		Identifiers: identifiersJSON,
-		RefNo:       m.Message.RefNo,
-		Categories:  categoriesJSON,
+		UUID:             m.UUID,
+		Channel:          models.ChannelWhatsApp,
+		Status:           models.StatusAccepted,
+		Identifiers:      identifiersJSON,
+		RefNo:            m.Message.RefNo,
+		Categories:       categoriesJSON,
+		NotificationUUID: m.Message.NotificationUUID,
+		TemplateUUID:     m.Message.Template,
 	}
 
 	// Save to database
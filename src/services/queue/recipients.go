@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+
+	"gorm.io/gorm"
+)
+
+// recordRecipient stores the address an outbound message was sent to so that replies and delivery receipts
+// can be correlated with it. providerMessageID is empty for providers that do not report one.
+func recordRecipient(db *gorm.DB, dbMessage *models.Message, providerUUID string, channel models.Channel, address string, providerMessageID string) {
+	recipient := models.MessageRecipient{
+		MessageID:         dbMessage.ID,
+		MessageUUID:       dbMessage.UUID,
+		TenantID:          dbMessage.TenantID,
+		ProviderUUID:      providerUUID,
+		Channel:           channel,
+		Address:           services.NormalizeAddress(channel, address),
+		ProviderMessageID: providerMessageID,
+	}
+	if err := db.Create(&recipient).Error; err != nil {
+		helper.Log.WithError(err).WithField("message_uuid", dbMessage.UUID).Error("Failed to record message recipient")
+	}
+}
+
+// recordTemplateVersion stores which version of its template a message is rendered from, so the exact
+// content sent can be looked up in the template history later
+func recordTemplateVersion(db *gorm.DB, dbMessage *models.Message, template *models.Template) {
+	dbMessage.TemplateVersion = template.Version
+	if err := db.Model(dbMessage).Update("template_version", template.Version).Error; err != nil {
+		helper.Log.WithError(err).WithField("message_uuid", dbMessage.UUID).Error("Failed to record message template version")
+	}
+}
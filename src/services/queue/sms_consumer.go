@@ -4,7 +4,9 @@ import (
 	"context"
 	"delivery/helper"
 	"delivery/models"
+	"delivery/services"
 	"delivery/services/providers"
+	"delivery/services/providers/sms"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,15 +28,23 @@ type SMSConsumer struct {
 	readerDB     *gorm.DB
 	consumer     pulsar.Consumer
 	running      bool
+
+	deliveryStatusService *services.DeliveryStatusService
 }
 
 // NewSMSConsumer creates a new SMS consumer
 func NewSMSConsumer(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB) (*SMSConsumer, error) {
+	deliveryStatusService, err := services.NewDeliveryStatusService(db, readerDB)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SMSConsumer{
-		pulsarClient: pulsarClient,
-		db:           db,
-		readerDB:     readerDB,
-		running:      false,
+		pulsarClient:          pulsarClient,
+		db:                    db,
+		readerDB:              readerDB,
+		running:               false,
+		deliveryStatusService: deliveryStatusService,
 	}, nil
 }
 
@@ -59,6 +69,9 @@ func (c *SMSConsumer) Start() error {
 		return err
 	}
 
+	// Delivery receipts of SMPP binds arrive outside the queue
+	sms.SetDeliveryReceiptHandler(c.recordDeliveryReceipt)
+
 	c.running = true
 	go c.consume()
 	return nil
@@ -139,6 +152,7 @@ func (c *SMSConsumer) processSMSMessage(msg pulsar.Message) error {
 	if err != nil {
 		return c.rejectMessage(dbMessage, fmt.Sprintf("template not found or inactive: %s", message.Template))
 	}
+	recordTemplateVersion(c.db, dbMessage, template)
 
 	// Check if provider exists and is active
 	var provider models.Provider
@@ -152,6 +166,12 @@ func (c *SMSConsumer) processSMSMessage(msg pulsar.Message) error {
 
 	messageLogger.WithField("provider", provider.Provider).Info("Found SMS provider in database")
 
+	// Leave the message in the queue while the provider's circuit is open, it is redelivered later
+	if !services.ProviderAvailable(provider.UUID) {
+		messageLogger.WithField("provider_uuid", provider.UUID).Warn("SMS provider circuit is open, delaying message")
+		return fmt.Errorf("%w: %s", services.ErrCircuitOpen, provider.UUID)
+	}
+
 	// Create SMS service based on provider using the factory
 	smsService, provErr := providers.CreateSMSProvider(&provider)
 	if provErr != nil {
@@ -181,6 +201,16 @@ func (c *SMSConsumer) processSMSMessage(msg pulsar.Message) error {
 
 	toNumber := message.To[0].Telephone
 
+	// Never contact a recipient who is on the suppression list
+	suppressed, err := checkSuppressed(c.db, c.readerDB, dbMessage, models.ChannelSMS, toNumber)
+	if err != nil {
+		messageLogger.WithError(err).Error("Failed to check suppression list")
+		return err
+	}
+	if suppressed {
+		return c.updateMessageStatus(dbMessage.UUID, models.StatusSuppressed)
+	}
+
 	// Render the template with variables using Go's text/template
 	messageLogger.WithFields(map[string]interface{}{
 		"telephone": toNumber,
@@ -223,7 +253,14 @@ func (c *SMSConsumer) processSMSMessage(msg pulsar.Message) error {
 	paramsWithRenderedContent["rendered_content"] = renderedContent
 
 	// Send the SMS with the rendered template content using template API
-	if err := smsService.SendTemplate(toNumber, template.Name, paramsWithRenderedContent); err != nil {
+	err = services.CallProvider(provider.UUID, func() error {
+		return smsService.SendTemplate(toNumber, template.Name, paramsWithRenderedContent)
+	})
+	if errors.Is(err, services.ErrCircuitOpen) {
+		messageLogger.Warn("SMS provider circuit opened, delaying message")
+		return err
+	}
+	if err != nil {
 		errMsg := fmt.Sprintf("Failed to send SMS message to %s: %v", toNumber, err)
 		messageLogger.WithError(err).Error("Failed to send SMS message")
 		return c.rejectMessage(dbMessage, errMsg)
@@ -235,6 +272,13 @@ func (c *SMSConsumer) processSMSMessage(msg pulsar.Message) error {
 		messageLogger.WithError(eventErr).Error("Failed to create success event")
 	}
 
+	// Remember the recipient so replies and delivery receipts can be matched to this message
+	providerMessageID := ""
+	if reporter, ok := smsService.(services.MessageIDReporter); ok {
+		providerMessageID = reporter.LastMessageID()
+	}
+	recordRecipient(c.db, dbMessage, provider.UUID, models.ChannelSMS, toNumber, providerMessageID)
+
 	// Update message status to SENT
 	dbMessage.Status = models.StatusSent
 	if err := c.db.Save(dbMessage).Error; err != nil {
@@ -278,13 +322,15 @@ func (c *SMSConsumer) fetchOrCreateMessageFromDB(messageUUID string, message mod
 			}
 		}
 		newMessage := models.Message{
-			UUID:        uuid,
-			Channel:     models.ChannelSMS,
-			Status:      models.StatusAccepted,
-			RefNo:       message.RefNo,
-			Identifiers: identifiersJSON,
-			Categories:  categoriesJSON,
-			TenantID:    message.TenantID,
+			UUID:             uuid,
+			Channel:          models.ChannelSMS,
+			Status:           models.StatusAccepted,
+			RefNo:            message.RefNo,
+			Identifiers:      identifiersJSON,
+			Categories:       categoriesJSON,
+			TenantID:         message.TenantID,
+			NotificationUUID: message.NotificationUUID,
+			TemplateUUID:     message.Template,
 		}
 
 		if err := c.db.Create(&newMessage).Error; err != nil {
@@ -428,3 +474,64 @@ func (c *SMSConsumer) updateMessageTimestamp(message *models.Message) error {
 func (c *SMSConsumer) updateMessageStatus(uuid string, status models.Status) error {
 	return c.db.Model(&models.Message{}).Where("uuid = ?", uuid).Update("status", status).Error
 }
+
+// smppReceiptStates maps the final states of SMPP delivery receipts to message events
+var smppReceiptStates = map[string]models.MessageEventType{
+	"DELIVRD": models.EventStatusDelivered,
+	"EXPIRED": models.EventStatusFailed,
+	"DELETED": models.EventStatusFailed,
+	"UNDELIV": models.EventStatusFailed,
+	"REJECTD": models.EventStatusFailed,
+	"UNKNOWN": models.EventStatusFailed,
+}
+
+// recordDeliveryReceipt records an SMPP delivery receipt as an event of the message it refers to
+func (c *SMSConsumer) recordDeliveryReceipt(receipt sms.DeliveryReceipt) {
+	logger := helper.Log.WithFields(map[string]interface{}{
+		"provider_uuid":       receipt.ProviderUUID,
+		"provider_message_id": receipt.MessageID,
+		"state":               receipt.State,
+	})
+
+	eventStatus, final := smppReceiptStates[receipt.State]
+	if !final {
+		logger.Debug("Ignoring intermediate SMPP delivery receipt")
+		return
+	}
+	if receipt.MessageID == "" {
+		logger.Warn("SMPP delivery receipt has no message ID")
+		return
+	}
+
+	update := services.DeliveryStatusUpdate{
+		ProviderUUID:      receipt.ProviderUUID,
+		ProviderMessageID: receipt.MessageID,
+		Status:            eventStatus,
+		Metadata: models.JSON{
+			"state":     receipt.State,
+			"errorCode": receipt.ErrorCode,
+		},
+		Timestamp: receipt.Timestamp,
+	}
+	if eventStatus == models.EventStatusFailed {
+		update.Reason = fmt.Sprintf("SMPP delivery receipt %s, error code %s", receipt.State, receipt.ErrorCode)
+	}
+
+	// The receipt can arrive before the recipient was recorded, so look it up a few times
+	for attempt := 0; ; attempt++ {
+		recipient, err := c.deliveryStatusService.Record(update)
+		if err == nil {
+			logger.WithField("message_uuid", recipient.MessageUUID).Info("Recorded SMPP delivery receipt")
+			return
+		}
+		if !errors.Is(err, services.ErrUnknownProviderMessage) {
+			logger.WithError(err).Error("Failed to record SMPP delivery receipt")
+			return
+		}
+		if attempt == 4 {
+			logger.Warn("No message found for SMPP delivery receipt")
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
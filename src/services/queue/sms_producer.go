@@ -44,13 +44,15 @@ func (p *SMSProducer) ProduceSMSMessage(message *models.SMSMessage, uuid string)
 
 	// Create a message record in the database
 	dbMessage := models.Message{
-		UUID:        uuid,
-		Channel:     models.ChannelSMS,
-		Status:      models.StatusAccepted,
-		Identifiers: identifiersJSON,
-		RefNo:       message.RefNo,
-		Categories:  categoriesJSON,
-		TenantID:    message.TenantID,
+		UUID:             uuid,
+		Channel:          models.ChannelSMS,
+		Status:           models.StatusAccepted,
+		Identifiers:      identifiersJSON,
+		RefNo:            message.RefNo,
+		Categories:       categoriesJSON,
+		TenantID:         message.TenantID,
+		NotificationUUID: message.NotificationUUID,
+		TemplateUUID:     message.Template,
 	}
 
 	// Save to database
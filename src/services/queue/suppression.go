@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"delivery/services"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// checkSuppressed looks up an address on the suppression list and records a SUPPRESSED event when it is found.
+// It returns true when the address must not be contacted.
+func checkSuppressed(db *gorm.DB, readerDB *gorm.DB, dbMessage *models.Message, channel models.Channel, address string) (bool, error) {
+	suppressionService, err := services.NewSuppressionService(db, readerDB)
+	if err != nil {
+		return false, err
+	}
+
+	suppression, err := suppressionService.IsSuppressed(dbMessage.TenantID, channel, address)
+	if err != nil {
+		return false, err
+	}
+	if suppression == nil {
+		return false, nil
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"message_uuid":     dbMessage.UUID,
+		"channel":          channel,
+		"suppression_uuid": suppression.UUID,
+		"reason":           suppression.Reason,
+	}).Info("Recipient is suppressed, skipping send")
+
+	event := models.MessageEvent{
+		MessageID: dbMessage.ID,
+		Status:    models.EventStatusSuppressed,
+		Reason:    fmt.Sprintf("Recipient %s is suppressed: %s", address, suppression.Reason),
+		Metadata: models.JSON{
+			"address":         address,
+			"suppressionUuid": suppression.UUID,
+			"source":          string(suppression.Source),
+		},
+		Timestamp: time.Now().UTC(),
+	}
+	if err := helper.InsertMessageEvent(db, event); err != nil {
+		helper.Log.WithError(err).WithField("message_uuid", dbMessage.UUID).Error("Failed to create suppressed event")
+	}
+
+	return true, nil
+}
@@ -6,6 +6,8 @@ import (
 	"delivery/services"
 	"delivery/services/providers"
 	"delivery/services/providers/whatsapp"
+	"delivery/services/storage"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,18 +19,34 @@ import (
 
 // WhatsAppConsumer handles consuming WhatsApp messages from the queue
 type WhatsAppConsumer struct {
-	pulsarClient *PulsarClient
-	db           *gorm.DB
-	readerDB     *gorm.DB
+	pulsarClient   *PulsarClient
+	db             *gorm.DB
+	readerDB       *gorm.DB
+	sessionService *services.WhatsAppSessionService
+	mediaStore     storage.Store
+	fetcher        *storage.Fetcher
 }
 
 // NewWhatsAppConsumer creates a new WhatsApp consumer
-func NewWhatsAppConsumer(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB) *WhatsAppConsumer {
-	return &WhatsAppConsumer{
-		pulsarClient: pulsarClient,
-		db:           db,
-		readerDB:     readerDB,
+func NewWhatsAppConsumer(pulsarClient *PulsarClient, db *gorm.DB, readerDB *gorm.DB) (*WhatsAppConsumer, error) {
+	sessionService, err := services.NewWhatsAppSessionService(db, readerDB)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaStore, err := storage.NewStoreFromEnv()
+	if err != nil {
+		return nil, err
 	}
+
+	return &WhatsAppConsumer{
+		pulsarClient:   pulsarClient,
+		db:             db,
+		readerDB:       readerDB,
+		sessionService: sessionService,
+		mediaStore:     mediaStore,
+		fetcher:        storage.NewFetcherFromEnv(),
+	}, nil
 }
 
 // Start starts consuming messages
@@ -61,12 +79,13 @@ func (c *WhatsAppConsumer) createRejectionEvent(messageID uint, reason string) e
 	return nil
 }
 
-// createSuccessEvent creates a message event with success status
-func (c *WhatsAppConsumer) createSuccessEvent(messageID uint) error {
+// createSuccessEvent creates a message event with success status and the send mode that was used
+func (c *WhatsAppConsumer) createSuccessEvent(messageID uint, mode string) error {
 	event := models.MessageEvent{
 		MessageID: messageID,
 		Status:    models.EventStatusSent,
 		Reason:    "Message sent successfully",
+		Metadata:  models.JSON{"mode": mode},
 		Timestamp: time.Now(),
 	}
 	if err := helper.InsertMessageEvent(c.db, event); err != nil {
@@ -124,13 +143,15 @@ func (c *WhatsAppConsumer) fetchOrCreateMessageFromDB(messageUUID string, messag
 			}
 		}
 		newMessage := models.Message{
-			UUID:        uuid,
-			Channel:     models.ChannelWhatsApp,
-			Status:      models.StatusAccepted,
-			RefNo:       message.RefNo,
-			Identifiers: identifiersJSON,
-			Categories:  categoriesJSON,
-			TenantID:    message.TenantID,
+			UUID:             uuid,
+			Channel:          models.ChannelWhatsApp,
+			Status:           models.StatusAccepted,
+			RefNo:            message.RefNo,
+			Identifiers:      identifiersJSON,
+			Categories:       categoriesJSON,
+			TenantID:         message.TenantID,
+			NotificationUUID: message.NotificationUUID,
+			TemplateUUID:     message.Template,
 		}
 
 		if err := c.db.Create(&newMessage).Error; err != nil {
@@ -257,73 +278,100 @@ func (c *WhatsAppConsumer) getProviderTemplateID(template *models.Template, prov
 	return providerIDStr, nil
 }
 
-// sendToRecipients sends messages to all recipients
+// whatsAppContent holds what can be sent to a recipient, the consumer picks per recipient
+// depending on whether their session is open
+type whatsAppContent struct {
+	templateID      string // Approved provider template, empty when the template has none
+	templateContent string
+	params          map[string]string
+	text            string
+	media           []models.WhatsAppMedia // Free-form media followed by stored attachments
+	noTemplate      string                 // Why no approved template is available
+}
+
+// WhatsApp send modes recorded on the SENT event
+const (
+	whatsAppModeTemplate = "TEMPLATE"
+	whatsAppModeText     = "TEXT"
+	whatsAppModeMedia    = "MEDIA"
+)
+
+// sendToRecipients sends messages to all recipients. When the provider's circuit opens part way
+// through, it stops and returns ErrCircuitOpen so the message is redelivered, and the recipients
+// already sent to are skipped on the next attempt.
 func (c *WhatsAppConsumer) sendToRecipients(
 	whatsappProvider services.WhatsAppService,
 	dbMessage *models.Message,
+	providerUUID string,
 	recipients []models.WhatsAppRecipient,
-	templateID string,
-	params map[string]string,
-	templateContent string,
-) {
+	content whatsAppContent,
+) error {
 	helper.Log.WithField("recipient_count", len(recipients)).Info("Processing recipients")
 
+	// Recipients are recorded once they are sent, a redelivered message skips them
+	var sentAddresses []string
+	if err := c.db.Model(&models.MessageRecipient{}).
+		Where("message_id = ?", dbMessage.ID).
+		Pluck("address", &sentAddresses).Error; err != nil {
+		helper.Log.WithError(err).WithField("message_uuid", dbMessage.UUID).Error("Failed to fetch recipients already sent to")
+		return fmt.Errorf("failed to fetch message recipients: %w", err)
+	}
+	alreadySent := make(map[string]bool, len(sentAddresses))
+	for _, address := range sentAddresses {
+		alreadySent[address] = true
+	}
+
 	// Track if any message was sent successfully
 	atLeastOneSuccess := false
+	suppressedCount := 0
 
 	for _, recipient := range recipients {
-		helper.Log.WithFields(map[string]interface{}{
-			"telephone":   recipient.Telephone,
-			"template_id": templateID,
-		}).Info("Sending WhatsApp message")
-
-		// Render the template with variables using Go's text/template
-		helper.Log.WithFields(map[string]interface{}{
-			"telephone": recipient.Telephone,
-			"params":    params,
-			"template":  templateContent,
-		}).Debug("Rendering template with parameters")
+		if alreadySent[services.NormalizeAddress(models.ChannelWhatsApp, recipient.Telephone)] {
+			helper.Log.WithField("telephone", recipient.Telephone).Info("Recipient already sent to, skipping")
+			atLeastOneSuccess = true
+			continue
+		}
 
-		renderedContent, err := helper.RenderTemplate(templateContent, params)
+		// Never contact a recipient who is on the suppression list
+		suppressed, err := checkSuppressed(c.db, c.readerDB, dbMessage, models.ChannelWhatsApp, recipient.Telephone)
 		if err != nil {
-			errMsg := fmt.Sprintf("Failed to render template for %s: %v", recipient.Telephone, err)
-			helper.Log.WithError(err).WithFields(map[string]interface{}{
-				"telephone": recipient.Telephone,
-				"params":    params,
-				"template":  templateContent,
-			}).Error("Template rendering failed")
-
-			// Update message status to REJECTED
-			dbMessage.Status = models.StatusRejected
-			if dbErr := c.db.Save(dbMessage).Error; dbErr != nil {
-				helper.Log.WithError(dbErr).Error("Failed to update message status to REJECTED")
-			}
-
-			// Create message event with error text but continue with next recipient
-			eventErr := c.createRejectionEvent(dbMessage.ID, errMsg)
-			if eventErr != nil {
+			helper.Log.WithError(err).WithField("telephone", recipient.Telephone).Error("Failed to check suppression list")
+			if eventErr := c.createRejectionEvent(dbMessage.ID, fmt.Sprintf("Failed to check suppression list for %s: %v", recipient.Telephone, err)); eventErr != nil {
 				helper.Log.WithError(eventErr).Error("Failed to create rejection event")
 			}
 			continue
 		}
+		if suppressed {
+			suppressedCount++
+			continue
+		}
+
+		// Free-form content is only allowed inside the recipient's customer-service window
+		sessionOpen := false
+		session, err := c.sessionService.OpenSession(dbMessage.TenantID, providerUUID, recipient.Telephone, time.Now())
+		if err != nil {
+			helper.Log.WithError(err).WithField("telephone", recipient.Telephone).Warn("Failed to check WhatsApp session, treating it as closed")
+		} else {
+			sessionOpen = session != nil
+		}
 
 		helper.Log.WithFields(map[string]interface{}{
-			"telephone":        recipient.Telephone,
-			"original_content": templateContent,
-			"rendered_content": renderedContent,
-		}).Debug("Template rendered successfully")
+			"telephone":    recipient.Telephone,
+			"template_id":  content.templateID,
+			"session_open": sessionOpen,
+		}).Info("Sending WhatsApp message")
 
-		// For Twilio WhatsApp, update the params with the rendered content
-		// This way we preserve the original API contract but enhance it with the rendered template
-		paramsWithRenderedContent := make(map[string]string)
-		for k, v := range params {
-			paramsWithRenderedContent[k] = v
+		// Recipients after the circuit opened are failed at once instead of waiting for timeouts
+		var mode string
+		err = services.CallProvider(providerUUID, func() error {
+			var sendErr error
+			mode, sendErr = c.sendToRecipient(whatsappProvider, recipient.Telephone, content, sessionOpen)
+			return sendErr
+		})
+		if errors.Is(err, services.ErrCircuitOpen) {
+			helper.Log.WithField("provider_uuid", providerUUID).Warn("WhatsApp provider circuit opened, delaying remaining recipients")
+			return err
 		}
-		// Add a special parameter for the rendered content if needed by providers
-		paramsWithRenderedContent["rendered_content"] = renderedContent
-
-		// Send the template message using the provider-specific template ID
-		err = whatsappProvider.SendTemplate(recipient.Telephone, templateID, paramsWithRenderedContent)
 		if err != nil {
 			errMsg := fmt.Sprintf("Failed to send WhatsApp message to %s: %v", recipient.Telephone, err)
 			helper.Log.WithError(err).WithField("telephone", recipient.Telephone).Error("Send failed")
@@ -343,26 +391,164 @@ func (c *WhatsAppConsumer) sendToRecipients(
 		}
 
 		// Create successful send event
-		eventErr := c.createSuccessEvent(dbMessage.ID)
+		eventErr := c.createSuccessEvent(dbMessage.ID, mode)
 		if eventErr != nil {
 			helper.Log.WithError(eventErr).Error("Failed to create success event")
 		} else {
-			helper.Log.WithField("telephone", recipient.Telephone).Info("Message sent successfully")
+			helper.Log.WithFields(map[string]interface{}{
+				"telephone": recipient.Telephone,
+				"mode":      mode,
+			}).Info("Message sent successfully")
 			atLeastOneSuccess = true
 		}
+
+		// Remember the recipient so replies and statuses can be matched to this message
+		providerMessageID := ""
+		if reporter, ok := whatsappProvider.(services.MessageIDReporter); ok {
+			providerMessageID = reporter.LastMessageID()
+		}
+		recordRecipient(c.db, dbMessage, providerUUID, models.ChannelWhatsApp, recipient.Telephone, providerMessageID)
 	}
 
 	// Update final message status based on success/failure
-	if !atLeastOneSuccess {
-		dbMessage.Status = models.StatusRejected
-	} else {
+	if atLeastOneSuccess {
 		dbMessage.Status = models.StatusSent
+	} else if suppressedCount > 0 && suppressedCount == len(recipients) {
+		dbMessage.Status = models.StatusSuppressed
+	} else {
+		dbMessage.Status = models.StatusRejected
 	}
 
 	// Save the final status
 	if err := c.db.Save(dbMessage).Error; err != nil {
 		helper.Log.WithError(err).Error("Failed to update final message status")
 	}
+	return nil
+}
+
+// sendToRecipient sends free-form content when the session is open and falls back to the
+// approved template otherwise. It returns the mode that was used.
+func (c *WhatsAppConsumer) sendToRecipient(whatsappProvider services.WhatsAppService, to string, content whatsAppContent, sessionOpen bool) (string, error) {
+	switch {
+	case sessionOpen && len(content.media) > 0:
+		// The first media item carries the text, or the rendered template when no text is given
+		caption := content.text
+		if caption == "" && content.templateContent != "" {
+			renderedContent, err := helper.RenderTemplate(content.templateContent, content.params)
+			if err != nil {
+				return "", fmt.Errorf("failed to render template: %w", err)
+			}
+			caption = renderedContent
+		}
+		for i, media := range content.media {
+			mediaCaption := media.Caption
+			if mediaCaption == "" && i == 0 {
+				mediaCaption = caption
+			}
+			if err := whatsappProvider.SendMedia(to, mediaCaption, media.Type, media.URL); err != nil {
+				return whatsAppModeMedia, err
+			}
+		}
+		return whatsAppModeMedia, nil
+
+	case sessionOpen && content.text != "":
+		return whatsAppModeText, whatsappProvider.SendText(to, content.text)
+
+	case content.templateID != "":
+		renderedContent, err := helper.RenderTemplate(content.templateContent, content.params)
+		if err != nil {
+			return "", fmt.Errorf("failed to render template: %w", err)
+		}
+
+		// For Twilio WhatsApp, update the params with the rendered content
+		// This way we preserve the original API contract but enhance it with the rendered template
+		paramsWithRenderedContent := make(map[string]string)
+		for k, v := range content.params {
+			paramsWithRenderedContent[k] = v
+		}
+		// Add a special parameter for the rendered content if needed by providers
+		paramsWithRenderedContent["rendered_content"] = renderedContent
+
+		// Media templates reference the first media item through the media_url variable
+		if len(content.media) > 0 {
+			paramsWithRenderedContent["media_url"] = content.media[0].URL
+		}
+
+		// Send the template message using the provider-specific template ID
+		return whatsAppModeTemplate, whatsappProvider.SendTemplate(to, content.templateID, paramsWithRenderedContent)
+
+	case sessionOpen && content.templateContent != "":
+		// Inside the session a template without an approved provider ID is sent as rendered text
+		renderedContent, err := helper.RenderTemplate(content.templateContent, content.params)
+		if err != nil {
+			return "", fmt.Errorf("failed to render template: %w", err)
+		}
+		return whatsAppModeText, whatsappProvider.SendText(to, renderedContent)
+	}
+
+	reason := "no approved template is available"
+	if content.noTemplate != "" {
+		reason = content.noTemplate
+	}
+	return "", fmt.Errorf("WhatsApp session is closed and %s", reason)
+}
+
+// hasInlineAttachments reports whether a message carries inline attachments
+func hasInlineAttachments(attachments *models.WhatsAppAttachments) bool {
+	return attachments != nil && len(attachments.Inline) > 0
+}
+
+// storeAttachments makes sure inline attachments are in the media store and returns them as media with signed URLs
+func (c *WhatsAppConsumer) storeAttachments(messageUUID string, attachments *models.WhatsAppAttachments) ([]models.WhatsAppMedia, error) {
+	if !hasInlineAttachments(attachments) {
+		return nil, nil
+	}
+
+	media := make([]models.WhatsAppMedia, 0, len(attachments.Inline))
+	for i, attachment := range attachments.Inline {
+		// Attachments accepted by the API are already in the store
+		key := attachment.StorageKey
+		contentType := attachment.Type
+		if key == "" {
+			object := &storage.Object{ContentType: attachment.Type}
+			if attachment.URL != "" {
+				// Referenced files are fetched from the allowlisted host, providers cannot reach internal URLs
+				fetched, err := c.fetcher.Fetch(attachment.URL, attachment.Type, storage.LimitsForChannel(models.ChannelWhatsApp).MaxFileSize)
+				if err != nil {
+					return nil, err
+				}
+				object = fetched
+				contentType = fetched.ContentType
+			} else {
+				data, err := base64.StdEncoding.DecodeString(attachment.Content)
+				if err != nil {
+					return nil, fmt.Errorf("attachment %s is not valid base64: %w", attachment.Filename, err)
+				}
+				object.Data = data
+			}
+
+			key = storage.AttachmentKey(messageUUID, i, attachment.Filename)
+			if err := c.mediaStore.Put(key, object); err != nil {
+				return nil, err
+			}
+		}
+
+		mediaURL, err := storage.SignedMediaURL(key, storage.DefaultMediaURLTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		helper.Log.WithFields(map[string]interface{}{
+			"message_uuid": messageUUID,
+			"key":          key,
+		}).Debug("Signed WhatsApp attachment URL")
+
+		media = append(media, models.WhatsAppMedia{
+			URL:  mediaURL,
+			Type: contentType,
+		})
+	}
+	return media, nil
 }
 
 // updateMessageTimestamp updates the message timestamp in the database
@@ -404,10 +590,8 @@ func (c *WhatsAppConsumer) handleMessage(data []byte) error {
 		return err
 	}
 
-	// Check if template exists in our database
-	template, err := c.fetchTemplateFromDB(message.Template, message.TenantID)
-	if err != nil {
-		return c.rejectMessage(dbMessage, fmt.Sprintf("template not found or inactive: %s", message.Template))
+	if message.Template == "" && message.Text == "" && message.Media == nil && !hasInlineAttachments(message.Attachments) {
+		return c.rejectMessage(dbMessage, "template, text or media is required")
 	}
 
 	// Check if provider exists and is active
@@ -416,13 +600,55 @@ func (c *WhatsAppConsumer) handleMessage(data []byte) error {
 		return c.rejectMessage(dbMessage, fmt.Sprintf("provider not found or inactive: %s", message.Provider))
 	}
 
-	// Extract provider-specific template ID from template_ids JSON field
-	templateID, err := c.getProviderTemplateID(template, provider.Provider)
+	content := whatsAppContent{
+		params: message.Params,
+		text:   message.Text,
+	}
+	if message.Media != nil {
+		content.media = append(content.media, *message.Media)
+	}
+
+	// Inline attachments are stored and sent as signed URLs the provider can fetch
+	attachmentMedia, err := c.storeAttachments(messageUUID, message.Attachments)
 	if err != nil {
-		return c.rejectMessage(dbMessage, err.Error())
+		return c.rejectMessage(dbMessage, fmt.Sprintf("failed to store attachments: %v", err))
+	}
+	content.media = append(content.media, attachmentMedia...)
+
+	if message.Template != "" {
+		// Check if template exists in our database
+		template, err := c.fetchTemplateFromDB(message.Template, message.TenantID)
+		if err != nil {
+			return c.rejectMessage(dbMessage, fmt.Sprintf("template not found or inactive: %s", message.Template))
+		}
+		recordTemplateVersion(c.db, dbMessage, template)
+		content.templateContent = template.Content
+
+		// Extract provider-specific template ID from template_ids JSON field. Without one the
+		// template can still be sent as text to recipients with an open session.
+		templateID, err := c.getProviderTemplateID(template, provider.Provider)
+		if err != nil {
+			content.noTemplate = err.Error()
+		}
+		content.templateID = templateID
+
+		// Log the template content that will be used
+		helper.Log.WithFields(map[string]interface{}{
+			"template_uuid": template.UUID,
+			"template_name": template.Name,
+			"content":       template.Content,
+		}).Debug("Using template content for rendering")
+	} else {
+		content.noTemplate = "no template was given"
 	}
 
-	// Use the provider to send the template
+	// Leave the message in the queue while the provider's circuit is open, it is redelivered later
+	if !services.ProviderAvailable(provider.UUID) {
+		helper.Log.WithField("provider_uuid", provider.UUID).Warn("WhatsApp provider circuit is open, delaying message")
+		return fmt.Errorf("%w: %s", services.ErrCircuitOpen, provider.UUID)
+	}
+
+	// Use the provider to send the message
 	whatsappProvider, err := c.createProviderFromConfig(provider)
 	if err != nil {
 		return c.rejectMessage(dbMessage, fmt.Sprintf("failed to create WhatsApp provider: %v", err))
@@ -435,15 +661,10 @@ func (c *WhatsAppConsumer) handleMessage(data []byte) error {
 		return fmt.Errorf("failed to update message status: %w", err)
 	}
 
-	// Log the template content that will be used
-	helper.Log.WithFields(map[string]interface{}{
-		"template_uuid": template.UUID,
-		"template_name": template.Name,
-		"content":       template.Content,
-	}).Debug("Using template content for rendering")
-
-	// Send to all recipients with the template content
-	c.sendToRecipients(whatsappProvider, dbMessage, message.To, templateID, message.Params, template.Content)
+	// Send to all recipients, picking free-form or template content per recipient session
+	if err := c.sendToRecipients(whatsappProvider, dbMessage, provider.UUID, message.To, content); err != nil {
+		return err
+	}
 
 	// Update message timestamp
 	return c.updateMessageTimestamp(dbMessage)
@@ -39,13 +39,15 @@ func (p *WhatsAppProducer) ProduceWhatsAppMessage(message *models.WhatsAppMessag
 	}
 
 	dbMessage := models.Message{
-		UUID:        uuid,
-		Channel:     models.ChannelWhatsApp,
-		Identifiers: identifiersJSON,
-		Categories:  categoriesJSON,
-		RefNo:       message.RefNo,
-		Status:      models.StatusAccepted,
-		TenantID:    message.TenantID,
+		UUID:             uuid,
+		Channel:          models.ChannelWhatsApp,
+		Identifiers:      identifiersJSON,
+		Categories:       categoriesJSON,
+		RefNo:            message.RefNo,
+		Status:           models.StatusAccepted,
+		TenantID:         message.TenantID,
+		NotificationUUID: message.NotificationUUID,
+		TemplateUUID:     message.Template,
 	}
 
 	if err := p.db.Create(&dbMessage).Error; err != nil {
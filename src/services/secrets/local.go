@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envNamePattern matches the references the env backend accepts
+var envNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// EnvStore loads secrets from environment variables holding the secure config JSON.
+// Only variables starting with the prefix can be referenced, so a provider cannot read the
+// service's own configuration.
+type EnvStore struct {
+	Prefix string
+}
+
+// NewEnvStore creates a store for the environment variables starting with prefix
+func NewEnvStore(prefix string) *EnvStore {
+	return &EnvStore{Prefix: prefix}
+}
+
+// Load implements the Store.Load method, "TWILIO" loads DELIVERY_SECRET_TWILIO with the default prefix
+func (s *EnvStore) Load(ref string) ([]byte, error) {
+	if !envNamePattern.MatchString(ref) {
+		return nil, fmt.Errorf("invalid environment variable name: %s", ref)
+	}
+
+	value, ok := os.LookupEnv(s.Prefix + strings.ToUpper(ref))
+	if !ok || value == "" {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// FileStore loads secrets from JSON files under a directory, such as mounted Docker or Kubernetes secrets
+type FileStore struct {
+	Dir string
+}
+
+// NewFileStore creates a store for the files under dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{Dir: dir}
+}
+
+// Load implements the Store.Load method, the reference is a path relative to the secrets directory
+func (s *FileStore) Load(ref string) ([]byte, error) {
+	if filepath.IsAbs(ref) || !filepath.IsLocal(ref) {
+		return nil, fmt.Errorf("secret file must be relative to the secrets directory: %s", ref)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.Dir, ref))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
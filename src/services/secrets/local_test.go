@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvStoreLoad(t *testing.T) {
+	t.Setenv("TEST_SECRET_TWILIO", `{"authToken":"secret"}`)
+	t.Setenv("TEST_SECRET_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+		invalid bool
+	}{
+		{name: "prefixed variable", ref: "TWILIO", want: `{"authToken":"secret"}`},
+		{name: "lower-case reference", ref: "twilio", want: `{"authToken":"secret"}`},
+		{name: "missing variable", ref: "SENDGRID", wantErr: ErrNotFound},
+		{name: "empty variable", ref: "EMPTY", wantErr: ErrNotFound},
+		{name: "variable outside the prefix", ref: "../ENCRYPTION_KEY", invalid: true},
+		{name: "name with a dash", ref: "TWILIO-PROD", invalid: true},
+		{name: "name with an equals sign", ref: "A=B", invalid: true},
+		{name: "empty name", ref: "", invalid: true},
+	}
+
+	store := NewEnvStore("TEST_SECRET_")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Load(tt.ref)
+			switch {
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Fatalf("Load(%q) error = %v, want an invalid name error", tt.ref, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Load(%q) error = %v, want %v", tt.ref, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Load(%q) error = %v", tt.ref, err)
+			case string(got) != tt.want:
+				t.Errorf("Load(%q) = %s, want %s", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStoreLoad(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "secrets")
+	if err := os.MkdirAll(filepath.Join(dir, "providers"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "twilio.json"), []byte(`{"authToken":"secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "providers", "sendgrid.json"), []byte(`{"apikey":"secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "outside.json"), []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+		invalid bool
+	}{
+		{name: "file in the directory", ref: "twilio.json", want: `{"authToken":"secret"}`},
+		{name: "file in a subdirectory", ref: "providers/sendgrid.json", want: `{"apikey":"secret"}`},
+		{name: "missing file", ref: "smpp.json", wantErr: ErrNotFound},
+		{name: "parent directory", ref: "../outside.json", invalid: true},
+		{name: "parent directory after a subdirectory", ref: "providers/../../outside.json", invalid: true},
+		{name: "absolute path", ref: filepath.Join(root, "outside.json"), invalid: true},
+		{name: "empty path", ref: "", invalid: true},
+	}
+
+	store := NewFileStore(dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Load(tt.ref)
+			switch {
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Fatalf("Load(%q) error = %v, want a path error", tt.ref, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Load(%q) error = %v, want %v", tt.ref, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Load(%q) error = %v", tt.ref, err)
+			case string(got) != tt.want:
+				t.Errorf("Load(%q) = %s, want %s", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"delivery/helper"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Secret backends a provider secure config can be kept in
+const (
+	BackendDB    = "db"    // Encrypted in the provider row, the default
+	BackendEnv   = "env"   // JSON in an environment variable
+	BackendFile  = "file"  // JSON file, e.g. a mounted Docker or Kubernetes secret
+	BackendVault = "vault" // HashiCorp Vault KV v2 secret
+)
+
+// ErrNotFound is returned when a referenced secret does not exist in its backend
+var ErrNotFound = errors.New("secret not found")
+
+// Store loads provider secure configs kept outside the application database.
+// The provider row only stores a reference, {"backend": "vault", "ref": "delivery/twilio"}.
+type Store interface {
+	// Load returns the secure config JSON stored under a reference, or ErrNotFound
+	Load(ref string) ([]byte, error)
+}
+
+// NewStore creates the store of an external backend, configured by the environment
+func NewStore(backend string) (Store, error) {
+	switch backend {
+	case BackendEnv:
+		return NewEnvStore(helper.GetEnv("SECRETS_ENV_PREFIX", "DELIVERY_SECRET_")), nil
+	case BackendFile:
+		return NewFileStore(helper.GetEnv("SECRETS_DIR", "/run/secrets")), nil
+	case BackendVault:
+		return NewVaultStore(
+			helper.GetEnv("VAULT_ADDR", ""),
+			helper.GetEnv("VAULT_TOKEN", ""),
+			helper.GetEnv("VAULT_NAMESPACE", ""),
+			helper.GetEnv("VAULT_KV_MOUNT", "secret"),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported secret backend: %s", backend)
+	}
+}
+
+// Backend returns the backend a secure config is kept in, secure configs without one are stored in the database
+func Backend(secureConfig map[string]interface{}) string {
+	backend, _ := secureConfig["backend"].(string)
+	if backend == "" {
+		return BackendDB
+	}
+	return strings.ToLower(backend)
+}
+
+// IsReference reports whether a secure config references a secret in an external backend
+func IsReference(secureConfig map[string]interface{}) bool {
+	return Backend(secureConfig) != BackendDB
+}
+
+// ValidateReference checks a secure config that references an external secret names a supported
+// backend and a reference, and holds nothing else so no credential ends up in the database
+func ValidateReference(secureConfig map[string]interface{}) error {
+	backend := Backend(secureConfig)
+	switch backend {
+	case BackendEnv, BackendFile, BackendVault:
+	default:
+		return fmt.Errorf("unsupported secret backend: %s", backend)
+	}
+
+	if ref, _ := secureConfig["ref"].(string); strings.TrimSpace(ref) == "" {
+		return fmt.Errorf("secureConfig.ref is required for the %s secret backend", backend)
+	}
+	for field := range secureConfig {
+		if field != "backend" && field != "ref" {
+			return fmt.Errorf("secureConfig.%s is not allowed with the %s secret backend, store it in the secret", field, backend)
+		}
+	}
+	return nil
+}
+
+// LoadSecureConfig loads a provider secure config from its backend and unmarshals it into target.
+// Secure configs stored in the database are decrypted with the keyring.
+func LoadSecureConfig(secureConfig map[string]interface{}, target interface{}) error {
+	if !IsReference(secureConfig) {
+		return helper.DecryptSecureConfig(secureConfig, target)
+	}
+
+	backend := Backend(secureConfig)
+	ref, _ := secureConfig["ref"].(string)
+	store, err := NewStore(backend)
+	if err != nil {
+		return err
+	}
+
+	data, err := store.Load(ref)
+	if err != nil {
+		return fmt.Errorf("failed to load provider secure config from %s secret %q: %w", backend, ref, err)
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to parse provider secure config from %s secret %q: %w", backend, ref, err)
+	}
+	return nil
+}
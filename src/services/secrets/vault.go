@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultStore loads secrets from a HashiCorp Vault KV version 2 secrets engine over its HTTP API.
+// The data of the secret is the secure config, e.g. {"apikey": "..."} for SendGrid.
+type VaultStore struct {
+	Address   string
+	Token     string
+	Namespace string
+	Mount     string
+	Client    *http.Client
+}
+
+// NewVaultStore creates a store for the KV v2 engine mounted at mount
+func NewVaultStore(address, token, namespace, mount string) (*VaultStore, error) {
+	if address == "" || token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set to use the vault secret backend")
+	}
+
+	return &VaultStore{
+		Address:   strings.TrimSuffix(address, "/"),
+		Token:     token,
+		Namespace: namespace,
+		Mount:     strings.Trim(mount, "/"),
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Load implements the Store.Load method. The reference is the secret path within the mount,
+// "delivery/twilio?version=3" reads a specific version.
+func (s *VaultStore) Load(ref string) ([]byte, error) {
+	path, query, _ := strings.Cut(strings.Trim(ref, "/"), "?")
+	if path == "" || strings.Contains(path, "..") {
+		return nil, fmt.Errorf("invalid vault secret path: %s", ref)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", s.Address, s.Mount, path)
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vault secret reference: %s", ref)
+		}
+		endpoint += "?version=" + url.QueryEscape(values.Get("version"))
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.Token)
+	if s.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.Namespace)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault API error: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	if len(secret.Data.Data) == 0 || string(secret.Data.Data) == "null" {
+		// Deleted versions are returned with empty data
+		return nil, ErrNotFound
+	}
+	return secret.Data.Data, nil
+}
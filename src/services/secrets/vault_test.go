@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultStoreLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/data/delivery/twilio":
+			if r.URL.Query().Get("version") == "3" {
+				w.Write([]byte(`{"data":{"data":{"authToken":"old"},"metadata":{"version":3}}}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"authToken":"secret"},"metadata":{"version":4}}}`))
+		case "/v1/kv/data/delivery/deleted":
+			w.Write([]byte(`{"data":{"data":null,"metadata":{"deletion_time":"2026-01-01T00:00:00Z"}}}`))
+		case "/v1/kv/data/delivery/broken":
+			w.Write([]byte(`not json`))
+		case "/v1/kv/data/delivery/sealed":
+			http.Error(w, `{"errors":["Vault is sealed"]}`, http.StatusServiceUnavailable)
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr error
+		failing bool
+	}{
+		{name: "latest version", ref: "delivery/twilio", want: `{"authToken":"secret"}`},
+		{name: "leading slash", ref: "/delivery/twilio", want: `{"authToken":"secret"}`},
+		{name: "specific version", ref: "delivery/twilio?version=3", want: `{"authToken":"old"}`},
+		{name: "missing secret", ref: "delivery/sendgrid", wantErr: ErrNotFound},
+		{name: "deleted version", ref: "delivery/deleted", wantErr: ErrNotFound},
+		{name: "malformed response", ref: "delivery/broken", failing: true},
+		{name: "server error", ref: "delivery/sealed", failing: true},
+		{name: "wrong token", token: "other-token", ref: "delivery/twilio", failing: true},
+		{name: "path traversal", ref: "delivery/../../sys/raw", failing: true},
+		{name: "empty path", ref: "/", failing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			if token == "" {
+				token = "test-token"
+			}
+			store, err := NewVaultStore(server.URL+"/", token, "team", "/kv/")
+			if err != nil {
+				t.Fatalf("NewVaultStore() error = %v", err)
+			}
+
+			got, err := store.Load(tt.ref)
+			switch {
+			case tt.failing:
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Fatalf("Load(%q) error = %v, want a failure", tt.ref, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Load(%q) error = %v, want %v", tt.ref, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Load(%q) error = %v", tt.ref, err)
+			case string(got) != tt.want:
+				t.Errorf("Load(%q) = %s, want %s", tt.ref, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewVaultStore("", "test-token", "", "secret"); err == nil {
+		t.Error("NewVaultStore() without an address error = nil, want an error")
+	}
+}
@@ -20,3 +20,10 @@ type SMSService interface {
 	// Get SMS delivery status by message ID
 	GetStatus(messageID string) (types.DeliveryStatus, error)
 }
+
+// MessageIDReporter is implemented by SMS and WhatsApp providers that report delivery statuses by their
+// own message ID, so the ID has to be stored with the recipient
+type MessageIDReporter interface {
+	// LastMessageID returns the provider message ID of the last message sent
+	LastMessageID() string
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"delivery/helper"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultFetchTimeout bounds the download of an attachment referenced by URL
+const DefaultFetchTimeout = 15 * time.Second
+
+// Fetcher downloads attachments referenced by URL. Only hosts on the allowlist are contacted,
+// redirects included, so the service cannot be used to reach arbitrary internal addresses.
+type Fetcher struct {
+	AllowedHosts []string // Host names, "*.example.com" matches every subdomain
+	AllowedTypes []string // MIME types, "image/*" matches every subtype; empty allows any type
+	Client       *http.Client
+}
+
+// NewFetcherFromEnv creates a fetcher configured by ATTACHMENT_URL_ALLOWED_HOSTS,
+// ATTACHMENT_URL_ALLOWED_TYPES and ATTACHMENT_URL_TIMEOUT
+func NewFetcherFromEnv() *Fetcher {
+	return NewFetcher(
+		splitList(helper.GetEnv("ATTACHMENT_URL_ALLOWED_HOSTS", "")),
+		splitList(helper.GetEnv("ATTACHMENT_URL_ALLOWED_TYPES", "")),
+		fetchTimeoutFromEnv(),
+	)
+}
+
+// NewMediaFetcherFromEnv creates a fetcher for signed media URLs, which only contacts the host of
+// PUBLIC_BASE_URL and applies ATTACHMENT_URL_ALLOWED_TYPES and ATTACHMENT_URL_TIMEOUT
+func NewMediaFetcherFromEnv() *Fetcher {
+	var hosts []string
+	if baseURL, err := url.Parse(helper.GetEnv("PUBLIC_BASE_URL", "")); err == nil && baseURL.Hostname() != "" {
+		hosts = []string{strings.ToLower(baseURL.Hostname())}
+	}
+
+	return NewFetcher(
+		hosts,
+		splitList(helper.GetEnv("ATTACHMENT_URL_ALLOWED_TYPES", "")),
+		fetchTimeoutFromEnv(),
+	)
+}
+
+// fetchTimeoutFromEnv returns ATTACHMENT_URL_TIMEOUT or the default fetch timeout
+func fetchTimeoutFromEnv() time.Duration {
+	value := helper.GetEnv("ATTACHMENT_URL_TIMEOUT", "")
+	if value == "" {
+		return DefaultFetchTimeout
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		helper.Log.Warnf("Invalid value for ATTACHMENT_URL_TIMEOUT, using default of %s", DefaultFetchTimeout)
+		return DefaultFetchTimeout
+	}
+	return parsed
+}
+
+// NewFetcher creates a fetcher for the given hosts and MIME types
+func NewFetcher(allowedHosts []string, allowedTypes []string, timeout time.Duration) *Fetcher {
+	f := &Fetcher{
+		AllowedHosts: allowedHosts,
+		AllowedTypes: allowedTypes,
+	}
+	f.Client = &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return f.CheckURL(req.URL.String())
+		},
+	}
+	return f
+}
+
+// CheckURL reports whether a URL may be fetched
+func (f *Fetcher) CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("attachment URL %q is not a valid URL", rawURL)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("attachment URL %q must use http or https", rawURL)
+	}
+	if len(f.AllowedHosts) == 0 {
+		return errors.New("attachments by URL are disabled, no hosts are allowed")
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range f.AllowedHosts {
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return nil
+			}
+			continue
+		}
+		if host == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("attachment host %q is not allowed", parsed.Hostname())
+}
+
+// Fetch downloads the file at a URL. The declared content type, when given, must match the
+// type returned by the server, and the download is aborted once it exceeds maxSize bytes.
+func (f *Fetcher) Fetch(rawURL string, contentType string, maxSize int64) (*Object, error) {
+	if err := f.CheckURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachment %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch attachment %s: status %d", rawURL, resp.StatusCode)
+	}
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("attachment %s is %s, the limit is %s", rawURL, formatSize(resp.ContentLength), formatSize(maxSize))
+	}
+
+	actualType := mediaType(resp.Header.Get("Content-Type"))
+	if declared := mediaType(contentType); declared != "" && actualType != "" && declared != actualType {
+		return nil, fmt.Errorf("attachment %s has content type %s, expected %s", rawURL, actualType, declared)
+	}
+	if actualType == "" {
+		actualType = mediaType(contentType)
+	}
+	if !f.typeAllowed(actualType) {
+		return nil, fmt.Errorf("attachment %s has content type %q, which is not allowed", rawURL, actualType)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(resp.Body, maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", rawURL, err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("attachment %s exceeds the limit of %s", rawURL, formatSize(maxSize))
+	}
+
+	return &Object{
+		Data:        data,
+		ContentType: actualType,
+	}, nil
+}
+
+// typeAllowed reports whether a MIME type is on the allowlist
+func (f *Fetcher) typeAllowed(contentType string) bool {
+	if len(f.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range f.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType returns the lower-case MIME type of a Content-Type value without parameters
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return parsed
+}
+
+// splitList splits a comma separated setting into lower-case values
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
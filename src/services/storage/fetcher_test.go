@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetcherCheckURL(t *testing.T) {
+	fetcher := NewFetcher([]string{"cdn.example.com", "*.files.example.org"}, nil, time.Second)
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "allowed host", url: "https://cdn.example.com/a.pdf"},
+		{name: "allowed host is case insensitive", url: "https://CDN.Example.com/a.pdf"},
+		{name: "allowed host with port", url: "http://cdn.example.com:8080/a.pdf"},
+		{name: "wildcard subdomain", url: "https://eu.files.example.org/a.pdf"},
+		{name: "wildcard does not match the bare domain", url: "https://files.example.org/a.pdf", wantErr: true},
+		{name: "suffix of an allowed host", url: "https://evilcdn.example.com/a.pdf", wantErr: true},
+		{name: "other host", url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "file scheme", url: "file:///etc/passwd", wantErr: true},
+		{name: "ftp scheme", url: "ftp://cdn.example.com/a.pdf", wantErr: true},
+		{name: "relative URL", url: "/a.pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fetcher.CheckURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewFetcher(nil, nil, time.Second).CheckURL("https://cdn.example.com/a.pdf"); err == nil {
+		t.Error("CheckURL() without allowed hosts error = nil, want an error")
+	}
+}
+
+func TestFetcherTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedTypes []string
+		contentType  string
+		want         bool
+	}{
+		{name: "no allowlist allows any type", contentType: "application/x-msdownload", want: true},
+		{name: "exact type", allowedTypes: []string{"application/pdf"}, contentType: "application/pdf", want: true},
+		{name: "wildcard subtype", allowedTypes: []string{"image/*"}, contentType: "image/png", want: true},
+		{name: "wildcard does not match other types", allowedTypes: []string{"image/*"}, contentType: "application/pdf"},
+		{name: "type not listed", allowedTypes: []string{"application/pdf"}, contentType: "text/html"},
+		{name: "empty type with an allowlist", allowedTypes: []string{"image/*"}, contentType: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewFetcher([]string{"cdn.example.com"}, tt.allowedTypes, time.Second)
+			if got := fetcher.typeAllowed(tt.contentType); got != tt.want {
+				t.Errorf("typeAllowed(%q) = %v, want %v", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetcherFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		case "/large.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte(strings.Repeat("x", 64)))
+		case "/redirect":
+			http.Redirect(w, r, "https://elsewhere.example.com/doc.pdf", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		maxSize     int64
+		wantType    string
+		wantErr     string
+	}{
+		{name: "allowed type", path: "/doc.pdf", wantType: "application/pdf"},
+		{name: "declared type matches", path: "/doc.pdf", contentType: "application/pdf", wantType: "application/pdf"},
+		{name: "declared type differs", path: "/doc.pdf", contentType: "image/png", wantErr: "expected image/png"},
+		{name: "type not allowed", path: "/page.html", wantErr: "not allowed"},
+		{name: "larger than the limit", path: "/large.pdf", maxSize: 16, wantErr: "the limit is"},
+		{name: "redirect to a host not allowed", path: "/redirect", wantErr: "not allowed"},
+		{name: "not found", path: "/missing.pdf", wantErr: "status 404"},
+	}
+
+	fetcher := NewFetcher([]string{"127.0.0.1"}, []string{"application/pdf", "image/*"}, time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := fetcher.Fetch(server.URL+tt.path, tt.contentType, tt.maxSize)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if object.ContentType != tt.wantType {
+				t.Errorf("Fetch() content type = %q, want %q", object.ContentType, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"fmt"
+	"strconv"
+)
+
+// Limits are the attachment size limits of a channel in bytes
+type Limits struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
+
+// defaultLimits follow the provider limits: WhatsApp media is capped at 16 MB per file and
+// most email providers reject messages with more than 30 MB of attachments.
+var defaultLimits = map[models.Channel]Limits{
+	models.ChannelWhatsApp: {MaxFileSize: 16 << 20, MaxTotalSize: 64 << 20},
+	models.ChannelEmail:    {MaxFileSize: 20 << 20, MaxTotalSize: 30 << 20},
+}
+
+// LimitsForChannel returns the attachment limits of a channel. The defaults can be overridden
+// with <CHANNEL>_MAX_ATTACHMENT_SIZE and <CHANNEL>_MAX_TOTAL_ATTACHMENT_SIZE, in bytes.
+func LimitsForChannel(channel models.Channel) Limits {
+	limits := defaultLimits[channel]
+	limits.MaxFileSize = sizeFromEnv(fmt.Sprintf("%s_MAX_ATTACHMENT_SIZE", channel), limits.MaxFileSize)
+	limits.MaxTotalSize = sizeFromEnv(fmt.Sprintf("%s_MAX_TOTAL_ATTACHMENT_SIZE", channel), limits.MaxTotalSize)
+	return limits
+}
+
+// Check returns an error describing the first limit exceeded by the given attachment sizes
+func (l Limits) Check(channel models.Channel, filenames []string, sizes []int64) error {
+	var total int64
+	for i, size := range sizes {
+		if l.MaxFileSize > 0 && size > l.MaxFileSize {
+			return fmt.Errorf("attachment %q is %s, %s attachments are limited to %s per file",
+				filenames[i], formatSize(size), channel, formatSize(l.MaxFileSize))
+		}
+		total += size
+	}
+	if l.MaxTotalSize > 0 && total > l.MaxTotalSize {
+		return fmt.Errorf("attachments total %s, %s attachments are limited to %s per message",
+			formatSize(total), channel, formatSize(l.MaxTotalSize))
+	}
+	return nil
+}
+
+// sizeFromEnv reads a size in bytes from the environment, keeping the default when unset or invalid
+func sizeFromEnv(key string, defaultValue int64) int64 {
+	value := helper.GetEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		helper.Log.Warnf("Invalid value for %s, using default of %d bytes", key, defaultValue)
+		return defaultValue
+	}
+	return size
+}
+
+// formatSize formats a byte count for error messages
+func formatSize(size int64) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	}
+	if size >= 1<<10 {
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
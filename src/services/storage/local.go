@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStore implements the Store interface on the local filesystem.
+// Each object is written as a data file with a JSON sidecar holding its content type.
+type LocalStore struct {
+	Root string
+}
+
+// localMetadata is the sidecar written next to each object
+type localMetadata struct {
+	ContentType string `json:"contentType"`
+}
+
+// NewLocalStore creates a local store rooted at the given directory
+func NewLocalStore(root string) (*LocalStore, error) {
+	if root == "" {
+		return nil, errors.New("storage root cannot be empty")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{Root: root}, nil
+}
+
+// path returns the file path of a key, rejecting keys that would escape the root
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return filepath.Join(s.Root, key), nil
+}
+
+// Put implements the Store.Put method
+func (s *LocalStore) Put(key string, object *Object) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(localMetadata{ContentType: object.ContentType})
+	if err != nil {
+		return fmt.Errorf("failed to marshal object metadata: %w", err)
+	}
+
+	if err := os.WriteFile(path, object.Data, 0o640); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.WriteFile(path+".meta", metadata, 0o640); err != nil {
+		return fmt.Errorf("failed to write object metadata: %w", err)
+	}
+	return nil
+}
+
+// Get implements the Store.Get method
+func (s *LocalStore) Get(key string) (*Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	var metadata localMetadata
+	if raw, err := os.ReadFile(path + ".meta"); err == nil {
+		_ = json.Unmarshal(raw, &metadata)
+	}
+
+	return &Object{
+		Data:        data,
+		ContentType: metadata.ContentType,
+	}, nil
+}
+
+// Delete implements the Store.Delete method
+func (s *LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []string{path, path + ".meta"} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete object: %w", err)
+		}
+	}
+	return nil
+}
+
+// Sweep implements the Sweeper interface, deleting objects whose data file was written before the cutoff
+func (s *LocalStore) Sweep(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".meta") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := s.Delete(entry.Name()); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
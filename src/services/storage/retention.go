@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"delivery/helper"
+	"time"
+)
+
+// DefaultMediaRetention is how long stored attachments and media are kept. It covers the signed
+// URL lifetime and messages waiting in the queue while a provider is unavailable.
+const DefaultMediaRetention = 7 * 24 * time.Hour
+
+// sweepInterval is how often the janitor looks for expired objects
+const sweepInterval = time.Hour
+
+// Sweeper is implemented by stores that cannot expire objects themselves. S3 buckets expire
+// objects with a lifecycle rule instead.
+type Sweeper interface {
+	// Sweep deletes the objects stored before the cutoff and returns how many were deleted
+	Sweep(cutoff time.Time) (int, error)
+}
+
+// MediaRetention returns how long stored objects are kept, MEDIA_RETENTION ("0" keeps them forever).
+// It is never shorter than the lifetime of a signed media URL.
+func MediaRetention() time.Duration {
+	value := helper.GetEnv("MEDIA_RETENTION", "")
+	if value == "" {
+		return DefaultMediaRetention
+	}
+
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		helper.Log.Warnf("Invalid value for MEDIA_RETENTION, using default of %s", DefaultMediaRetention)
+		return DefaultMediaRetention
+	}
+	if retention > 0 && retention < DefaultMediaURLTTL {
+		helper.Log.Warnf("MEDIA_RETENTION is shorter than the media URL lifetime, using %s", DefaultMediaURLTTL)
+		return DefaultMediaURLTTL
+	}
+	return retention
+}
+
+// StartJanitor deletes the objects of the configured store once they are older than the retention.
+// It does nothing for stores without a Sweeper or when the retention is 0.
+func StartJanitor() error {
+	store, err := NewStoreFromEnv()
+	if err != nil {
+		return err
+	}
+
+	sweeper, ok := store.(Sweeper)
+	retention := MediaRetention()
+	if !ok || retention == 0 {
+		return nil
+	}
+
+	go func() {
+		for {
+			deleted, err := sweeper.Sweep(time.Now().Add(-retention))
+			if err != nil {
+				helper.Log.WithError(err).Error("Failed to delete expired media")
+			} else if deleted > 0 {
+				helper.Log.WithField("deleted", deleted).Info("Deleted expired media")
+			}
+			time.Sleep(sweepInterval)
+		}
+	}()
+
+	helper.Log.WithField("retention", retention.String()).Info("Media janitor started")
+	return nil
+}
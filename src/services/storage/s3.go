@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bytes"
+	"delivery/helper"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Store implements the Store interface on an S3-compatible object store.
+// Requests use path-style addressing and AWS Signature Version 4, which is supported by
+// AWS S3 as well as MinIO, Ceph and most other compatible services.
+type S3Store struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Client          *http.Client
+}
+
+// NewS3Store creates an S3-compatible store for a bucket
+func NewS3Store(endpoint, region, bucket, accessKeyID, secretAccessKey string) (*S3Store, error) {
+	if endpoint == "" || bucket == "" {
+		return nil, errors.New("S3 endpoint and bucket are required")
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("S3 access key ID and secret access key are required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Store{
+		Endpoint:        strings.TrimRight(endpoint, "/"),
+		Region:          region,
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Client:          &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Put implements the Store.Put method
+func (s *S3Store) Put(key string, object *Object) error {
+	headers := map[string]string{}
+	if object.ContentType != "" {
+		headers["Content-Type"] = object.ContentType
+	}
+
+	resp, err := s.do(http.MethodPut, key, object.Data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to write object: S3 returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Get implements the Store.Get method
+func (s *S3Store) Get(key string) (*Object, error) {
+	resp, err := s.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read object: S3 returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	return &Object{
+		Data:        data,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// Delete implements the Store.Delete method
+func (s *S3Store) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 answers 204 whether or not the object existed
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete object: S3 returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// do sends a signed request for an object key
+func (s *S3Store) do(method, key string, payload []byte, headers map[string]string) (*http.Response, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) {
+		return nil, fmt.Errorf("invalid storage key: %q", key)
+	}
+
+	objectURL := fmt.Sprintf("%s/%s/%s", s.Endpoint, url.PathEscape(s.Bucket), url.PathEscape(key))
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	helper.SignAWSRequest(req, payload, s.Region, "s3", helper.AWSCredentials{
+		AccessKeyID:     s.AccessKeyID,
+		SecretAccessKey: s.SecretAccessKey,
+	}, time.Now())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send S3 request: %w", err)
+	}
+	return resp, nil
+}
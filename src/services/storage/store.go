@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"delivery/helper"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist in the store
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored file with its content type
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Store defines operations for storing message media and attachments
+type Store interface {
+	// Put stores an object under a key, replacing any existing object
+	Put(key string, object *Object) error
+
+	// Get returns the object stored under a key, or ErrNotFound
+	Get(key string) (*Object, error)
+
+	// Delete removes the object stored under a key. Objects are kept for MEDIA_RETENTION,
+	// local stores are swept by the janitor and S3 buckets need a matching lifecycle rule.
+	Delete(key string) error
+}
+
+// NewStoreFromEnv creates the store configured by the environment.
+// STORAGE_BACKEND selects "local" (the default, files under MEDIA_STORAGE_PATH) or "s3".
+func NewStoreFromEnv() (Store, error) {
+	switch backend := strings.ToLower(helper.GetEnv("STORAGE_BACKEND", "local")); backend {
+	case "local":
+		return NewLocalStore(helper.GetEnv("MEDIA_STORAGE_PATH", "./data/media"))
+	case "s3":
+		return NewS3Store(
+			helper.GetEnv("S3_ENDPOINT", ""),
+			helper.GetEnv("S3_REGION", "us-east-1"),
+			helper.GetEnv("S3_BUCKET", ""),
+			helper.GetEnv("S3_ACCESS_KEY_ID", ""),
+			helper.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", backend)
+	}
+}
+
+// AttachmentKey returns the storage key of the attachment at the given index of a message
+func AttachmentKey(messageUUID string, index int, filename string) string {
+	return fmt.Sprintf("%s-%d%s", messageUUID, index, strings.ToLower(filepath.Ext(filepath.Base(filename))))
+}
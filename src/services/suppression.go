@@ -0,0 +1,138 @@
+package services
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// optOutKeywords are the inbound replies that opt a recipient out of further messages
+var optOutKeywords = map[string]bool{
+	"STOP":        true,
+	"STOPALL":     true,
+	"UNSUBSCRIBE": true,
+	"CANCEL":      true,
+	"END":         true,
+	"QUIT":        true,
+	"OPTOUT":      true,
+}
+
+// optInKeywords are the inbound replies that opt a recipient back in
+var optInKeywords = map[string]bool{
+	"START":     true,
+	"UNSTOP":    true,
+	"SUBSCRIBE": true,
+}
+
+// SuppressionService manages the per-tenant suppression list
+type SuppressionService struct {
+	db       *gorm.DB
+	readerDB *gorm.DB
+}
+
+// NewSuppressionService creates a new suppression service
+func NewSuppressionService(db *gorm.DB, readerDB *gorm.DB) (*SuppressionService, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	if readerDB == nil {
+		readerDB = db
+	}
+	return &SuppressionService{
+		db:       db,
+		readerDB: readerDB,
+	}, nil
+}
+
+// NormalizeAddress returns the canonical form of an address for a channel
+func NormalizeAddress(channel models.Channel, address string) string {
+	address = strings.TrimSpace(address)
+	if channel == models.ChannelEmail {
+		return strings.ToLower(address)
+	}
+
+	// Twilio prefixes WhatsApp numbers with the channel name
+	address = strings.TrimPrefix(address, "whatsapp:")
+	return strings.ReplaceAll(address, " ", "")
+}
+
+// IsOptOutKeyword reports whether an inbound message body is an opt-out request
+func IsOptOutKeyword(body string) bool {
+	return optOutKeywords[strings.ToUpper(strings.TrimSpace(body))]
+}
+
+// IsOptInKeyword reports whether an inbound message body is an opt-in request
+func IsOptInKeyword(body string) bool {
+	return optInKeywords[strings.ToUpper(strings.TrimSpace(body))]
+}
+
+// IsSuppressed returns the suppression entry for an address, or nil when the address may be messaged
+func (s *SuppressionService) IsSuppressed(tenantID string, channel models.Channel, address string) (*models.Suppression, error) {
+	var suppression models.Suppression
+	err := s.readerDB.Where("tenant_id = ? AND channel = ? AND address = ?", tenantID, channel, NormalizeAddress(channel, address)).
+		First(&suppression).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	return &suppression, nil
+}
+
+// Suppress adds an address to the suppression list, updating the reason if it is already present.
+// A MANUAL entry is kept as it is, so provider events never replace a suppression added by an operator.
+func (s *SuppressionService) Suppress(suppression *models.Suppression) error {
+	suppression.Address = NormalizeAddress(suppression.Channel, suppression.Address)
+	if suppression.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+
+	if suppression.UUID == "" {
+		uuid, err := helper.GenerateUUID()
+		if err != nil {
+			return fmt.Errorf("failed to generate UUID for suppression: %w", err)
+		}
+		suppression.UUID = uuid
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "channel"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "source", "details", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Neq{Column: clause.Column{Table: "suppressions", Name: "reason"}, Value: models.SuppressionReasonManual},
+		}},
+	}).Create(suppression).Error
+	if err != nil {
+		return fmt.Errorf("failed to save suppression: %w", err)
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"tenantId": suppression.TenantID,
+		"channel":  suppression.Channel,
+		"reason":   suppression.Reason,
+		"source":   suppression.Source,
+	}).Info("Address added to suppression list")
+	return nil
+}
+
+// Unsuppress removes an address from the suppression list
+func (s *SuppressionService) Unsuppress(tenantID string, channel models.Channel, address string) error {
+	result := s.db.Where("tenant_id = ? AND channel = ? AND address = ?", tenantID, channel, NormalizeAddress(channel, address)).
+		Delete(&models.Suppression{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove suppression: %w", result.Error)
+	}
+
+	helper.Log.WithFields(map[string]interface{}{
+		"tenantId": tenantID,
+		"channel":  channel,
+		"removed":  result.RowsAffected,
+	}).Info("Address removed from suppression list")
+	return nil
+}
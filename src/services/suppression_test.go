@@ -0,0 +1,37 @@
+package services
+
+import (
+	"delivery/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSuppressKeepsManualEntries(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "suppressions" .* ON CONFLICT \("tenant_id","channel","address"\) DO UPDATE SET .* WHERE "suppressions"."reason" <> \$\d+ RETURNING "id"`).
+		WithArgs(sqlmock.AnyArg(), "tenant", models.ChannelEmail, "jane@example.com", models.SuppressionReasonBounce,
+			models.SuppressionSourceSendGrid, "bounce", sqlmock.AnyArg(), sqlmock.AnyArg(), models.SuppressionReasonManual).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	service, err := NewSuppressionService(db, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = service.Suppress(&models.Suppression{
+		TenantID: "tenant",
+		Channel:  models.ChannelEmail,
+		Address:  "Jane@Example.com",
+		Reason:   models.SuppressionReasonBounce,
+		Source:   models.SuppressionSourceSendGrid,
+		Details:  "bounce",
+	})
+	if err != nil {
+		t.Fatalf("Suppress() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"delivery/helper"
+	"delivery/models"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RenderedEmail is the subject and bodies of an email rendered from a template
+type RenderedEmail struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// RenderEmail renders the subject, HTML body and plain-text body of an email template with the params of
+// a message. The subject is taken from the message, the "subject" param, the template subject or the
+// template name, in that order. Without a text template the text body is generated from the HTML body.
+func RenderEmail(template *models.Template, message *models.EmailMessage, logger *logrus.Entry) (*RenderedEmail, error) {
+	// Process the template content with params
+	processedContent, err := helper.ProcessTemplate(template.Content, message.Params)
+	if err != nil {
+		// Use debug template to get more info about the error
+		debugInfo := helper.DebugTemplate(template.Content, message.Params)
+		logger.WithError(err).WithField("debugInfo", debugInfo).Error("Failed to process template")
+		return nil, fmt.Errorf("failed to process template: %w", err)
+	}
+
+	// Process the subject with params if provided
+	subject := message.Subject
+	if subject == "" && message.Params != nil {
+		subject = message.Params["subject"]
+	}
+
+	// Use template subject if still empty
+	if subject == "" && template.Subject != "" {
+		// Process the template subject with params
+		subject, err = helper.ProcessTemplate(template.Subject, message.Params)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to process template subject, using raw template subject")
+			subject = template.Subject
+		}
+	}
+
+	// Hardcode a default subject if still empty to satisfy SendGrid requirements
+	if subject == "" {
+		subject = template.Name
+		if subject == "" {
+			subject = "Notification"
+		}
+		logger.WithField("defaultSubject", subject).Info("Using default subject for email")
+	}
+
+	// Always send a plain-text alternative, spam filters and pager gateways rely on it
+	var textBody string
+	if template.TextContent != "" {
+		textBody, err = helper.ProcessTextTemplate(template.TextContent, message.Params)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to process text template, generating text from HTML")
+			textBody = ""
+		}
+	}
+	if textBody == "" {
+		textBody = helper.HTMLToText(processedContent)
+	}
+
+	return &RenderedEmail{
+		Subject:  subject,
+		HTMLBody: processedContent,
+		TextBody: textBody,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"delivery/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WhatsAppSessionService tracks WhatsApp customer-service windows from inbound messages
+type WhatsAppSessionService struct {
+	db       *gorm.DB
+	readerDB *gorm.DB
+}
+
+// NewWhatsAppSessionService creates a new WhatsApp session service
+func NewWhatsAppSessionService(db *gorm.DB, readerDB *gorm.DB) (*WhatsAppSessionService, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	if readerDB == nil {
+		readerDB = db
+	}
+	return &WhatsAppSessionService{
+		db:       db,
+		readerDB: readerDB,
+	}, nil
+}
+
+// Touch opens or extends the session of a recipient after an inbound message received at the given time
+func (s *WhatsAppSessionService) Touch(tenantID string, providerUUID string, address string, receivedAt time.Time) error {
+	session := models.WhatsAppSession{
+		TenantID:      tenantID,
+		ProviderUUID:  providerUUID,
+		Address:       NormalizeAddress(models.ChannelWhatsApp, address),
+		LastInboundAt: receivedAt,
+		ExpiresAt:     receivedAt.Add(models.WhatsAppSessionWindow),
+	}
+
+	// Out-of-order webhooks must not shorten a window that a later message already extended
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "provider_uuid"}, {Name: "address"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "last_inbound_at"}, Value: gorm.Expr("GREATEST(whatsapp_sessions.last_inbound_at, excluded.last_inbound_at)")},
+			{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("GREATEST(whatsapp_sessions.expires_at, excluded.expires_at)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(&session).Error
+	if err != nil {
+		return fmt.Errorf("failed to save WhatsApp session: %w", err)
+	}
+	return nil
+}
+
+// OpenSession returns the session of a recipient when its window is still open at the given time, or nil
+func (s *WhatsAppSessionService) OpenSession(tenantID string, providerUUID string, address string, at time.Time) (*models.WhatsAppSession, error) {
+	var session models.WhatsAppSession
+	err := s.readerDB.Where("tenant_id = ? AND provider_uuid = ? AND address = ?", tenantID, providerUUID, NormalizeAddress(models.ChannelWhatsApp, address)).
+		Where("expires_at > ?", at).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch WhatsApp session: %w", err)
+	}
+	return &session, nil
+}